@@ -0,0 +1,42 @@
+// File: cmd/goscant/anonmap.go
+package main
+
+import (
+    "encoding/csv"
+    "flag"
+    "fmt"
+    "os"
+    "sort"
+
+    "goscant/internal/anonymize"
+)
+
+// runAnonMap decrypts an --anonymize mapping file and prints it as CSV.
+func runAnonMap(args []string) int {
+    fs := flag.NewFlagSet("anon-map", flag.ExitOnError)
+    path := fs.String("map", "anonymize-map.enc", "Encrypted mapping file written by --anonymize")
+    fs.Parse(args)
+
+    a, err := anonymize.FromEnv()
+    if err != nil {
+        fmt.Println("anon-map:", err)
+        return 1
+    }
+    m, err := a.ReadMapping(*path)
+    if err != nil {
+        fmt.Println("anon-map:", err)
+        return 1
+    }
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    w := csv.NewWriter(os.Stdout)
+    w.Write([]string{"pseudonym", "value"})
+    for _, k := range keys {
+        w.Write([]string{k, m[k]})
+    }
+    w.Flush()
+    return 0
+}
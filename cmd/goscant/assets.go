@@ -0,0 +1,77 @@
+// File: cmd/goscant/assets.go
+package main
+
+import (
+    "context"
+    "encoding/csv"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "goscant/internal/assets"
+    "goscant/internal/results"
+)
+
+// runAssets merges an earlier run's results per logical asset and, with
+// --against, reports which ports opened or closed on each asset.
+func runAssets(args []string) int {
+    fs := flag.NewFlagSet("assets", flag.ExitOnError)
+    mapPath := fs.String("map", "", "CSV of asset,address[,address...] (required)")
+    from := fs.String("from", "", "Results from the run to report on (required)")
+    against := fs.String("against", "", "Earlier results to diff against")
+    output := fs.String("output", "assets.csv", "Report path")
+    fs.Parse(args)
+
+    if *mapPath == "" || *from == "" {
+        fmt.Println("assets: --map and --from are required")
+        fs.Usage()
+        return 1
+    }
+    m, err := assets.Load(context.Background(), *mapPath)
+    if err != nil {
+        fmt.Println("assets:", err)
+        return 1
+    }
+    cur, err := results.Read(*from)
+    if err != nil {
+        fmt.Println("assets:", err)
+        return 1
+    }
+    merged := m.Merge(cur)
+
+    f, err := os.Create(*output)
+    if err != nil {
+        fmt.Println("assets:", err)
+        return 1
+    }
+    defer f.Close()
+    w := csv.NewWriter(f)
+    if *against == "" {
+        w.Write([]string{"asset", "addresses", "open_ports"})
+        for _, a := range merged {
+            w.Write([]string{a.Name, strings.Join(a.Addresses, " "), strings.Join(a.Open, " ")})
+        }
+    } else {
+        old, err := results.Read(*against)
+        if err != nil {
+            fmt.Println("assets:", err)
+            return 1
+        }
+        w.Write([]string{"asset", "port", "change"})
+        for _, c := range assets.Diff(m.Merge(old), merged) {
+            change := "closed"
+            if c.Opened {
+                change = "opened"
+            }
+            w.Write([]string{c.Asset, c.Port, change})
+        }
+    }
+    w.Flush()
+    if err := w.Error(); err != nil {
+        fmt.Println("assets:", err)
+        return 1
+    }
+    fmt.Println("assets: report written to", *output)
+    return 0
+}
@@ -0,0 +1,34 @@
+// File: cmd/goscant/clock.go
+package main
+
+import (
+    "strconv"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// clockColumn holds each result's offset from scan start in microseconds.
+const clockColumn = "mono_offset_us"
+
+// clockStamp records when each result finished relative to the scan epoch.
+// Both readings come from the monotonic clock, so the offsets keep their
+// order even if NTP steps the wall clock mid-scan.
+type clockStamp struct {
+    epoch time.Time
+}
+
+func newClockStamp() clockStamp {
+    return clockStamp{epoch: time.Now()}
+}
+
+func (c clockStamp) Enrich(r *scanner.Result) {
+    at := r.Time
+    if at.IsZero() {
+        at = time.Now()
+    }
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    r.Meta[clockColumn] = strconv.FormatInt(at.Sub(c.epoch).Microseconds(), 10)
+}
@@ -0,0 +1,151 @@
+// File: cmd/goscant/compare.go
+package main
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "goscant/internal/compare"
+    "goscant/internal/results"
+    "goscant/internal/scanner"
+    "goscant/internal/triage"
+)
+
+// compareFailed is the exit status when a --fail-on rule or --fail-score
+// trips; 1 stays reserved for errors.
+const compareFailed = 2
+
+// deltaRow is one line of the machine-readable delta.
+type deltaRow struct {
+    Change   string `json:"change"`
+    DstIP    string `json:"dst_ip"`
+    DstPort  int    `json:"dst_port"`
+    Proto    string `json:"proto"`
+    Severity string `json:"severity"`
+    Previous string `json:"previous_severity,omitempty"`
+    Service  string `json:"service,omitempty"`
+}
+
+// runCompare diffs the open ports of two stored runs, prints the changes,
+// writes them as JSON lines and exits non-zero when the gating rules trip:
+//
+//	goscant compare old.csv new.csv --fail-on new-critical,raised-high
+func runCompare(args []string) int {
+    fs := flag.NewFlagSet("compare", flag.ExitOnError)
+    failOn := fs.String("fail-on", "", "Comma-separated KIND-SEVERITY rules that fail the comparison, e.g. new-critical,raised-high")
+    failScore := fs.Int("fail-score", 0, "Fail when new and raised exposures weigh at least this much (info=1 ... critical=16); 0 disables")
+    triagePath := fs.String("triage", "", "Triage rules to label rows that carry no severity")
+    delta := fs.String("delta", "compare.jsonl", "Machine-readable delta path (empty to skip)")
+    paths := parseInterspersed(fs, args)
+    if len(paths) != 2 {
+        fmt.Println("compare: want exactly two runs: compare OLD NEW [flags]")
+        fs.Usage()
+        return 1
+    }
+    rules, err := compare.ParseRules(*failOn)
+    if err != nil {
+        fmt.Println("compare: --fail-on:", err)
+        return 1
+    }
+    var runs [2][]scanner.Result
+    for i, p := range paths {
+        if runs[i], err = results.Read(p); err != nil {
+            fmt.Println("compare:", err)
+            return 1
+        }
+    }
+    if *triagePath != "" {
+        ruleSet, err := triage.Load(*triagePath)
+        if err != nil {
+            fmt.Println("compare:", err)
+            return 1
+        }
+        for _, run := range runs {
+            for i := range run {
+                if run[i].Meta["severity"] == "" {
+                    ruleSet.Enrich(&run[i])
+                }
+            }
+        }
+    }
+
+    changes := compare.Diff(runs[0], runs[1])
+    marks := map[string]string{compare.New: "+", compare.Closed: "-", compare.Raised: "^", compare.Lowered: "v"}
+    for _, c := range changes {
+        sev := c.Severity
+        if c.Previous != "" {
+            sev = c.Previous + " -> " + c.Severity
+        }
+        fmt.Printf("%s %-8s %-28s %-20s %s\n", marks[c.Kind], c.Kind, fmt.Sprintf("%s:%d/%s", c.IP, c.Port, c.Proto), sev, c.Service)
+    }
+    counts := make([]string, 0, len(compare.Kinds))
+    for _, k := range compare.Kinds {
+        n := 0
+        for _, c := range changes {
+            if c.Kind == k {
+                n++
+            }
+        }
+        counts = append(counts, fmt.Sprintf("%d %s", n, k))
+    }
+    score := compare.Score(changes)
+    fmt.Printf("compare: %s; exposure score %d\n", strings.Join(counts, ", "), score)
+
+    if *delta != "" {
+        if err := writeDelta(*delta, changes); err != nil {
+            fmt.Println("compare:", err)
+            return 1
+        }
+        fmt.Println("compare: delta written to", *delta)
+    }
+
+    failed := false
+    for _, r := range rules {
+        if hits := r.Match(changes); len(hits) > 0 {
+            fmt.Printf("compare: FAIL %s: %d matching changes\n", r, len(hits))
+            failed = true
+        }
+    }
+    if *failScore > 0 && score >= *failScore {
+        fmt.Printf("compare: FAIL exposure score %d reaches --fail-score %d\n", score, *failScore)
+        failed = true
+    }
+    if failed {
+        return compareFailed
+    }
+    return 0
+}
+
+// parseInterspersed parses fs allowing flags after positional arguments,
+// as in "compare old new --fail-on ...", and returns the positionals.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+    var pos []string
+    for {
+        fs.Parse(args)
+        args = fs.Args()
+        if len(args) == 0 {
+            return pos
+        }
+        pos = append(pos, args[0])
+        args = args[1:]
+    }
+}
+
+// writeDelta saves changes as JSON lines.
+func writeDelta(path string, changes []compare.Change) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    enc := json.NewEncoder(f)
+    for _, c := range changes {
+        if err := enc.Encode(deltaRow{Change: c.Kind, DstIP: c.IP, DstPort: c.Port, Proto: c.Proto, Severity: c.Severity, Previous: c.Previous, Service: c.Service}); err != nil {
+            f.Close()
+            return err
+        }
+    }
+    return f.Close()
+}
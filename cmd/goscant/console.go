@@ -0,0 +1,133 @@
+// File: cmd/goscant/console.go
+package main
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "os/signal"
+    "strconv"
+    "strings"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/input"
+    "goscant/internal/models"
+    "goscant/internal/scanner"
+)
+
+const consoleHelp = `commands:
+  scan <ips> <ports>      probe targets, e.g. scan 10.0.0.5 22,80
+  banner <ip> <port>      print the service greeting
+  set timeout <duration>  probe timeout, e.g. set timeout 500ms
+  set scan <type>         scan type (tcp, sctp, ipproto, quic, tls)
+  show                    print current settings
+  help                    this text
+  quit                    leave the console`
+
+// runConsole starts an interactive prompt for one-off probes.
+func runConsole(args []string) int {
+    cfg := &config.Config{Timeout: 500 * time.Millisecond, ScanType: "tcp"}
+    rawCapable := scanner.CheckRawSocketCapability()
+    var engine scanner.Scanner
+    rebuild := func() {
+        if c, ok := engine.(io.Closer); ok {
+            c.Close()
+        }
+        engine = scanner.NewFactory(cfg, rawCapable)
+    }
+    rebuild()
+    defer func() {
+        if c, ok := engine.(io.Closer); ok {
+            c.Close()
+        }
+    }()
+
+    in := bufio.NewScanner(os.Stdin)
+    for {
+        fmt.Print("goscant> ")
+        if !in.Scan() {
+            fmt.Println()
+            return 0
+        }
+        f := strings.Fields(in.Text())
+        if len(f) == 0 {
+            continue
+        }
+        // Ctrl-C aborts the running command, not the console.
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+        switch {
+        case f[0] == "quit" || f[0] == "exit":
+            stop()
+            return 0
+        case f[0] == "help":
+            fmt.Println(consoleHelp)
+        case f[0] == "show":
+            fmt.Printf("timeout=%s scan=%s raw=%v\n", cfg.Timeout, cfg.ScanType, rawCapable)
+        case f[0] == "scan" && len(f) == 3:
+            consoleScan(ctx, engine, f[1], f[2])
+        case f[0] == "banner" && len(f) == 3:
+            port, err := strconv.Atoi(f[2])
+            if err != nil {
+                fmt.Println("bad port:", f[2])
+                break
+            }
+            b, err := scanner.GrabBanner(ctx, f[1], port, cfg.Timeout, 512)
+            if err != nil {
+                fmt.Println("error:", err)
+                break
+            }
+            fmt.Printf("%s:%d %q\n", f[1], port, b)
+        case f[0] == "set" && len(f) == 3 && f[1] == "timeout":
+            d, err := time.ParseDuration(f[2])
+            if err != nil {
+                fmt.Println("bad duration:", f[2])
+                break
+            }
+            cfg.Timeout = d
+            rebuild()
+        case f[0] == "set" && len(f) == 3 && f[1] == "scan":
+            if !config.ValidScanType(f[2]) {
+                fmt.Printf("scan must be one of %s\n", strings.Join(config.ScanTypes, ", "))
+                break
+            }
+            if err := requireRaw(&config.Config{ScanType: f[2]}, rawCapable); err != nil {
+                fmt.Println("error:", err)
+                break
+            }
+            cfg.ScanType = f[2]
+            rebuild()
+        default:
+            fmt.Println("unknown command; type help")
+        }
+        stop()
+    }
+}
+
+func consoleScan(ctx context.Context, engine scanner.Scanner, ipArg, portArg string) {
+    ips, err := input.ParseIPs(ctx, ipArg)
+    if err != nil {
+        fmt.Println("error:", err)
+        return
+    }
+    ports, err := input.ParsePorts(portArg)
+    if err != nil {
+        fmt.Println("error:", err)
+        return
+    }
+    for _, ip := range ips {
+        for _, port := range ports {
+            if ctx.Err() != nil {
+                return
+            }
+            r := engine.Scan(ctx, models.ScanTarget{IP: ip, Port: port})
+            line := fmt.Sprintf("%s %d/%s %s %dms", r.IP, r.Port, r.Proto, r.Status, r.LatencyMS)
+            if r.Err != nil && r.Status == scanner.Error {
+                line += " (" + r.Err.Error() + ")"
+            }
+            fmt.Println(line)
+        }
+    }
+}
@@ -0,0 +1,84 @@
+// File: cmd/goscant/estimate.go
+package main
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/logger"
+)
+
+// scanEstimate is the expected size of a scan, worked out before launch.
+type scanEstimate struct {
+    targets  int
+    probes   int // including retries, which only FILTERED/ERROR results get
+    packets  int // outbound packets
+    duration time.Duration
+}
+
+// estimateScan sizes a scan of n targets. It runs before discovery, and
+// retries are counted in full, so probes, packets and duration are upper
+// bounds.
+func estimateScan(cfg *config.Config, n int, rawCapable bool) scanEstimate {
+    e := scanEstimate{targets: n}
+    e.probes = e.targets * (1 + cfg.Retries)
+
+    // a connect handshake costs SYN, ACK and the closing RST or FIN; raw
+    // engines send a single packet per probe
+    perProbe := 3
+    switch {
+    case cfg.Stateless, cfg.ScanType == "sctp", cfg.ScanType == "ipproto", cfg.ScanType == "quic":
+        perProbe = 1
+    case cfg.ScanType == "tcp" && rawCapable && !cfg.DryRun && !cfg.Banner && cfg.EgressProfiles == nil && cfg.Via == "":
+        perProbe = 1
+    }
+    e.packets = e.probes * perProbe
+
+    // each worker spends at most delay + timeout per probe; --rate may cap
+    // the pace lower still
+    workers := max(cfg.NumWorkers, 1)
+    e.duration = time.Duration(e.probes/workers) * (cfg.Delay + cfg.Timeout)
+    if cfg.Rate > 0 {
+        e.duration = max(e.duration, time.Duration(e.probes)*time.Second/time.Duration(cfg.Rate))
+    }
+    return e
+}
+
+func (e scanEstimate) String() string {
+    return fmt.Sprintf("%d targets, up to %d probes (~%d packets), up to %s", e.targets, e.probes, e.packets, e.duration.Round(time.Second))
+}
+
+// errNotConfirmed aborts a scan the operator declined at the prompt.
+var errNotConfirmed = errors.New("scan not confirmed")
+
+// promptMu keeps the prompts of concurrent plan jobs apart.
+var promptMu sync.Mutex
+
+// confirmScan logs the estimate and, when the target count exceeds
+// --confirm-above, asks the operator to go ahead unless --yes was given.
+// Without a terminal to ask on, a large scan needs --yes. The prompt goes to
+// stderr so it cannot end up in results streamed to stdout.
+func confirmScan(cfg *config.Config, e scanEstimate, log *logger.Logger) error {
+    log.Info("estimate: " + e.String())
+    if cfg.Yes || cfg.DryRun || cfg.ConfirmAbove <= 0 || e.targets <= cfg.ConfirmAbove {
+        return nil
+    }
+    if st, err := os.Stdin.Stat(); err != nil || st.Mode()&os.ModeCharDevice == 0 {
+        return fmt.Errorf("%d targets exceed --confirm-above %d; pass --yes to run unattended", e.targets, cfg.ConfirmAbove)
+    }
+    promptMu.Lock()
+    defer promptMu.Unlock()
+    fmt.Fprintf(os.Stderr, "About to scan %s (%s).\nProceed? [y/N] ", cfg.OutputPath, e)
+    answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+    switch strings.ToLower(strings.TrimSpace(answer)) {
+    case "y", "yes":
+        return nil
+    }
+    return errNotConfirmed
+}
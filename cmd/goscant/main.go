@@ -3,32 +3,85 @@ package main
 
 import (
     "context"
-    "encoding/csv"
+    "errors"
     "flag"
     "fmt"
-    "log"
+    "io"
+    "net"
     "os"
     "os/signal"
     "path/filepath"
+    "slices"
+    "strconv"
     "strings"
     "sync"
+    "sync/atomic"
     "syscall"
     "time"
 
-    "github.com/google/gopacket/pcap"
-
+    "goscant/filter"
+    "goscant/internal/anonymize"
+    "goscant/internal/assets"
     "goscant/internal/checkpoint"
     "goscant/internal/config"
+    "goscant/internal/consul"
+    "goscant/internal/control"
+    "goscant/internal/egress"
+    "goscant/internal/fragile"
+    "goscant/internal/guardrail"
+    "goscant/internal/hostdown"
     "goscant/internal/input"
     "goscant/internal/logger"
+    "goscant/internal/owners"
     "goscant/internal/prober"
+    "goscant/internal/ratelimit"
+    "goscant/internal/rawnet"
+    "goscant/internal/rdns"
+    "goscant/internal/results"
     "goscant/internal/scanner"
+    "goscant/internal/seal"
+    "goscant/internal/service"
+    "goscant/internal/snmp"
+    "goscant/internal/traceroute"
+    "goscant/internal/triage"
     "goscant/internal/writer"
 )
 
+// subcommands maps the first CLI argument to an alternative entry point.
+var subcommands = map[string]func(args []string) int{
+    "verify":         runVerify,
+    "console":        runConsole,
+    "ptr-sweep":      runPTRSweep,
+    "anon-map":       runAnonMap,
+    "assets":         runAssets,
+    "support-bundle": runSupportBundle,
+    "target-sim":     runTargetSim,
+    "compare":        runCompare,
+}
+
 func main() {
+    // "resume <checkpoint> [flags]" is shorthand for "--resume <checkpoint> [flags]"
+    if len(os.Args) > 2 && os.Args[1] == "resume" {
+        os.Args = append([]string{os.Args[0], "--resume", os.Args[2]}, os.Args[3:]...)
+    }
+    if len(os.Args) > 1 {
+        if run, ok := subcommands[os.Args[1]]; ok {
+            os.Exit(run(os.Args[2:]))
+        }
+    }
+
     cfg := parseFlags()
     log := logger.New(cfg.LogPath)
+    if cfg.OutputPath == writer.Stdout {
+        log = logger.NewTo(cfg.LogPath, os.Stderr)
+    }
+    if cfg.TraceTargets != "" {
+        nets, err := parseTraceTargets(cfg.TraceTargets)
+        if err != nil {
+            log.Fatal(err)
+        }
+        rawnet.Trace(nets, log.Tracef)
+    }
 
     // Privilege / raw socket capability check (run-time)
     rawCapable := scanner.CheckRawSocketCapability()
@@ -40,38 +93,534 @@ func main() {
     ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
     defer stop()
 
-    // Resolve targets (with ping pre‑filter)
-    targets, err := input.ParseTargets(ctx, cfg)
-    if err != nil {
+    // Probe rate shared by every worker (and every job of a plan)
+    limiter := ratelimit.New(cfg.Rate)
+    if cfg.MaxMem != "" || cfg.MaxCPU != "" {
+        caps, err := parseCaps(cfg)
+        if err != nil {
+            log.Fatal(err)
+        }
+        g, err := guardrail.New(caps, limiter, log)
+        if err != nil {
+            log.Fatal(err)
+        }
+        go g.Run(ctx)
+    }
+
+    // Optional control socket for wrapper tooling
+    var ctl *control.Server
+    if cfg.ControlSocket != "" {
+        var err error
+        if ctl, err = control.Listen(cfg.ControlSocket, limiter, log); err != nil {
+            log.Fatal(err)
+        }
+        defer ctl.Close()
+    }
+
+    if cfg.PlanFile != "" {
+        if err := runPlan(ctx, cfg, rawCapable, limiter, ctl, log); err != nil {
+            log.Fatal(err)
+        }
+        log.Info("Plan complete")
+        return
+    }
+    if err := requireRaw(cfg, rawCapable); err != nil {
+        log.Fatal(err)
+    }
+    if err := runScan(ctx, cfg, rawCapable, limiter, ctl, log); err != nil {
         log.Fatal(err)
     }
+    if ctx.Err() != nil {
+        log.Info("Scan interrupted")
+        return
+    }
+    log.Info("Scan complete")
+}
+
+// parseCaps reads the --max-mem and --max-cpu guardrails.
+func parseCaps(cfg *config.Config) (guardrail.Caps, error) {
+    var caps guardrail.Caps
+    var err error
+    if cfg.MaxMem != "" {
+        if caps.Mem, err = guardrail.ParseSize(cfg.MaxMem); err != nil {
+            return caps, fmt.Errorf("--max-mem: %w", err)
+        }
+    }
+    if cfg.MaxCPU != "" {
+        if caps.CPU, err = guardrail.ParsePercent(cfg.MaxCPU); err != nil {
+            return caps, fmt.Errorf("--max-cpu: %w", err)
+        }
+    }
+    return caps, nil
+}
 
-    // Prepare CSV writer
-    w, err := writer.New(cfg.OutputPath)
+// resumedResults loads the results recorded by the run that wrote the
+// checkpoint at path, or none if the checkpoint does not name its output.
+func resumedResults(path string) ([]scanner.Result, error) {
+    prev, err := checkpoint.Output(path)
+    if err != nil || prev == "" {
+        return nil, err
+    }
+    if _, err := os.Stat(prev); os.IsNotExist(err) && len(writer.RotatedChunks(prev)) == 0 {
+        return nil, fmt.Errorf("resume: completed results %s not found", prev)
+    }
+    return results.Read(prev)
+}
+
+// parseTraceTargets parses the comma-separated IPs and CIDRs of
+// --trace-target.
+func parseTraceTargets(list string) ([]*net.IPNet, error) {
+    var nets []*net.IPNet
+    for _, tok := range strings.Split(list, ",") {
+        if tok = strings.TrimSpace(tok); tok == "" {
+            continue
+        }
+        if ip := net.ParseIP(tok); ip != nil {
+            bits := 8 * len(ip.To16())
+            if ip.To4() != nil {
+                ip, bits = ip.To4(), 32
+            }
+            nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+            continue
+        }
+        _, n, err := net.ParseCIDR(tok)
+        if err != nil {
+            return nil, fmt.Errorf("--trace-target: %q is not an IP or CIDR", tok)
+        }
+        nets = append(nets, n)
+    }
+    return nets, nil
+}
+
+// unprivilegedScans are the scan types that work over ordinary sockets.
+var unprivilegedScans = map[string]bool{"tcp": true, "quic": true, "tls": true}
+
+// requireRaw rejects scan types that cannot run without raw sockets.
+func requireRaw(cfg *config.Config, rawCapable bool) error {
+    if !unprivilegedScans[cfg.ScanType] && !rawCapable {
+        return fmt.Errorf("%s scan requires raw socket privileges", cfg.ScanType)
+    }
+    if cfg.Stateless && !rawCapable {
+        return errors.New("stateless mode requires raw socket privileges")
+    }
+    if cfg.Traceroute != "" && !rawCapable {
+        return errors.New("--traceroute requires raw socket privileges")
+    }
+    return nil
+}
+
+// runScan performs one complete scan: target resolution, probing and output.
+func runScan(ctx context.Context, cfg *config.Config, rawCapable bool, limiter *ratelimit.Limiter, ctl *control.Server, log *logger.Logger) error {
+    phases := &phaseTimer{}
+    if cfg.PartitionBy != "" {
+        // The output names a directory from here on (checkpoints, resume)
+        cfg.OutputPath = writer.PartitionDir(cfg.OutputPath)
+    }
+
+    // Resolve targets (with DNS pre-resolution and ping pre‑filter)
+    phases.Start("targets")
+    if cfg.TargetsFrom == "consul" {
+        var services []string
+        for _, s := range strings.Split(cfg.ConsulServices, ",") {
+            if s = strings.TrimSpace(s); s != "" {
+                services = append(services, s)
+            }
+        }
+        catalog, err := consul.Load(ctx, cfg.ConsulAddr, cfg.ConsulDC, services)
+        if err != nil {
+            return err
+        }
+        cfg.Consul = catalog
+        log.Info(fmt.Sprintf("consul: %d registrations on %d nodes", len(cfg.Consul.Endpoints), len(cfg.Consul.Nodes())))
+    }
+    confirm := func(n int) error { return confirmScan(cfg, estimateScan(cfg, n, rawCapable), log) }
+    targets, downHosts, hostNames, err := input.ParseTargets(ctx, cfg, log, confirm)
     if err != nil {
-        log.Fatal(err)
+        return err
+    }
+
+    // Pre-flight: compute source addressing per destination network for raw engines
+    phases.Start("preflight")
+    if rawCapable && cfg.Via == "" {
+        n := scanner.PrewarmRoutes(targets.IPs())
+        log.Info(fmt.Sprintf("preflight: source addresses cached for %d networks", n))
+    }
+
+    // Result enrichment (ownership annotations, ...)
+    var enrichers []prober.Enricher
+    var metaCols []string
+    if cfg.MonoOffset {
+        enrichers = append(enrichers, newClockStamp())
+        metaCols = append(metaCols, clockColumn)
+    }
+    if cfg.Banner {
+        metaCols = append(metaCols, writer.BannerColumn)
+    }
+    switch cfg.ScanType {
+    case "quic":
+        metaCols = append(metaCols, scanner.QUICColumns...)
+    case "tls":
+        metaCols = append(metaCols, scanner.TLSColumns...)
+        if cfg.TLSCerts {
+            metaCols = append(metaCols, scanner.TLSCertColumns...)
+        }
+    }
+    var ownerTable *owners.Table
+    if cfg.OwnersFile != "" {
+        ownerTable, err = owners.Load(cfg.OwnersFile)
+        if err != nil {
+            return err
+        }
+        enrichers = append(enrichers, ownerTable)
+        metaCols = append(metaCols, owners.Columns...)
+    }
+    if cfg.AssetsFile != "" {
+        assetMap, err := assets.Load(ctx, cfg.AssetsFile)
+        if err != nil {
+            return err
+        }
+        enrichers = append(enrichers, assetMap)
+        metaCols = append(metaCols, assets.Columns...)
+    }
+    if cfg.ServiceDetect || cfg.ServiceDB != "" {
+        detector, err := service.New(cfg.ServiceTimeout, cfg.ServiceDB)
+        if err != nil {
+            return err
+        }
+        enrichers = append(enrichers, detector)
+        metaCols = append(metaCols, service.Columns...)
+    }
+    if communities := snmp.Parse(cfg.SNMPCommunities); len(communities) > 0 {
+        enrichers = append(enrichers, snmp.New(communities, cfg.SNMPTimeout))
+        metaCols = append(metaCols, snmp.Columns...)
+    }
+    if cfg.TriageFile != "" {
+        rules, err := triage.Load(cfg.TriageFile)
+        if err != nil {
+            return err
+        }
+        enrichers = append(enrichers, rules)
+        metaCols = append(metaCols, triage.Columns...)
+    }
+    if cfg.Consul != nil {
+        enrichers = append(enrichers, input.ConsulServices{Catalog: cfg.Consul})
+        metaCols = append(metaCols, input.ConsulColumns...)
+    }
+    if len(hostNames) > 0 {
+        enrichers = append(enrichers, hostNames)
+        metaCols = append(metaCols, input.HostColumns...)
+    }
+    // rdns runs later but names its column whether or not --ptr is set,
+    // so an input "hostname" column is never mistaken for a PTR name
+    reserved := append([]string{writer.BannerColumn, writer.ServiceColumn}, rdns.Columns...)
+    inputMeta, err := input.LoadAssetMeta(ctx, cfg, append(reserved, metaCols...))
+    if err != nil {
+        return err
+    }
+    if inputMeta != nil {
+        enrichers = append(enrichers, inputMeta)
+        metaCols = append(metaCols, inputMeta.Columns...)
+    }
+    if cfg.PTR {
+        resolver := rdns.New(ctx)
+        resolver.Prime(targets.IPs(), cfg.PTRWorkers)
+        enrichers = append(enrichers, resolver)
+        metaCols = append(metaCols, rdns.Columns...)
+    }
+    // Pseudonymization must see the final row, so it always runs last
+    var anon *anonymize.Anonymizer
+    if cfg.Anonymize {
+        if anon, err = anonymize.FromEnv(); err != nil {
+            return err
+        }
+        enrichers = append(enrichers, anon)
     }
 
-    // Build scanner factory
-    scanEngine := scanner.NewFactory(cfg, rawCapable)
+    // Fragile devices get single, spaced, header-only probes
+    var guard *fragile.Guard
+    if cfg.FragileFile != "" {
+        if guard, err = fragile.Load(cfg.FragileFile, cfg.FragileGap); err != nil {
+            return err
+        }
+    }
+    down, err := hostdown.New(cfg.HostDownAfter, cfg.HostDownErrors)
+    if err != nil {
+        return fmt.Errorf("--host-down-errors: %w", err)
+    }
+
+    filters, err := filter.Parse(cfg.Filters)
+    if err != nil {
+        return err
+    }
+    if cfg.OpenOnly {
+        filters = append(filters, filter.OpenOnly())
+    }
+
+    // Results the checkpoint's run already completed are carried into the
+    // new output (read first: it may be the same file)
+    var completed []scanner.Result
+    if cfg.ResumeFile != "" && cfg.AggregateOnly {
+        log.Warn("resume: aggregate output holds no host rows to carry over; counts cover the remaining targets only")
+    } else if cfg.ResumeFile != "" {
+        if completed, err = resumedResults(cfg.ResumeFile); err != nil {
+            return err
+        }
+    }
+
+    // Prepare output writer; partitioned output has no single file
+    w, err := writer.NewFormat("", cfg.Format, metaCols, filters...)
+    if err != nil {
+        return err
+    }
+    w.Encrypt(cfg.EncryptKey)
+    command := os.Args[1:]
+    if cfg.Anonymize {
+        command = anonymizedCommand(command) // the json head, gnmap, xml, sqlite and markdown all record it
+    }
+    w.SetScanInfo(writer.ScanInfo{Started: time.Now(), Command: command, ScanType: cfg.ScanType, Targets: targets.Len()})
+    switch {
+    case cfg.AggregateOnly:
+        err = w.AddAggregate(cfg.OutputPath, cfg.AggregateMin, cfg.AggregateEpsilon)
+    case cfg.PartitionBy != "":
+        err = w.AddPartitioned(cfg.OutputPath, cfg.PartitionBy)
+    case cfg.RotateRows > 0 || cfg.RotateBytes > 0:
+        err = w.AddRotating(cfg.OutputPath, cfg.RotateRows, cfg.RotateBytes)
+    case cfg.OutputAll != "":
+        for _, f := range writer.AllFormats {
+            if err = w.AddMirrorFormat(cfg.OutputAll+"."+f, f); err != nil {
+                break
+            }
+        }
+    default:
+        err = w.AddMirror(cfg.OutputPath)
+    }
+    if err != nil {
+        return err
+    }
+    if err := w.SetAddrFormat(cfg.AddrFormat); err != nil {
+        return err
+    }
+    if cfg.InventoryPath != "" {
+        if err := w.AddInventory(cfg.InventoryPath); err != nil {
+            return err
+        }
+    }
+    if cfg.MarkdownPath != "" {
+        if err := w.AddMarkdown(cfg.MarkdownPath); err != nil {
+            return err
+        }
+    }
+    for _, m := range strings.Split(cfg.Mirrors, ",") {
+        if m = strings.TrimSpace(m); m == "" {
+            continue
+        }
+        if err := w.AddMirror(m); err != nil {
+            return err
+        }
+    }
+    var tracer *traceroute.Tracer
+    var sampler *traceroute.Sampler
+    if cfg.Traceroute != "" {
+        if tracer, err = traceroute.New(cfg.Traceroute, cfg.TracerouteHops); err != nil {
+            return err
+        }
+        sampler = traceroute.NewSampler(cfg.TracerouteSample)
+        w.AddSink("traceroute", sampler)
+    }
+
+    // Writer goroutine
+    go w.Run()
+    for _, r := range completed {
+        w.Submit(r)
+    }
+    if len(completed) > 0 {
+        log.Info(fmt.Sprintf("resume: carried over %d completed results", len(completed)))
+    }
+    if cfg.ReportDown {
+        // One row per host dropped by discovery, so the output accounts for every input host
+        for _, ip := range downHosts {
+            r := scanner.Result{IP: ip, Proto: cfg.ScanType, Status: scanner.HostDown, Time: time.Now()}
+            prober.Enrich(&r, enrichers, guard)
+            w.Submit(r)
+        }
+    }
+
+    // The job is complete before the control socket can see it; the engine
+    // is chosen below, and remaining() reports nothing until probing starts
+    run := &scanRun{cfg: cfg, targets: targets, w: w, enrichers: enrichers, limiter: limiter, guard: guard, down: down, log: log, inflight: prober.NewInFlight()}
+    job := &control.Job{Name: cfg.OutputPath, Total: targets.Len(), Written: w.Written, Key: cfg.EncryptKey}
+    if !cfg.Stateless {
+        job.Remaining = run.remaining
+    }
+    if ctl != nil {
+        ctl.Attach(job)
+        defer ctl.Detach(job.Name)
+    }
+
+    phases.Start("scan")
+    if cfg.Stateless {
+        err = runStateless(ctx, cfg, targets, w, enrichers, limiter, guard, log)
+    } else {
+        for _, line := range cfg.EgressProfiles.Summary() {
+            log.Info("egress " + line)
+        }
+        // Build scanner factory
+        scanEngine := scanner.NewFactory(cfg, rawCapable)
+        if cfg.Via != "" {
+            if scanEngine, err = scanner.NewSSHScanner(cfg); err != nil {
+                return err
+            }
+            log.Info("connect-scanning through jump host " + cfg.Via)
+        } else if st, ok := scanEngine.(scanner.SelfTester); ok {
+            if err := st.SelfTest(ctx); err != nil {
+                // Every port would silently read FILTERED; connect scans are slower but honest
+                log.Warn("raw SYN path failed its self-test (" + err.Error() + "); switching to connect scans")
+                if c, ok := scanEngine.(io.Closer); ok {
+                    c.Close()
+                }
+                scanEngine = scanner.NewSocketScanner(cfg)
+            }
+        }
+        if c, ok := scanEngine.(io.Closer); ok {
+            defer c.Close()
+        }
+        guard.UseEngine(cfg, scanEngine)
+        engine := scanEngine
+        if targets.HasUDP() {
+            engine = scanner.WithUDP(scanEngine, cfg)
+        }
+        run.engine = engine
+        run.run(ctx)
+        if s, ok := scanEngine.(interface{ Strays() uint64 }); ok && s.Strays() > 0 {
+            log.Info(fmt.Sprintf("syn: ignored %d replies that did not acknowledge a pending probe", s.Strays()))
+        }
+    }
+    flushed := true
+    if ctx.Err() == nil {
+        w.Close()
+        for _, line := range w.Summary() {
+            log.Warn(line)
+        }
+    } else if flushed = shutdownPhase(log, "flush-sinks", cfg.ShutdownFlush, w.Close); flushed {
+        for _, line := range w.Summary() {
+            log.Warn(line)
+        }
+    }
+    if ctx.Err() != nil && job.Remaining != nil {
+        shutdownPhase(log, "write-checkpoint", cfg.ShutdownCheckpoint, func() {
+            writeCheckpoint(cfg, job.Remaining(), log)
+        })
+    }
+    if tracer != nil && ctx.Err() == nil {
+        phases.Start("traceroute")
+        tracePaths(ctx, cfg, tracer, sampler.Sample(), log)
+    }
+    if anon != nil {
+        if err := anon.WriteMapping(cfg.AnonymizeMap); err != nil {
+            log.Warn("anonymize: cannot write mapping: " + err.Error())
+        } else {
+            log.Info("anonymize: encrypted mapping saved to " + cfg.AnonymizeMap)
+        }
+    }
+    phases.Stop()
+    if flushed { // else the writer may still be counting
+        reportSummary(cfg, targets.Len(), phases.Elapsed("scan"), w.Stats(), log)
+    }
+    for _, line := range down.Summary() {
+        log.Info("host-down " + line)
+    }
+    for _, line := range phases.Summary() {
+        log.Info("phase " + line)
+    }
+    if ownerTable != nil {
+        for _, line := range ownerTable.Summary() {
+            log.Info("owner " + line)
+        }
+    }
+    return err
+}
+
+// scanRun bundles what the probing stage of one scan needs.
+type scanRun struct {
+    cfg       *config.Config
+    targets   *input.Targets
+    engine    scanner.Scanner
+    w         *writer.CSVWriter
+    enrichers []prober.Enricher
+    limiter   *ratelimit.Limiter
+    guard     *fragile.Guard
+    down      *hostdown.Policy
+    log       *logger.Logger
+
+    dispatched int64            // how many of order were queued
+    inflight   *prober.InFlight // taken off the queue, not yet submitted
+
+    mu     sync.Mutex     // guards order and queued, read by the control socket
+    order  *input.Targets // targets in dispatch order
+    queued func() int     // upper bound of targets still in the queue
+}
+
+// start records the dispatch order and queue of a run about to begin.
+func (r *scanRun) start(order *input.Targets, queued func() int) {
+    r.mu.Lock()
+    r.order, r.queued = order, queued
+    r.mu.Unlock()
+}
+
+// remaining returns the targets not yet probed, or nil before the run has
+// started: those workers hold, then those queued or not yet dispatched.
+// Once the workers have stopped it is exact; a snapshot taken mid-run may
+// repeat or miss a target that is changing hands at that moment.
+func (r *scanRun) remaining() []input.ProbeTarget {
+    r.mu.Lock()
+    order, queued := r.order, r.queued
+    r.mu.Unlock()
+    if queued == nil {
+        return nil
+    }
+    n := int(atomic.LoadInt64(&r.dispatched)) - queued()
+    if n < 0 {
+        n = 0
+    }
+    return append(r.inflight.Targets(), order.From(n)...)
+}
+
+// run feeds targets to a pool of probing workers and waits for them.
+func (r *scanRun) run(ctx context.Context) {
+    if _, ok := r.engine.(scanner.BatchScanner); ok && r.cfg.BatchPorts > 1 {
+        r.runBatches(ctx)
+        return
+    }
+    if r.cfg.BatchPorts > 1 {
+        r.log.Warn("--batch-ports needs the SYN engine; probing one port at a time")
+    }
 
-    // Launch worker pool
     wg := &sync.WaitGroup{}
-    taskCh := make(chan input.ProbeTarget, cfg.QueueSize)
+    taskCh := make(chan input.ProbeTarget, r.cfg.QueueSize)
+    r.start(r.targets, func() int { return len(taskCh) })
 
-    // Producer goroutine – feeds taskCh then closes
+    // Producer goroutine – feeds taskCh until done or interrupted, then closes
+    produced := make(chan struct{})
     go func() {
+        defer close(produced)
         defer close(taskCh)
-        for _, t := range targets {
-            taskCh <- t
+        for it := r.targets.Iter(0); ; {
+            t, ok := it.Next()
+            if !ok {
+                return
+            }
+            select {
+            case taskCh <- t:
+            case <-ctx.Done():
+                return
+            }
+            atomic.AddInt64(&r.dispatched, 1)
         }
     }()
 
-    // Writer goroutine
-    go w.Run()
-
-    for i := 0; i < cfg.NumWorkers; i++ {
-        worker := prober.New(i, scanEngine, w, cfg, log)
+    for i := 0; i < r.cfg.NumWorkers; i++ {
+        worker := prober.New(i, r.engine, r.w, r.enrichers, r.limiter, r.guard, r.down, r.cfg, r.log)
+        worker.Track(r.inflight)
         wg.Add(1)
         go func() {
             defer wg.Done()
@@ -79,42 +628,382 @@ func main() {
         }()
     }
 
-    // Graceful shutdown & checkpoint
-    go checkpoint.Handle(ctx, cfg, taskCh, log)
+    r.wait(ctx, produced, wg)
+}
 
-    wg.Wait()
-    w.Close()
-    log.Info("Scan complete")
+// runBatches groups each host's ports into batches of --batch-ports and
+// hands whole batches to workers.
+func (r *scanRun) runBatches(ctx context.Context) {
+    order := r.targets.ByHost()
+    wg := &sync.WaitGroup{}
+    batchCh := make(chan []input.ProbeTarget, r.cfg.QueueSize)
+    r.start(order, func() int { return len(batchCh) * r.cfg.BatchPorts })
+    produced := make(chan struct{})
+    go func() {
+        defer close(produced)
+        defer close(batchCh)
+        var batch []input.ProbeTarget
+        send := func() bool {
+            select {
+            case batchCh <- batch:
+            case <-ctx.Done():
+                return false
+            }
+            atomic.AddInt64(&r.dispatched, int64(len(batch)))
+            batch = nil
+            return true
+        }
+        for it := order.Iter(0); ; {
+            t, ok := it.Next()
+            if !ok {
+                break
+            }
+            if len(batch) > 0 && (batch[0].IP != t.IP || len(batch) == r.cfg.BatchPorts) && !send() {
+                return
+            }
+            batch = append(batch, t)
+        }
+        if len(batch) > 0 {
+            send()
+        }
+    }()
+
+    for i := 0; i < r.cfg.NumWorkers; i++ {
+        worker := prober.New(i, r.engine, r.w, r.enrichers, r.limiter, r.guard, r.down, r.cfg, r.log)
+        worker.Track(r.inflight)
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            worker.RunBatches(ctx, batchCh)
+        }()
+    }
+
+    r.wait(ctx, produced, wg)
 }
 
 // parseFlags initialises Config from CLI flags.
 func parseFlags() *config.Config {
     cfg := &config.Config{}
 
-    flag.StringVar(&cfg.IPInput, "ip", "", "IPv4/CIDR/host list or CSV file (required)")
-    flag.StringVar(&cfg.PortInput, "port", "", "Port list/range or CSV file (required)")
+    // Flags whose values name hosts, networks, files of them or credentials
+    // must also be listed in targetFlags (support.go), or support bundles
+    // will ship them unredacted.
+    flag.StringVar(&cfg.IPInput, "ip", "", "IPv4/CIDR/host list, CSV file or nmap XML report (required); link:IFACE finds on-link IPv6 hosts. A CSV's other columns are copied onto its results, and --anonymize leaves them as they are")
+    flag.BoolVar(&cfg.NmapPorts, "nmap-ports", false, "With an nmap XML --ip, rescan the ports it reported open instead of --port")
+    flag.StringVar(&cfg.PortInput, "port", "", "Port list/range, service names or CSV file (required); nmap-style T:/U: prefixes and - for all ports, e.g. T:ssh,80,U:53")
+    flag.StringVar(&cfg.Exclude, "exclude", "", "Comma-separated IPs, CIDRs or hostnames to leave out of the targets")
+    flag.StringVar(&cfg.ExcludeFile, "exclude-file", "", "File of IPs, CIDRs or hostnames to leave out, one per line ('#' comments)")
+    flag.IntVar(&cfg.TopPorts, "top-ports", 0, "Also scan the N most commonly open TCP ports from the built-in frequency table (up to 75)")
+    flag.StringVar(&cfg.ExcludePorts, "exclude-ports", "", "Ports or ranges to leave out of --port, e.g. 9100,6000-6063")
+    flag.StringVar(&cfg.Sample, "sample", "", "Scan only a uniformly random subset of the hosts: a count (5000) or a percentage (1%)")
+    flag.Int64Var(&cfg.SampleSeed, "sample-seed", 0, "Seed for --sample; the default picks one and logs it so the subset can be repeated")
+    flag.Var(&seedFlag{on: &cfg.Randomize, seed: &cfg.RandomSeed}, "randomize-hosts", "Scan hosts in random order; =SEED repeats an earlier order")
+    flag.StringVar(&cfg.Order, "order", "port", "Probe order: port sprays each port across all hosts first (gentler per host), host finishes one host's ports before the next")
+    flag.IntVar(&cfg.V6MinPrefix, "ipv6-min-prefix", 112, "Refuse to expand IPv6 prefixes shorter than this unless --sample N draws hosts from them")
+    flag.BoolVar(&cfg.V6ExpandAll, "ipv6-expand-all", false, "Expand IPv6 prefixes of any length host by host (may never finish)")
+    flag.BoolVar(&cfg.SkipNetBcast, "skip-network-broadcast", false, "Leave the network and broadcast addresses of IPv4 CIDRs (/30 and shorter) out of the targets; /31 and /32 are always scanned whole")
+    flag.BoolVar(&cfg.KeepReserved, "keep-reserved", false, "Keep multicast, reserved and documentation ranges (224/4, 240/4, 192.0.2.0/24, ...) in the targets")
+    flag.BoolVar(&cfg.AllowPublic, "allow-public", false, "Allow targets outside RFC 1918/ULA private space; without it such targets are refused")
+    flag.BoolVar(&cfg.AllowSelf, "allow-self", false, "Scan this machine's own interface addresses instead of skipping them")
+    flag.StringVar(&cfg.IPColumn, "ip-column", "", "CSV column holding addresses when --ip is a .csv file: header name or 1-based index (default: first)")
+    flag.StringVar(&cfg.PortColumn, "port-column", "", "CSV column holding ports when --port is a .csv file: header name or 1-based index (default: second)")
     flag.IntVar(&cfg.NumWorkers, "worker", 1, "Number of concurrent workers")
     flag.DurationVar(&cfg.Timeout, "timeout", 100*time.Millisecond, "Probe timeout")
     flag.DurationVar(&cfg.Delay, "delay", 100*time.Millisecond, "Inter‑probe delay per worker")
     flag.IntVar(&cfg.QueueSize, "queue", 1024, "Task queue size (bounded)")
     flag.BoolVar(&cfg.DryRun, "dryrun", false, "Dry‑run mode – no packets sent")
     flag.StringVar(&cfg.ResumeFile, "resume", "", "Checkpoint file to resume from")
-    flag.StringVar(&cfg.OutputPath, "output", "result.csv", "CSV output path")
+    flag.StringVar(&cfg.TargetsFrom, "targets-from", "", "Take targets from another source instead of --ip: k8s lists pod and service IPs with their declared ports, aws lists EC2 instance addresses (scanned on --port), consul lists registered service ports (or, with --port, scans those ports on the registered nodes)")
+    flag.StringVar(&cfg.ConsulAddr, "consul-addr", "", "Consul agent for --targets-from consul (default: $CONSUL_HTTP_ADDR or http://127.0.0.1:8500)")
+    flag.StringVar(&cfg.ConsulDC, "consul-dc", "", "Consul datacenter (default: the agent's)")
+    flag.StringVar(&cfg.ConsulServices, "consul-service", "", "Comma-separated Consul services to scan (default: all)")
+    flag.StringVar(&cfg.AWSRegions, "aws-region", "", "Comma-separated regions for --targets-from aws (default: $AWS_REGION)")
+    flag.StringVar(&cfg.AWSFilter, "aws-filter", "", "EC2 filters for --targets-from aws, e.g. tag:Env=prod|staging,vpc-id=vpc-0abc (default: running instances)")
+    flag.BoolVar(&cfg.AWSPublic, "aws-public", false, "Also scan the public and IPv6 addresses of --targets-from aws instances (needs --allow-public)")
+    flag.StringVar(&cfg.K8sAPI, "k8s-api", "", "Kubernetes API URL for --targets-from k8s, e.g. http://127.0.0.1:8001 for kubectl proxy (default: in-cluster service account). Kubeconfig files and contexts are not read: use kubectl proxy --context to reach another cluster")
+    flag.StringVar(&cfg.K8sNamespace, "k8s-namespace", "", "Namespace for --targets-from k8s (default: all)")
+    flag.StringVar(&cfg.K8sSelector, "k8s-selector", "", "Label selector for --targets-from k8s, e.g. app=web,tier!=db")
+    flag.StringVar(&cfg.Masscan, "masscan", "", "masscan output (-oL list or -oJ/-oD JSON): scan only the ports it found open, e.g. to confirm them with a connect scan")
+    flag.StringVar(&cfg.Rescan, "rescan", "", "Results (CSV, JSON lines or directory) of an earlier run: rescan only its open ports, e.g. to verify remediation")
+    flag.StringVar(&cfg.OutputPath, "output", "result.csv", "Output path (- streams --format ndjson to stdout)")
+    flag.StringVar(&cfg.AddrFormat, "addr-format", "canonical", "Address form in outputs: canonical (RFC 5952 IPv6) or int (IPv4 as integer)")
+    flag.StringVar(&cfg.Format, "format", "csv", "Output format: "+strings.Join(writer.Formats, " or "))
+    flag.StringVar(&cfg.ScanType, "scan", "tcp", "Scan type: tcp, sctp, ipproto (--port then lists IP protocol numbers), quic or tls")
+    flag.StringVar(&cfg.OwnersFile, "owners", "", "CSV mapping cidr,owner,team,email joined onto results")
+    flag.StringVar(&cfg.AssetsFile, "assets", "", "CSV mapping asset,address[,address...] adding an asset column")
+    flag.StringVar(&cfg.TriageFile, "triage", "", "JSON triage rules (regex on banner/service -> label/severity)")
+    flag.BoolVar(&cfg.Anonymize, "anonymize", false, "Replace IPs/hostnames in outputs with keyed pseudonyms (secret in $"+anonymize.KeyEnv+"); the extra columns of an --ip CSV are not pseudonymized")
+    flag.StringVar(&cfg.AnonymizeMap, "anonymize-map", "anonymize-map.enc", "Encrypted pseudonym mapping file written with --anonymize")
+    flag.StringVar(&cfg.FragileFile, "fragile", "", "CSV of fragile IPs/CIDRs: one probe at a time per host, no payloads or service probes")
+    flag.DurationVar(&cfg.FragileGap, "fragile-gap", 5*time.Second, "Minimum gap between probes of one fragile host")
+    flag.IntVar(&cfg.HostDownAfter, "host-down-after", 0, "Mark a host down after N consecutive connection errors and fail its remaining targets fast (0 = off)")
+    flag.StringVar(&cfg.HostDownErrors, "host-down-errors", hostdown.DefaultKinds, "Error kinds counted by --host-down-after")
+    flag.IntVar(&cfg.ConfirmAbove, "confirm-above", 1000000, "Ask before scanning more targets than this (0 never asks)")
+    flag.BoolVar(&cfg.Yes, "yes", false, "Skip the --confirm-above prompt")
+    flag.IntVar(&cfg.Rate, "rate", 0, "Maximum probes per second across all workers (0 = unlimited)")
+    flag.StringVar(&cfg.PlanFile, "plan", "", "JSON plan file with multiple scan jobs")
+    flag.BoolVar(&cfg.TLSCerts, "tls-certs", false, "With --scan tls, record the leaf certificate's subject, SANs, issuer, expiry and self-signed flag")
+    flag.BoolVar(&cfg.ServiceDetect, "service-detect", false, "Probe open TCP ports and match replies to fill service and version columns")
+    flag.StringVar(&cfg.ServiceDB, "service-db", "", "nmap-service-probes file replacing the built-in signatures (implies --service-detect)")
+    flag.DurationVar(&cfg.ServiceTimeout, "service-timeout", 2*time.Second, "Per-probe timeout for --service-detect")
+    flag.BoolVar(&cfg.Encrypt, "encrypt", false, "Encrypt outputs and checkpoints with AES-GCM, keyed from the secret in $"+seal.KeyEnv+" (no KMS or age recipients; sealed files can only be read with that secret)")
+    flag.BoolVar(&cfg.AggregateOnly, "aggregate-only", false, "Write only open-host counts per network/port/service, no per-host rows")
+    flag.IntVar(&cfg.AggregateMin, "aggregate-min", 5, "Suppress --aggregate-only cells with fewer hosts than this")
+    flag.Float64Var(&cfg.AggregateEpsilon, "aggregate-epsilon", 0, "Add Laplace noise to --aggregate-only counts for epsilon-differential privacy (0 = exact counts)")
+    flag.StringVar(&cfg.PartitionBy, "partition-by", "", "Split output into <output without extension>/<key>/results.<format> by host, network, status, engine or day")
+    flag.StringVar(&cfg.MarkdownPath, "markdown", "", "Also write a Markdown report: result counts, hosts with open ports, top services and errors")
+    flag.StringVar(&cfg.InventoryPath, "inventory", "", "Also write a service inventory CSV: unique service/version/port rows with host counts")
+    flag.Int64Var(&cfg.RotateRows, "rotate-rows", 0, "Roll the output to <output>-0001, -0002, ... every N rows (0 = never)")
+    flag.StringVar(&cfg.RotateSize, "rotate-size", "", "Roll the output to <output>-0001, -0002, ... once a chunk reaches SIZE, e.g. 512M")
+    flag.StringVar(&cfg.OutputAll, "output-all", "", "Write BASE.csv, BASE.json and BASE.xml (nmap XML) from one run instead of --output")
+    flag.StringVar(&cfg.Mirrors, "mirror", "", "Comma-separated extra CSV paths receiving a copy of the output")
+    flag.StringVar(&cfg.SNMPCommunities, "snmp-communities", "", "Comma-separated SNMP communities to try on port 161 targets (v2c GET sysDescr.0 over UDP)")
+    flag.DurationVar(&cfg.SNMPTimeout, "snmp-timeout", time.Second, "Reply timeout per SNMP community tried")
+    flag.BoolVar(&cfg.PTR, "ptr", true, "Add a hostname column from reverse DNS (--ptr=false to disable)")
+    flag.IntVar(&cfg.PTRWorkers, "ptr-workers", 32, "Concurrent reverse DNS lookups")
+    flag.BoolVar(&cfg.Banner, "banner", false, "Read each open port's greeting into a banner column (uses connect scans)")
+    flag.IntVar(&cfg.BannerBytes, "banner-bytes", 512, "Maximum banner bytes kept per port")
+    flag.DurationVar(&cfg.BannerTimeout, "banner-timeout", 2*time.Second, "How long to wait for a greeting after connecting")
+    flag.BoolVar(&cfg.OpenOnly, "open", false, "Write only OPEN results, dropping CLOSED, FILTERED and other rows (same as --filter open)")
+    flag.StringVar(&cfg.Filters, "filter", "", "Comma-separated result filters applied before output: open, dedupe, changes, confidence=low|medium|high")
+    flag.BoolVar(&cfg.AdaptiveTimeout, "adaptive-timeout", false, "Scale each host's timeout to 3x its measured RTT, between --min-timeout and --timeout")
+    flag.DurationVar(&cfg.MinTimeout, "min-timeout", 20*time.Millisecond, "Lower bound for --adaptive-timeout")
+    flag.IntVar(&cfg.Retries, "retries", 0, "Re-probe FILTERED/ERROR results up to N more times before recording them")
+    flag.BoolVar(&cfg.MonoOffset, "mono-offset", false, "Add a mono_offset_us column: time since scan start on the monotonic clock, immune to wall-clock steps")
+    flag.StringVar(&cfg.PingMethods, "ping-method", "icmp,timestamp,tcp:22,80,443,ack:80,udp:53", "Host discovery probes, any answer counts as up: icmp, timestamp, tcp:PORTS, ack:PORTS, udp:PORTS")
+    flag.IntVar(&cfg.PingCount, "ping-count", 1, "Send each discovery probe up to N times, stopping at the first answer")
+    flag.IntVar(&cfg.PingRetry, "ping-retry", 0, "Extra discovery rounds before a silent host is declared down")
+    flag.BoolVar(&cfg.ReportDown, "report-down", true, "Write a HOST_DOWN row (port 0) for every host dropped by discovery")
+    flag.StringVar(&cfg.Via, "via", "", "Connect-scan through an SSH jump host, ssh://user@host[:port] (raise --timeout for the extra hop)")
+    flag.StringVar(&cfg.EgressFile, "egress-profiles", "", "JSON file of named egress profiles (interface, source, routes); targets use the profile routing them")
+    flag.StringVar(&cfg.Egress, "egress", "", "Use this egress profile for every target")
+    flag.StringVar(&cfg.DiscoveryCache, "discovery-cache", "", "Reuse host discovery verdicts from this file and save new ones to it")
+    flag.DurationVar(&cfg.DiscoveryCacheTTL, "discovery-cache-ttl", 24*time.Hour, "How long a cached discovery verdict is trusted (0 = forever)")
+    flag.StringVar(&cfg.TraceTargets, "trace-target", "", "Comma-separated IPs/CIDRs whose raw packets are hex-dumped to the log at TRACE level")
+    flag.StringVar(&cfg.ControlSocket, "control-socket", "", "Unix socket accepting status/pause/resume/set-rate/checkpoint/dump-goroutines")
+    flag.IntVar(&cfg.BatchPorts, "batch-ports", 0, "SYN scan: send up to N ports of one host back-to-back per worker pass")
+    flag.BoolVar(&cfg.NoRST, "no-rst", false, "Do not send RST after a SYN-ACK in SYN scans (leaves half-open connections)")
+    flag.BoolVar(&cfg.Stateless, "stateless", false, "Masscan-style SYN sweep with SipHash cookies; only responders are reported")
+    flag.DurationVar(&cfg.StatelessWait, "stateless-wait", 2*time.Second, "How long to collect late replies after the last stateless SYN")
+    flag.StringVar(&cfg.Traceroute, "traceroute", "", "Trace hop paths to a sample of targets, FILTERED ones first: icmp, udp or tcp (needs raw sockets)")
+    flag.IntVar(&cfg.TracerouteSample, "traceroute-sample", 10, "How many targets --traceroute traces")
+    flag.IntVar(&cfg.TracerouteHops, "traceroute-max-hops", 30, "Highest TTL --traceroute probes with")
+    flag.StringVar(&cfg.TracerouteReport, "traceroute-report", "", "Path report CSV for --traceroute (default: <output>.paths.csv)")
+    flag.StringVar(&cfg.MaxMem, "max-mem", "", "Throttle workers while resident memory exceeds this, e.g. 2G")
+    flag.StringVar(&cfg.MaxCPU, "max-cpu", "", "Throttle workers while CPU use exceeds this share of the machine, e.g. 50%")
+    flag.DurationVar(&cfg.ShutdownProduce, "shutdown-produce", 2*time.Second, "On interrupt: time allowed for target dispatch to stop")
+    flag.DurationVar(&cfg.ShutdownDrain, "shutdown-drain", 10*time.Second, "On interrupt: time allowed for workers to finish in-flight probes")
+    flag.DurationVar(&cfg.ShutdownFlush, "shutdown-flush", 10*time.Second, "On interrupt: time allowed for output sinks to flush and close")
+    flag.DurationVar(&cfg.ShutdownCheckpoint, "shutdown-checkpoint", 5*time.Second, "On interrupt: time allowed for writing the checkpoint")
 
     flag.Parse()
 
-    if cfg.IPInput == "" && cfg.ResumeFile == "" {
-        fmt.Println("--ip or --resume is required")
+    if cfg.TargetsFrom != "" && !slices.Contains([]string{"k8s", "aws", "consul"}, cfg.TargetsFrom) {
+        fmt.Println("--targets-from must be one of k8s, aws, consul")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if sources := nonEmpty(cfg.TargetsFrom, cfg.Rescan, cfg.Masscan); sources > 1 {
+        fmt.Println("--targets-from, --rescan and --masscan are alternative target sources: pick one")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if (cfg.Rescan != "" || cfg.Masscan != "" || cfg.TargetsFrom == "k8s") && (cfg.IPInput != "" || cfg.PortInput != "" || cfg.TopPorts > 0) {
+        fmt.Println("--rescan, --masscan and --targets-from k8s bring their own ports: they cannot be combined with --ip, --port or --top-ports")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if (cfg.TargetsFrom == "aws" || cfg.TargetsFrom == "consul") && cfg.IPInput != "" {
+        fmt.Printf("--targets-from %s replaces --ip\n", cfg.TargetsFrom)
+        flag.Usage()
+        os.Exit(1)
+    }
+    if cfg.NmapPorts && (!strings.HasSuffix(cfg.IPInput, ".xml") || cfg.PortInput != "" || cfg.TopPorts > 0) {
+        fmt.Println("--nmap-ports takes the ports from an nmap XML --ip: it needs one, and no --port or --top-ports")
         flag.Usage()
         os.Exit(1)
     }
-    if cfg.PortInput == "" && cfg.ResumeFile == "" {
-        fmt.Println("--port or --resume is required")
+    listed := cfg.Rescan != "" || cfg.Masscan != "" || cfg.TargetsFrom == "k8s" || cfg.TargetsFrom == "consul" || cfg.NmapPorts
+    if cfg.IPInput == "" && cfg.ResumeFile == "" && cfg.PlanFile == "" && cfg.TargetsFrom == "" && cfg.Rescan == "" && cfg.Masscan == "" {
+        fmt.Println("--ip, --rescan, --masscan, --targets-from, --resume or --plan is required")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if cfg.PortInput == "" && cfg.TopPorts == 0 && cfg.ResumeFile == "" && cfg.PlanFile == "" && !listed {
+        fmt.Println("--port, --top-ports, --resume or --plan is required")
+        flag.Usage()
+        os.Exit(1)
+    }
+
+    if !config.ValidScanType(cfg.ScanType) {
+        fmt.Printf("--scan must be one of %s\n", strings.Join(config.ScanTypes, ", "))
         flag.Usage()
         os.Exit(1)
     }
 
-    cfg.LogPath = filepath.Join(".", "portRunner-"+time.Now().Format("2006-01-02")+".log")
+    if cfg.V6MinPrefix < 1 || cfg.V6MinPrefix > 128 {
+        fmt.Println("--ipv6-min-prefix must be between 1 and 128")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if !slices.Contains(input.Orders, cfg.Order) {
+        fmt.Printf("--order must be one of %s\n", strings.Join(input.Orders, ", "))
+        flag.Usage()
+        os.Exit(1)
+    }
+
+    if !slices.Contains(writer.Formats, cfg.Format) {
+        fmt.Printf("--format must be one of %s\n", strings.Join(writer.Formats, ", "))
+        flag.Usage()
+        os.Exit(1)
+    }
+    if cfg.Encrypt {
+        key, err := seal.KeyFromEnv()
+        if err != nil {
+            fmt.Println(err)
+            os.Exit(1)
+        }
+        cfg.EncryptKey = key
+    }
+    if cfg.OutputPath == writer.Stdout && (cfg.Format != "ndjson" || cfg.Encrypt || cfg.PartitionBy != "" || cfg.AggregateOnly) {
+        fmt.Println("--output - streams --format ndjson: it cannot be combined with --encrypt, --partition-by or --aggregate-only")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if cfg.OutputAll != "" {
+        if cfg.PartitionBy != "" || cfg.AggregateOnly || cfg.OutputPath == writer.Stdout {
+            fmt.Println("--output-all writes one file per format: it cannot be combined with --partition-by, --aggregate-only or --output -")
+            flag.Usage()
+            os.Exit(1)
+        }
+        cfg.OutputPath = cfg.OutputAll + ".csv" // names the run in checkpoints and status
+    }
+    if cfg.RotateSize != "" {
+        size, err := guardrail.ParseSize(cfg.RotateSize)
+        if err != nil {
+            fmt.Println("--rotate-size:", err)
+            os.Exit(1)
+        }
+        cfg.RotateBytes = int64(size)
+    }
+    if cfg.RotateRows > 0 || cfg.RotateBytes > 0 {
+        if !slices.Contains(writer.RotateFormats, cfg.Format) || cfg.PartitionBy != "" || cfg.AggregateOnly || cfg.OutputAll != "" || cfg.OutputPath == writer.Stdout {
+            fmt.Printf("--rotate-rows and --rotate-size roll a %s output file: they cannot be combined with --partition-by, --aggregate-only, --output-all or --output -\n", strings.Join(writer.RotateFormats, ", "))
+            flag.Usage()
+            os.Exit(1)
+        }
+    }
+    if cfg.Format == "sqlite" && cfg.Encrypt {
+        fmt.Println("--format sqlite cannot be encrypted: use csv, jsonl or json with --encrypt")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if (cfg.Format == "json" || cfg.Format == "gnmap") && cfg.PartitionBy != "" {
+        fmt.Printf("--format %s writes one document: use jsonl with --partition-by\n", cfg.Format)
+        flag.Usage()
+        os.Exit(1)
+    }
+    if cfg.PartitionBy != "" && !slices.Contains(writer.Partitions, cfg.PartitionBy) {
+        fmt.Printf("--partition-by must be one of %s\n", strings.Join(writer.Partitions, ", "))
+        flag.Usage()
+        os.Exit(1)
+    }
+
+    if cfg.Traceroute != "" && !slices.Contains(traceroute.Methods, cfg.Traceroute) {
+        fmt.Printf("--traceroute must be one of %s\n", strings.Join(traceroute.Methods, ", "))
+        flag.Usage()
+        os.Exit(1)
+    }
+    if cfg.Traceroute != "" && cfg.Anonymize {
+        fmt.Println("--traceroute needs real addresses: it cannot be combined with --anonymize")
+        flag.Usage()
+        os.Exit(1)
+    }
+    if (cfg.Banner || cfg.SNMPCommunities != "") && cfg.Anonymize {
+        fmt.Println("--banner and --snmp-communities record raw greetings and sysDescr text that name hosts: they cannot be combined with --anonymize")
+        flag.Usage()
+        os.Exit(1)
+    }
+
+    if cfg.Via != "" && (cfg.ScanType != "tcp" || cfg.Stateless || cfg.Traceroute != "") {
+        fmt.Println("--via connect-scans TCP through the jump host: it cannot be combined with --scan other than tcp, --stateless or --traceroute")
+        flag.Usage()
+        os.Exit(1)
+    }
+
+    if cfg.EgressFile != "" {
+        if cfg.Stateless || cfg.Via != "" || cfg.Traceroute != "" || !unprivilegedScans[cfg.ScanType] {
+            fmt.Println("--egress-profiles binds ordinary sockets: it cannot be combined with --stateless, --via, --traceroute or raw scan types")
+            flag.Usage()
+            os.Exit(1)
+        }
+        profiles, err := egress.Load(cfg.EgressFile, cfg.Egress)
+        if err != nil {
+            fmt.Println("--egress-profiles:", err)
+            os.Exit(1)
+        }
+        cfg.EgressProfiles = profiles
+    } else if cfg.Egress != "" {
+        fmt.Println("--egress needs --egress-profiles")
+        flag.Usage()
+        os.Exit(1)
+    }
+
+    if cfg.AggregateOnly && (cfg.PartitionBy != "" || cfg.InventoryPath != "" || cfg.MarkdownPath != "" || cfg.Mirrors != "" || cfg.Format != "csv") {
+        fmt.Println("--aggregate-only writes a single CSV: it cannot be combined with --partition-by, --inventory, --markdown, --mirror or --format")
+        flag.Usage()
+        os.Exit(1)
+    }
+
+    cfg.LogPath = defaultLogPath()
 
     return cfg
+}
+
+// nonEmpty counts the non-empty strings.
+func nonEmpty(s ...string) int {
+    n := 0
+    for _, v := range s {
+        if v != "" {
+            n++
+        }
+    }
+    return n
+}
+
+// seedFlag is a boolean flag that optionally takes a seed: "--name" turns
+// it on with a fresh seed, "--name=42" with seed 42.
+type seedFlag struct {
+    on   *bool
+    seed *int64
+}
+
+func (f *seedFlag) IsBoolFlag() bool { return true }
+
+func (f *seedFlag) String() string {
+    if f.on == nil || !*f.on {
+        return "false"
+    }
+    if *f.seed != 0 {
+        return strconv.FormatInt(*f.seed, 10)
+    }
+    return "true"
+}
+
+func (f *seedFlag) Set(s string) error {
+    if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+        *f.on, *f.seed = true, n
+        return nil
+    }
+    b, err := strconv.ParseBool(s)
+    if err != nil {
+        return fmt.Errorf("want true, false or an integer seed")
+    }
+    *f.on, *f.seed = b, 0
+    return nil
+}
+
+// defaultLogPath returns the per-day log file in the working directory.
+func defaultLogPath() string {
+    return filepath.Join(".", "portRunner-"+time.Now().Format("2006-01-02")+".log")
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+// File: cmd/goscant/paths.go
+package main
+
+import (
+    "context"
+    "fmt"
+    "path/filepath"
+    "strings"
+
+    "goscant/internal/config"
+    "goscant/internal/logger"
+    "goscant/internal/scanner"
+    "goscant/internal/traceroute"
+)
+
+// tracePaths traces the sampled targets after a scan, logs where each path
+// ended and writes the path report.
+func tracePaths(ctx context.Context, cfg *config.Config, tracer *traceroute.Tracer, sample []scanner.Result, log *logger.Logger) {
+    if len(sample) == 0 {
+        log.Info("traceroute: no targets to trace")
+        return
+    }
+    log.Info(fmt.Sprintf("traceroute: tracing %d targets with %s probes", len(sample), cfg.Traceroute))
+    paths := tracer.Run(ctx, sample)
+    for _, p := range paths {
+        log.Info("traceroute: " + p.Summary())
+    }
+    report := cfg.TracerouteReport
+    if report == "" {
+        report = strings.TrimSuffix(cfg.OutputPath, filepath.Ext(cfg.OutputPath)) + ".paths.csv"
+    }
+    if err := traceroute.WriteReport(report, paths, cfg.EncryptKey); err != nil {
+        log.Warn("traceroute: cannot write report: " + err.Error())
+        return
+    }
+    log.Info("traceroute: path report saved to " + report)
+}
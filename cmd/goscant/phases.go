@@ -0,0 +1,50 @@
+// File: cmd/goscant/phases.go
+package main
+
+import (
+    "fmt"
+    "time"
+)
+
+// phaseTimer records how long each run phase took so the reported scan
+// duration reflects probing rather than setup. Durations come from the
+// monotonic clock, so wall-clock steps do not skew them.
+type phaseTimer struct {
+    names   []string
+    elapsed []time.Duration
+    current time.Time
+}
+
+// Start closes the running phase (if any) and begins timing name.
+func (p *phaseTimer) Start(name string) {
+    p.Stop()
+    p.names = append(p.names, name)
+    p.current = time.Now()
+}
+
+// Stop closes the running phase.
+func (p *phaseTimer) Stop() {
+    if len(p.names) > len(p.elapsed) {
+        p.elapsed = append(p.elapsed, time.Since(p.current))
+    }
+}
+
+// Elapsed returns the total time spent in phases called name.
+func (p *phaseTimer) Elapsed(name string) time.Duration {
+    var d time.Duration
+    for i, e := range p.elapsed {
+        if p.names[i] == name {
+            d += e
+        }
+    }
+    return d
+}
+
+// Summary returns one "name=duration" line per finished phase.
+func (p *phaseTimer) Summary() []string {
+    lines := make([]string, 0, len(p.elapsed))
+    for i, d := range p.elapsed {
+        lines = append(lines, fmt.Sprintf("%s=%s", p.names[i], d.Round(time.Millisecond)))
+    }
+    return lines
+}
@@ -0,0 +1,55 @@
+// File: cmd/goscant/plan.go
+package main
+
+import (
+    "context"
+    "fmt"
+    "sync"
+
+    "goscant/internal/config"
+    "goscant/internal/control"
+    "goscant/internal/logger"
+    "goscant/internal/plan"
+    "goscant/internal/ratelimit"
+)
+
+// runPlan executes every job of a plan file stage by stage. Jobs within a
+// stage run concurrently; all jobs draw from the same rate limiter.
+func runPlan(ctx context.Context, base *config.Config, rawCapable bool, limiter *ratelimit.Limiter, ctl *control.Server, log *logger.Logger) error {
+    p, err := plan.Load(base.PlanFile)
+    if err != nil {
+        return err
+    }
+    if p.Rate > 0 {
+        limiter.SetRate(p.Rate)
+    }
+    for n, stage := range p.Stages() {
+        log.Info(fmt.Sprintf("plan: stage %d with %d job(s)", n+1, len(stage)))
+        wg := &sync.WaitGroup{}
+        errs := make([]error, len(stage))
+        for i, job := range stage {
+            cfg := job.Config(*base)
+            if err := requireRaw(cfg, rawCapable); err != nil {
+                return fmt.Errorf("job %q: %w", job.Name, err)
+            }
+            wg.Add(1)
+            go func(i int, name string) {
+                defer wg.Done()
+                log.Info("plan: starting job " + name)
+                if errs[i] = runScan(ctx, cfg, rawCapable, limiter, ctl, log); errs[i] == nil {
+                    log.Info("plan: finished job " + name)
+                }
+            }(i, job.Name)
+        }
+        wg.Wait()
+        for i, err := range errs {
+            if err != nil {
+                return fmt.Errorf("job %q: %w", stage[i].Name, err)
+            }
+        }
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+    }
+    return nil
+}
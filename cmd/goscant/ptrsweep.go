@@ -0,0 +1,93 @@
+// File: cmd/goscant/ptrsweep.go
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "net"
+    "os"
+    "os/signal"
+    "strings"
+    "sync/atomic"
+    "syscall"
+    "time"
+
+    "goscant/internal/input"
+    "goscant/internal/ratelimit"
+    "goscant/internal/scanner"
+    "goscant/internal/writer"
+)
+
+// runPTRSweep reverse-resolves every address of the given ranges, without
+// any port probing, and writes one row per name found (proto "ptr").
+func runPTRSweep(args []string) int {
+    fs := flag.NewFlagSet("ptr-sweep", flag.ExitOnError)
+    ips := fs.String("ip", "", "Addresses/CIDRs (comma separated) or a CSV file (required)")
+    workers := fs.Int("worker", 64, "Concurrent DNS lookups")
+    rate := fs.Int("rate", 0, "Maximum lookups per second (0 = unlimited)")
+    output := fs.String("output", "ptr.csv", "Output path")
+    format := fs.String("format", "csv", "Output format: "+strings.Join(writer.Formats, " or "))
+    all := fs.Bool("all", false, "Also write addresses without a PTR record (status CLOSED)")
+    fs.Parse(args)
+
+    if *ips == "" {
+        fmt.Println("ptr-sweep: --ip is required")
+        fs.Usage()
+        return 1
+    }
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    addrs, err := input.ParseIPs(ctx, *ips)
+    if err != nil {
+        fmt.Println("ptr-sweep:", err)
+        return 1
+    }
+    w, err := writer.NewFormat(*output, *format, []string{"ptr"})
+    if err != nil {
+        fmt.Println("ptr-sweep:", err)
+        return 1
+    }
+    go w.Run()
+
+    limiter := ratelimit.New(*rate)
+    var named, failed int64
+    ch := make(chan string, *workers)
+    go func() {
+        defer close(ch)
+        for _, ip := range addrs {
+            if limiter.Wait(ctx) != nil {
+                return
+            }
+            ch <- ip
+        }
+    }()
+    start := time.Now()
+    input.ReverseLookup(ctx, ch, *workers, func(ip string, names []string, err error) {
+        var dnsErr *net.DNSError
+        if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+            atomic.AddInt64(&failed, 1)
+        }
+        if len(names) == 0 {
+            if *all {
+                w.Submit(scanner.Result{IP: ip, Proto: "ptr", Engine: "ptr", Status: scanner.Closed, Time: time.Now(), Err: err})
+            }
+            return
+        }
+        atomic.AddInt64(&named, 1)
+        for _, n := range names {
+            r := scanner.Result{IP: ip, Proto: "ptr", Engine: "ptr", Status: scanner.Open, Time: time.Now()}
+            r.Meta = map[string]string{"ptr": strings.TrimSuffix(n, ".")}
+            w.Submit(r)
+        }
+    })
+    w.Close()
+    for _, line := range w.Summary() {
+        fmt.Println("ptr-sweep:", line)
+    }
+    fmt.Printf("ptr-sweep: %d of %d addresses have PTR records (%d lookup errors) in %s, written to %s\n",
+        named, len(addrs), failed, time.Since(start).Round(time.Millisecond), *output)
+    return 0
+}
@@ -0,0 +1,78 @@
+// File: cmd/goscant/shutdown.go
+package main
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "goscant/internal/checkpoint"
+    "goscant/internal/config"
+    "goscant/internal/input"
+    "goscant/internal/logger"
+    "goscant/internal/writer"
+)
+
+// An interrupted scan shuts down in four phases, each bounded by its own
+// --shutdown-* timeout so one stuck stage (a hung sink, a wedged worker)
+// cannot keep the later ones from running:
+//
+//   stop-producing    the producer stops queueing targets
+//   drain-workers     workers return from their in-flight probes
+//   flush-sinks       the writer drains its queue and closes every sink
+//   write-checkpoint  targets never probed are saved for --resume
+
+// shutdownPhase runs fn and waits up to d for it. If d elapses first it
+// warns and returns false, leaving fn running in the background.
+func shutdownPhase(log *logger.Logger, name string, d time.Duration, fn func()) bool {
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        fn()
+    }()
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+    case <-done:
+        return true
+    case <-t.C:
+        log.Warn(fmt.Sprintf("shutdown: %s did not finish within %s, moving on", name, d))
+        return false
+    }
+}
+
+// wait blocks until every worker has returned. On interrupt it runs the
+// stop-producing and drain-workers phases instead.
+func (r *scanRun) wait(ctx context.Context, produced <-chan struct{}, wg *sync.WaitGroup) {
+    workers := make(chan struct{})
+    go func() {
+        wg.Wait()
+        close(workers)
+    }()
+    select {
+    case <-workers:
+        return
+    case <-ctx.Done():
+    }
+    r.log.Info("interrupt received – shutting down")
+    shutdownPhase(r.log, "stop-producing", r.cfg.ShutdownProduce, func() { <-produced })
+    shutdownPhase(r.log, "drain-workers", r.cfg.ShutdownDrain, func() { <-workers })
+}
+
+// writeCheckpoint saves the targets left unscanned by an interrupted run.
+func writeCheckpoint(cfg *config.Config, remaining []input.ProbeTarget, log *logger.Logger) {
+    if len(remaining) == 0 {
+        return
+    }
+    output := cfg.OutputPath
+    if output == writer.Stdout {
+        output = "" // streamed results cannot be carried over
+    }
+    final, err := checkpoint.Save(remaining, output, cfg.EncryptKey)
+    if err != nil {
+        log.Warn("checkpoint failed: " + err.Error())
+        return
+    }
+    log.Info(fmt.Sprintf("checkpoint of %d targets saved to %s", len(remaining), final))
+}
@@ -0,0 +1,96 @@
+// File: cmd/goscant/stateless.go
+package main
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/fragile"
+    "goscant/internal/input"
+    "goscant/internal/logger"
+    "goscant/internal/prober"
+    "goscant/internal/ratelimit"
+    "goscant/internal/scanner"
+    "goscant/internal/writer"
+)
+
+// runStateless sweeps targets with cookie-bearing SYNs and records whichever
+// replies validate. Senders never wait for replies, so throughput is bounded
+// only by --rate and the NIC.
+func runStateless(ctx context.Context, cfg *config.Config, targets *input.Targets, w *writer.CSVWriter, enrichers []prober.Enricher, limiter *ratelimit.Limiter, guard *fragile.Guard, log *logger.Logger) error {
+    sweep, err := scanner.NewStateless(cfg, func(r scanner.Result) {
+        r.Time, r.Attempts = time.Now(), 1
+        prober.Enrich(&r, enrichers, guard)
+        w.Submit(r)
+    })
+    if err != nil {
+        return err
+    }
+    defer sweep.Close()
+    if cfg.Retries > 0 {
+        log.Warn("--retries has no effect in stateless mode: silence is never recorded")
+    }
+
+    var sent, failed int64
+    taskCh := make(chan input.ProbeTarget, cfg.QueueSize)
+    wg := &sync.WaitGroup{}
+    for i := 0; i < cfg.NumWorkers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for t := range taskCh {
+                if limiter.Wait(ctx) != nil {
+                    return
+                }
+                if err := sendGuarded(ctx, sweep, guard, t); err != nil {
+                    if ctx.Err() != nil {
+                        return
+                    }
+                    atomic.AddInt64(&failed, 1)
+                    log.Debugf("stateless send %s:%d: %v", t.IP, t.Port, err)
+                    continue
+                }
+                atomic.AddInt64(&sent, 1)
+            }
+        }()
+    }
+produce:
+    for it := targets.Iter(0); ; {
+        t, ok := it.Next()
+        if !ok {
+            break
+        }
+        select {
+        case taskCh <- t:
+        case <-ctx.Done():
+            break produce
+        }
+    }
+    close(taskCh)
+    wg.Wait()
+
+    log.Info(fmt.Sprintf("stateless: %d SYNs sent (%d failed), collecting replies for %s", sent, failed, cfg.StatelessWait))
+    select {
+    case <-time.After(cfg.StatelessWait):
+    case <-ctx.Done():
+    }
+    return nil
+}
+
+// sendGuarded sends one SYN, spacing probes of fragile hosts. A SYN carries
+// no payload, so the stateless engine itself is safe for them.
+func sendGuarded(ctx context.Context, sweep *scanner.Stateless, guard *fragile.Guard, t input.ProbeTarget) error {
+    if !guard.Fragile(t.IP) {
+        return sweep.Send(t.IP, t.Port)
+    }
+    release, err := guard.Acquire(ctx, t.IP)
+    if err != nil {
+        return err
+    }
+    defer release()
+    return sweep.Send(t.IP, t.Port)
+}
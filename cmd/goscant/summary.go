@@ -0,0 +1,66 @@
+// File: cmd/goscant/summary.go
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/logger"
+    "goscant/internal/scanner"
+    "goscant/internal/seal"
+    "goscant/internal/writer"
+)
+
+// scanSummary is the end-of-scan report logged and saved next to the
+// output as <output>.summary.json.
+type scanSummary struct {
+    Targets      int     `json:"targets"`
+    DurationSec  float64 `json:"duration_sec"`
+    ProbesPerSec float64 `json:"probes_per_sec"`
+    writer.RunStats
+}
+
+// summaryPath is where the summary of a run writing output is saved, or ""
+// when results stream to stdout.
+func summaryPath(output string) string {
+    if output == writer.Stdout {
+        return ""
+    }
+    return strings.TrimSuffix(output, filepath.Ext(output)) + ".summary.json"
+}
+
+// reportSummary logs the statistics of a finished scan and saves them.
+// elapsed is the probing time, so setup does not dilute the probe rate.
+func reportSummary(cfg *config.Config, targets int, elapsed time.Duration, st writer.RunStats, log *logger.Logger) {
+    s := scanSummary{Targets: targets, DurationSec: elapsed.Seconds(), RunStats: st}
+    if elapsed > 0 {
+        s.ProbesPerSec = float64(st.Probes) / elapsed.Seconds()
+    }
+    counts := []string{}
+    for _, status := range []scanner.Status{scanner.Open, scanner.Closed, scanner.Filtered, scanner.Error, scanner.HostDown} {
+        if n := st.ByStatus[status.String()]; n > 0 {
+            counts = append(counts, fmt.Sprintf("%s=%d", status, n))
+        }
+    }
+    log.Info(fmt.Sprintf("summary: %d targets, %d results (%s) in %s, %.0f probes/s",
+        targets, st.Results, strings.Join(counts, " "), elapsed.Round(time.Millisecond), s.ProbesPerSec))
+    log.Info(fmt.Sprintf("summary: latency p50=%dms p95=%dms p99=%dms", st.LatencyP50, st.LatencyP95, st.LatencyP99))
+
+    path := summaryPath(cfg.OutputPath)
+    if path == "" {
+        return
+    }
+    b, err := json.MarshalIndent(s, "", "  ")
+    if err != nil {
+        return
+    }
+    if err := seal.WriteFile(path, append(b, '\n'), cfg.EncryptKey); err != nil {
+        log.Warn("summary: cannot save " + path + ": " + err.Error())
+        return
+    }
+    log.Info("summary: saved to " + path)
+}
@@ -0,0 +1,299 @@
+// File: cmd/goscant/support.go
+package main
+
+import (
+    "archive/tar"
+    "bytes"
+    "compress/gzip"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "regexp"
+    "runtime"
+    "runtime/debug"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/google/gopacket/pcap"
+
+    "goscant/internal/scanner"
+)
+
+const (
+    supportLogFiles = 3         // most recent log files included
+    supportLogTail  = 256 << 10 // bytes kept from the end of each log
+)
+
+// Target addresses and names are replaced before anything leaves the machine.
+var (
+    ipv4Pattern     = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+    ipv6Pattern     = regexp.MustCompile(`\b(?:[0-9A-Fa-f]{1,4}:){2,7}(?::|[0-9A-Fa-f]{1,4})\b|::(?:[0-9A-Fa-f]{1,4}:){0,6}[0-9A-Fa-f]{1,4}\b`)
+    userHostPattern = regexp.MustCompile(`\b[\w.-]+@[\w.-]+`)
+    tokenPattern    = regexp.MustCompile(`[\w.-]+`)
+    nameToken       = regexp.MustCompile(`^[\w.-]+$`)
+    hostPattern     = regexp.MustCompile(`\b(?:[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)+[A-Za-z][A-Za-z0-9-]{0,62}\b`)
+)
+
+// fileExts are final labels that make a dotted name a file rather than a
+// host, so paths in log lines survive redaction.
+var fileExts = map[string]bool{
+    "csv": true, "json": true, "jsonl": true, "ndjson": true, "xml": true, "gnmap": true, "sqlite": true, "db": true,
+    "md": true, "log": true, "txt": true, "gz": true, "enc": true, "tmp": true, "sock": true, "yaml": true, "yml": true,
+}
+
+// targetFlags are scan flags whose values name hosts, networks, files of
+// them or credentials. parseFlags asks new such flags to be added here.
+var targetFlags = map[string]bool{
+    "ip": true, "exclude": true, "exclude-file": true, "via": true, "trace-target": true,
+    "rescan": true, "masscan": true, "plan": true, "assets": true, "owners": true, "fragile": true, "triage": true,
+    "k8s-api": true, "k8s-namespace": true, "k8s-selector": true,
+    "consul-addr": true, "consul-service": true, "aws-filter": true,
+    "snmp-communities": true,
+}
+
+// runSupportBundle gathers build, capability, environment and log details
+// into one tar.gz for bug reports. Arguments after "--" are the scan command
+// line being reported; target values in it are redacted.
+func runSupportBundle(args []string) int {
+    fs := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+    out := fs.String("output", "goscant-support-"+time.Now().Format("20060102-150405")+".tar.gz", "Archive to write")
+    logDir := fs.String("logs", ".", "Directory holding portRunner-*.log files")
+    raw := fs.Bool("no-redact", false, "Keep addresses and host names in logs and the scan command line")
+    fs.Parse(args)
+
+    redact := redactor(fs.Args())
+    if *raw {
+        redact = func(s string) string { return s }
+    }
+
+    files := map[string][]byte{
+        "build.txt":        []byte(buildReport()),
+        "capabilities.txt": []byte(capabilityReport()),
+        "environment.txt":  []byte(environmentReport()),
+        "command.txt":      []byte(redact(commandReport(fs.Args(), *raw)) + "\n"),
+    }
+    logs, _ := filepath.Glob(filepath.Join(*logDir, "portRunner-*.log"))
+    sort.Strings(logs) // names carry the date, so this is oldest first
+    if len(logs) > supportLogFiles {
+        logs = logs[len(logs)-supportLogFiles:]
+    }
+    for _, p := range logs {
+        b, err := tailFile(p, supportLogTail)
+        if err != nil {
+            fmt.Println("support-bundle: skipping", p+":", err)
+            continue
+        }
+        files["logs/"+filepath.Base(p)] = []byte(redact(string(b)))
+    }
+
+    if err := writeBundle(*out, files); err != nil {
+        fmt.Println("support-bundle:", err)
+        return 1
+    }
+    fmt.Println("support bundle written to", *out)
+    if *raw {
+        fmt.Println("warning: addresses were not redacted")
+    }
+    return 0
+}
+
+func buildReport() string {
+    b := &strings.Builder{}
+    fmt.Fprintf(b, "go=%s os=%s arch=%s cpus=%d\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+    if info, ok := debug.ReadBuildInfo(); ok {
+        fmt.Fprintf(b, "module=%s version=%s\n", info.Main.Path, info.Main.Version)
+        for _, s := range info.Settings {
+            if strings.HasPrefix(s.Key, "vcs.") || s.Key == "CGO_ENABLED" || s.Key == "-tags" {
+                fmt.Fprintf(b, "%s=%s\n", s.Key, s.Value)
+            }
+        }
+        for _, d := range info.Deps {
+            fmt.Fprintf(b, "dep %s %s\n", d.Path, d.Version)
+        }
+    }
+    return b.String()
+}
+
+func capabilityReport() string {
+    b := &strings.Builder{}
+    fmt.Fprintf(b, "euid=%d\n", os.Geteuid())
+    fmt.Fprintf(b, "raw_socket=%v\n", scanner.CheckRawSocketCapability())
+    fmt.Fprintf(b, "pcap=%s\n", pcap.Version())
+    devs, err := pcap.FindAllDevs()
+    if err != nil {
+        fmt.Fprintf(b, "pcap_devices_error=%v\n", err)
+    }
+    for _, d := range devs {
+        fmt.Fprintf(b, "pcap_device %s addresses=%d\n", d.Name, len(d.Addresses))
+    }
+    for _, p := range []string{"/proc/version", "/etc/os-release", "/proc/sys/net/ipv4/ip_local_port_range"} {
+        if data, err := os.ReadFile(p); err == nil {
+            fmt.Fprintf(b, "--- %s\n%s", p, data)
+        }
+    }
+    return b.String()
+}
+
+// environmentReport lists goscant-related variables; values of anything
+// that looks like a secret are withheld.
+func environmentReport() string {
+    lines := []string{}
+    for _, kv := range os.Environ() {
+        k, v, _ := strings.Cut(kv, "=")
+        if !strings.HasPrefix(k, "GOSCANT_") && k != "GODEBUG" && k != "GOMAXPROCS" {
+            continue
+        }
+        if strings.Contains(k, "KEY") || strings.Contains(k, "TOKEN") || strings.Contains(k, "SECRET") {
+            v = "<set>"
+        }
+        lines = append(lines, k+"="+v)
+    }
+    sort.Strings(lines)
+    return strings.Join(lines, "\n") + "\n"
+}
+
+// redactor returns a function hiding targets in bundle text: the values
+// given to targetFlags in the reported command line (which also covers
+// single-label host names), user@host pairs, dotted host names and IP
+// literals.
+func redactor(args []string) func(string) string {
+    var values []string
+    for i := 0; i < len(args); i++ {
+        name, val, hasVal := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+        if !targetFlags[name] {
+            continue
+        }
+        if !hasVal {
+            if i+1 >= len(args) {
+                continue
+            }
+            i++
+            val = args[i]
+        }
+        for _, v := range strings.Split(val, ",") {
+            if v = strings.TrimSpace(v); len(v) > 2 {
+                values = append(values, v)
+            }
+        }
+    }
+    // Names are matched as whole tokens, so "web1" spares "web10"; values
+    // like CIDRs and URLs are specific enough to replace anywhere
+    names := map[string]bool{}
+    var pairs []string
+    sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) }) // longest first
+    for _, v := range values {
+        if nameToken.MatchString(v) {
+            names[v] = true
+        } else {
+            pairs = append(pairs, v, "<redacted>")
+        }
+    }
+    literals := strings.NewReplacer(pairs...)
+    return func(s string) string {
+        s = literals.Replace(s)
+        s = tokenPattern.ReplaceAllStringFunc(s, func(tok string) string {
+            if names[tok] {
+                return "<redacted>"
+            }
+            return tok
+        })
+        s = userHostPattern.ReplaceAllString(s, "<user>@<host>")
+        s = ipv4Pattern.ReplaceAllString(s, "x.x.x.x")
+        s = ipv6Pattern.ReplaceAllString(s, "x:x::x")
+        return hostPattern.ReplaceAllStringFunc(s, func(name string) string {
+            if name == "x.x.x.x" || fileExts[strings.ToLower(name[strings.LastIndexByte(name, '.')+1:])] {
+                return name
+            }
+            return "<host>"
+        })
+    }
+}
+
+// commandReport renders the reported scan command line, hiding target values.
+func commandReport(args []string, raw bool) string {
+    if raw {
+        return strings.Join(args, " ")
+    }
+    return strings.Join(redactArgs(args), " ")
+}
+
+// redactArgs returns a copy of args with the values of targetFlags hidden.
+func redactArgs(args []string) []string {
+    out := make([]string, len(args))
+    copy(out, args)
+    for i := 0; i < len(out); i++ {
+        name, val, hasVal := strings.Cut(strings.TrimLeft(out[i], "-"), "=")
+        if !targetFlags[name] {
+            continue
+        }
+        if hasVal {
+            out[i] = out[i][:len(out[i])-len(val)] + "<redacted>"
+        } else if i+1 < len(out) {
+            out[i+1] = "<redacted>"
+            i++
+        }
+    }
+    return out
+}
+
+// anonymizedCommand is the command line recorded in outputs written with
+// --anonymize: target values, addresses and host names are redacted as
+// in support bundles.
+func anonymizedCommand(args []string) []string {
+    redact := redactor(args)
+    out := redactArgs(args)
+    for i, a := range out {
+        out[i] = redact(a)
+    }
+    return out
+}
+
+// tailFile returns at most n bytes from the end of path.
+func tailFile(path string, n int64) ([]byte, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    st, err := f.Stat()
+    if err != nil {
+        return nil, err
+    }
+    if st.Size() > n {
+        if _, err := f.Seek(-n, io.SeekEnd); err != nil {
+            return nil, err
+        }
+    }
+    return io.ReadAll(f)
+}
+
+func writeBundle(path string, files map[string][]byte) error {
+    buf := &bytes.Buffer{}
+    gz := gzip.NewWriter(buf)
+    tw := tar.NewWriter(gz)
+    names := make([]string, 0, len(files))
+    for name := range files {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    now := time.Now()
+    for _, name := range names {
+        hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(files[name])), ModTime: now}
+        if err := tw.WriteHeader(hdr); err != nil {
+            return err
+        }
+        if _, err := tw.Write(files[name]); err != nil {
+            return err
+        }
+    }
+    if err := tw.Close(); err != nil {
+        return err
+    }
+    if err := gz.Close(); err != nil {
+        return err
+    }
+    return os.WriteFile(path, buf.Bytes(), 0600)
+}
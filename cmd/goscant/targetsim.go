@@ -0,0 +1,198 @@
+// File: cmd/goscant/targetsim.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "net"
+    "os"
+    "os/signal"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+
+    "goscant/internal/input"
+)
+
+// simProfile describes which services a simulated target exposes.
+type simProfile struct {
+    open, udp, slow, tarpit string // port lists in --port syntax
+}
+
+var simProfiles = map[string]simProfile{
+    "mixed":  {open: "20000-20009", udp: "20100-20101", slow: "20200-20201", tarpit: "20300-20301"},
+    "open":   {open: "20000-20999"},
+    "tarpit": {tarpit: "20300-20399"},
+}
+
+// runTargetSim serves simulated services on loopback (by default) so scan
+// settings can be rehearsed and throughput measured without touching real
+// hosts. Ports not listed stay closed.
+func runTargetSim(args []string) int {
+    fs := flag.NewFlagSet("target-sim", flag.ExitOnError)
+    profile := fs.String("listen-profile", "mixed", "Preset port layout: mixed, open or tarpit")
+    addr := fs.String("listen", "127.0.0.1", "Address to bind")
+    open := fs.String("open", "", "TCP ports that send a banner and close (overrides the profile)")
+    udp := fs.String("udp", "", "UDP echo ports (overrides the profile)")
+    slow := fs.String("slow", "", "TCP ports that answer only after --slow-delay (overrides the profile)")
+    tarpit := fs.String("tarpit", "", "TCP ports that accept and then stall (overrides the profile)")
+    banner := fs.String("banner", "SSH-2.0-goscant-sim port={port}\r\n", "Banner template; {port} is replaced")
+    slowDelay := fs.Duration("slow-delay", 2*time.Second, "Delay before slow ports answer")
+    fs.Parse(args)
+
+    p, ok := simProfiles[*profile]
+    if !ok {
+        fmt.Println("target-sim: unknown profile", *profile)
+        return 1
+    }
+    override := func(v string, dst *string) {
+        if v != "" {
+            *dst = v
+        }
+    }
+    override(*open, &p.open)
+    override(*udp, &p.udp)
+    override(*slow, &p.slow)
+    override(*tarpit, &p.tarpit)
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    sim := &targetSim{addr: *addr, banner: *banner, slowDelay: *slowDelay}
+    for _, svc := range []struct {
+        kind  string
+        ports string
+        serve func(context.Context, int) error
+    }{
+        {"open", p.open, sim.serveOpen},
+        {"udp", p.udp, sim.serveUDP},
+        {"slow", p.slow, sim.serveSlow},
+        {"tarpit", p.tarpit, sim.serveTarpit},
+    } {
+        if svc.ports == "" {
+            continue
+        }
+        ports, err := input.ParsePorts(svc.ports)
+        if err != nil {
+            fmt.Println("target-sim:", err)
+            return 1
+        }
+        for _, port := range ports {
+            if err := svc.serve(ctx, port); err != nil {
+                fmt.Println("target-sim:", err)
+                stop()
+                sim.wg.Wait()
+                return 1
+            }
+        }
+        fmt.Printf("target-sim: %d %s port(s) on %s: %s\n", len(ports), svc.kind, *addr, svc.ports)
+    }
+    fmt.Println("target-sim: running, Ctrl-C to stop")
+    <-ctx.Done()
+    sim.wg.Wait()
+    return 0
+}
+
+type targetSim struct {
+    addr      string
+    banner    string
+    slowDelay time.Duration
+    wg        sync.WaitGroup
+}
+
+func (s *targetSim) bannerFor(port int) []byte {
+    return []byte(strings.ReplaceAll(s.banner, "{port}", strconv.Itoa(port)))
+}
+
+// listenTCP accepts on port until ctx ends, passing each connection to handle.
+func (s *targetSim) listenTCP(ctx context.Context, port int, handle func(net.Conn)) error {
+    l, err := net.Listen("tcp", net.JoinHostPort(s.addr, strconv.Itoa(port)))
+    if err != nil {
+        return err
+    }
+    s.wg.Add(1)
+    go func() {
+        defer s.wg.Done()
+        <-ctx.Done()
+        l.Close()
+    }()
+    s.wg.Add(1)
+    go func() {
+        defer s.wg.Done()
+        for {
+            c, err := l.Accept()
+            if err != nil {
+                return
+            }
+            go func() {
+                defer c.Close()
+                handle(c)
+            }()
+        }
+    }()
+    return nil
+}
+
+func (s *targetSim) serveOpen(ctx context.Context, port int) error {
+    return s.listenTCP(ctx, port, func(c net.Conn) {
+        c.Write(s.bannerFor(port))
+    })
+}
+
+func (s *targetSim) serveSlow(ctx context.Context, port int) error {
+    return s.listenTCP(ctx, port, func(c net.Conn) {
+        select {
+        case <-time.After(s.slowDelay):
+            c.Write(s.bannerFor(port))
+        case <-ctx.Done():
+        }
+    })
+}
+
+// serveTarpit holds connections open, trickling a byte every ten seconds
+// so clients without read deadlines hang.
+func (s *targetSim) serveTarpit(ctx context.Context, port int) error {
+    return s.listenTCP(ctx, port, func(c net.Conn) {
+        t := time.NewTicker(10 * time.Second)
+        defer t.Stop()
+        for {
+            select {
+            case <-t.C:
+                if _, err := c.Write([]byte{'\n'}); err != nil {
+                    return
+                }
+            case <-ctx.Done():
+                return
+            }
+        }
+    })
+}
+
+func (s *targetSim) serveUDP(ctx context.Context, port int) error {
+    pc, err := net.ListenPacket("udp", net.JoinHostPort(s.addr, strconv.Itoa(port)))
+    if err != nil {
+        return err
+    }
+    s.wg.Add(1)
+    go func() {
+        defer s.wg.Done()
+        <-ctx.Done()
+        pc.Close()
+    }()
+    s.wg.Add(1)
+    go func() {
+        defer s.wg.Done()
+        buf := make([]byte, 65535)
+        for {
+            n, from, err := pc.ReadFrom(buf)
+            if err != nil {
+                return
+            }
+            pc.WriteTo(buf[:n], from)
+        }
+    }()
+    return nil
+}
@@ -0,0 +1,174 @@
+// File: cmd/goscant/verify.go
+package main
+
+import (
+    "context"
+    "encoding/csv"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "os/signal"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/logger"
+    "goscant/internal/models"
+    "goscant/internal/results"
+    "goscant/internal/scanner"
+)
+
+// verifyCheck holds the outcome of re-probing one earlier result.
+type verifyCheck struct {
+    prev     scanner.Result
+    observed []scanner.Status
+}
+
+// verdict compares the observed rounds against the earlier status.
+func (c verifyCheck) verdict() string {
+    if len(c.observed) == 0 {
+        return "SKIPPED"
+    }
+    for _, st := range c.observed[1:] {
+        if st != c.observed[0] {
+            return "FLAPPING"
+        }
+    }
+    if c.observed[0] != c.prev.Status {
+        return "CHANGED"
+    }
+    return "CONFIRMED"
+}
+
+// runVerify re-probes selected rows of an earlier results CSV several times
+// and writes a confirmation report that flags ports whose state flaps.
+func runVerify(args []string) int {
+    cfg := &config.Config{}
+    fs := flag.NewFlagSet("verify", flag.ExitOnError)
+    from := fs.String("from", "", "Results CSV from an earlier run (required)")
+    status := fs.String("status", "open", "Comma-separated statuses to re-probe (open, closed, filtered, error)")
+    rounds := fs.Int("rounds", 3, "Probes per target; differing outcomes are reported as FLAPPING")
+    fs.IntVar(&cfg.NumWorkers, "worker", 1, "Number of concurrent workers")
+    fs.DurationVar(&cfg.Timeout, "timeout", 500*time.Millisecond, "Probe timeout")
+    fs.DurationVar(&cfg.Delay, "delay", 100*time.Millisecond, "Delay between rounds against the same target")
+    fs.StringVar(&cfg.OutputPath, "output", "verify.csv", "Confirmation report path")
+    fs.Parse(args)
+
+    if *from == "" {
+        fmt.Println("verify: --from is required")
+        fs.Usage()
+        return 1
+    }
+    if *rounds < 1 {
+        *rounds = 1
+    }
+    want := map[scanner.Status]bool{}
+    for _, s := range strings.Split(*status, ",") {
+        st, ok := scanner.ParseStatus(s)
+        if !ok {
+            fmt.Printf("verify: unknown status %q\n", s)
+            return 1
+        }
+        want[st] = true
+    }
+
+    cfg.LogPath = defaultLogPath()
+    log := logger.New(cfg.LogPath)
+
+    prev, err := results.Read(*from)
+    if err != nil {
+        log.Fatal(err)
+    }
+    checks := []verifyCheck{}
+    for _, r := range prev {
+        if want[r.Status] {
+            checks = append(checks, verifyCheck{prev: r})
+        }
+    }
+    log.Info(fmt.Sprintf("verify: re-probing %d of %d rows from %s", len(checks), len(prev), *from))
+
+    rawCapable := scanner.CheckRawSocketCapability()
+    engines := map[string]scanner.Scanner{}
+    for _, c := range checks {
+        if _, ok := engines[c.prev.Proto]; ok {
+            continue
+        }
+        pc := *cfg
+        pc.ScanType = c.prev.Proto
+        if pc.ScanType == "ip" {
+            pc.ScanType = "ipproto"
+        }
+        engines[c.prev.Proto] = scanner.NewFactory(&pc, rawCapable)
+        if cl, ok := engines[c.prev.Proto].(io.Closer); ok {
+            defer cl.Close()
+        }
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    idx := make(chan int)
+    wg := &sync.WaitGroup{}
+    for i := 0; i < cfg.NumWorkers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for n := range idx {
+                c := &checks[n]
+                for round := 0; round < *rounds && ctx.Err() == nil; round++ {
+                    if round > 0 {
+                        time.Sleep(cfg.Delay)
+                    }
+                    res := engines[c.prev.Proto].Scan(ctx, models.ScanTarget{IP: c.prev.IP, Port: c.prev.Port})
+                    c.observed = append(c.observed, res.Status)
+                }
+            }
+        }()
+    }
+    for n := range checks {
+        if ctx.Err() != nil {
+            break
+        }
+        idx <- n
+    }
+    close(idx)
+    wg.Wait()
+
+    if err := writeVerifyReport(cfg.OutputPath, checks); err != nil {
+        log.Fatal(err)
+    }
+    counts := map[string]int{}
+    for _, c := range checks {
+        counts[c.verdict()]++
+    }
+    log.Info(fmt.Sprintf("verify: confirmed=%d changed=%d flapping=%d skipped=%d, report at %s",
+        counts["CONFIRMED"], counts["CHANGED"], counts["FLAPPING"], counts["SKIPPED"], cfg.OutputPath))
+    return 0
+}
+
+func writeVerifyReport(path string, checks []verifyCheck) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+    w := csv.NewWriter(f)
+    w.Write([]string{"dst_ip", "dst_port", "proto", "previous_status", "current_status", "verdict", "rounds"})
+    for _, c := range checks {
+        current, rounds := "", make([]string, len(c.observed))
+        for i, st := range c.observed {
+            rounds[i] = st.String()
+        }
+        if len(c.observed) > 0 {
+            current = c.observed[len(c.observed)-1].String()
+        }
+        w.Write([]string{c.prev.IP, strconv.Itoa(c.prev.Port), c.prev.Proto, c.prev.Status.String(),
+            current, c.verdict(), strings.Join(rounds, "|")})
+    }
+    w.Flush()
+    return w.Error()
+}
@@ -0,0 +1,141 @@
+// File: filter/filter.go
+
+// Package filter lets programs embedding goscant shape the result stream
+// before it reaches the output. Filters run on the writer goroutine, one
+// result at a time, so implementations need no locking.
+package filter
+
+import (
+    "fmt"
+    "net"
+    "strconv"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// Result and Status are the scanner's own types, re-exported for embedders.
+type (
+    Result     = scanner.Result
+    Status     = scanner.Status
+    Confidence = scanner.Confidence
+)
+
+const (
+    Open     = scanner.Open
+    Closed   = scanner.Closed
+    Filtered = scanner.Filtered
+    Error    = scanner.Error
+    HostDown = scanner.HostDown
+
+    Low    = scanner.Low
+    Medium = scanner.Medium
+    High   = scanner.High
+)
+
+// ResultFilter decides whether a result is written.
+type ResultFilter interface {
+    Keep(r Result) bool
+}
+
+// Func adapts a plain predicate to ResultFilter.
+type Func func(r Result) bool
+
+func (f Func) Keep(r Result) bool { return f(r) }
+
+// Chain keeps a result only if every filter keeps it, evaluated in order.
+func Chain(r Result, filters []ResultFilter) bool {
+    for _, f := range filters {
+        if !f.Keep(r) {
+            return false
+        }
+    }
+    return true
+}
+
+// OpenOnly keeps OPEN results.
+func OpenOnly() ResultFilter {
+    return Func(func(r Result) bool { return r.Status == Open })
+}
+
+// MinConfidence keeps results rated at least min.
+func MinConfidence(min Confidence) ResultFilter {
+    return Func(func(r Result) bool { return r.Confidence >= min })
+}
+
+func targetKey(r Result) string {
+    return r.Proto + "/" + net.JoinHostPort(r.IP, strconv.Itoa(r.Port))
+}
+
+// Dedupe drops a result whose target and status were already written.
+func Dedupe() ResultFilter {
+    seen := map[string]bool{}
+    return Func(func(r Result) bool {
+        k := targetKey(r) + "/" + r.Status.String()
+        if seen[k] {
+            return false
+        }
+        seen[k] = true
+        return true
+    })
+}
+
+// RateOfChange keeps a target's first result and then only results whose
+// status differs from the last one kept. A target that changes more than
+// max times within window is treated as flapping and muted until it calms.
+func RateOfChange(max int, window time.Duration) ResultFilter {
+    last := map[string]Status{}
+    changes := map[string][]time.Time{}
+    return Func(func(r Result) bool {
+        k := targetKey(r)
+        prev, ok := last[k]
+        if ok && prev == r.Status {
+            return false
+        }
+        at := r.Time
+        if at.IsZero() {
+            at = time.Now()
+        }
+        recent := changes[k][:0]
+        for _, t := range changes[k] {
+            if at.Sub(t) < window {
+                recent = append(recent, t)
+            }
+        }
+        changes[k] = append(recent, at)
+        if ok && len(changes[k]) > max {
+            return false
+        }
+        last[k] = r.Status
+        return true
+    })
+}
+
+// Parse builds the built-in filters named in a comma-separated list:
+// "open", "dedupe", "changes" (RateOfChange with 3 changes per 10m) and
+// "confidence=low|medium|high" (MinConfidence).
+func Parse(list string) ([]ResultFilter, error) {
+    var out []ResultFilter
+    for _, name := range strings.Split(list, ",") {
+        name, arg, _ := strings.Cut(strings.TrimSpace(name), "=")
+        switch name {
+        case "":
+        case "open":
+            out = append(out, OpenOnly())
+        case "dedupe":
+            out = append(out, Dedupe())
+        case "changes":
+            out = append(out, RateOfChange(3, 10*time.Minute))
+        case "confidence":
+            min, ok := scanner.ParseConfidence(arg)
+            if !ok {
+                return nil, fmt.Errorf("filter confidence: want low, medium or high, got %q", arg)
+            }
+            out = append(out, MinConfidence(min))
+        default:
+            return nil, fmt.Errorf("unknown filter %q (want open, dedupe, changes or confidence=LEVEL)", name)
+        }
+    }
+    return out, nil
+}
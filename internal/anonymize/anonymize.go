@@ -0,0 +1,155 @@
+// File: internal/anonymize/anonymize.go
+package anonymize
+
+import (
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "net"
+    "os"
+    "strings"
+    "sync"
+
+    "goscant/internal/scanner"
+)
+
+// KeyEnv names the environment variable holding the pseudonymization secret.
+const KeyEnv = "GOSCANT_ANON_KEY"
+
+// hostFields are annotation keys that carry host identities.
+var hostFields = []string{"hostname", "target_host", "ptr", "asset"}
+
+// Certificate names: subject and issuer DNs are replaced whole, SANs one at
+// a time, so a SAN naming the scanned address matches its ip pseudonym.
+var (
+    certFields = []string{"cert_subject", "cert_issuer"}
+    sanFields  = []string{"cert_sans"}
+)
+
+// Anonymizer replaces IPs and hostnames with keyed HMAC pseudonyms. The same
+// secret always yields the same pseudonym, so datasets stay joinable.
+type Anonymizer struct {
+    secret []byte
+
+    mu      sync.Mutex
+    mapping map[string]string // pseudonym -> real value
+}
+
+// FromEnv builds an Anonymizer from the secret in KeyEnv.
+func FromEnv() (*Anonymizer, error) {
+    secret := os.Getenv(KeyEnv)
+    if len(secret) < 16 {
+        return nil, errors.New("--anonymize requires " + KeyEnv + " to hold a secret of at least 16 bytes")
+    }
+    return &Anonymizer{secret: []byte(secret), mapping: map[string]string{}}, nil
+}
+
+func (a *Anonymizer) mac(label, v string) []byte {
+    m := hmac.New(sha256.New, a.secret)
+    m.Write([]byte(label))
+    m.Write([]byte{0})
+    m.Write([]byte(v))
+    return m.Sum(nil)
+}
+
+// Pseudonym returns the stable pseudonym for v and records the mapping.
+func (a *Anonymizer) Pseudonym(kind, v string) string {
+    if v == "" {
+        return ""
+    }
+    p := kind + "-" + hex.EncodeToString(a.mac(kind, v)[:6])
+    a.mu.Lock()
+    a.mapping[p] = v
+    a.mu.Unlock()
+    return p
+}
+
+// Enrich pseudonymizes r in place; it must run after every other enricher.
+// Raw banners cannot be pseudonymized and are dropped.
+func (a *Anonymizer) Enrich(r *scanner.Result) {
+    r.IP = a.Pseudonym("ip", r.IP)
+    r.Banner = "" // free text; --service-detect keeps the reply it matched
+    for _, k := range hostFields {
+        if v, ok := r.Meta[k]; ok {
+            r.Meta[k] = a.Pseudonym("host", v)
+        }
+    }
+    for _, k := range certFields {
+        if v, ok := r.Meta[k]; ok {
+            r.Meta[k] = a.Pseudonym("cert", v)
+        }
+    }
+    for _, k := range sanFields {
+        v, ok := r.Meta[k]
+        if !ok || v == "" {
+            continue
+        }
+        sans := strings.Split(v, ";")
+        for i, san := range sans {
+            if net.ParseIP(san) != nil {
+                sans[i] = a.Pseudonym("ip", san)
+            } else {
+                sans[i] = a.Pseudonym("host", san)
+            }
+        }
+        r.Meta[k] = strings.Join(sans, ";")
+    }
+}
+
+// mapKey derives the mapping file encryption key from the secret.
+func (a *Anonymizer) mapKey() []byte {
+    return a.mac("mapping-file", "")
+}
+
+// WriteMapping stores the pseudonym -> real value table AES-GCM encrypted.
+func (a *Anonymizer) WriteMapping(path string) error {
+    a.mu.Lock()
+    plain, err := json.Marshal(a.mapping)
+    a.mu.Unlock()
+    if err != nil {
+        return err
+    }
+    gcm, err := newGCM(a.mapKey())
+    if err != nil {
+        return err
+    }
+    nonce := make([]byte, gcm.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return err
+    }
+    return os.WriteFile(path, gcm.Seal(nonce, nonce, plain, nil), 0600)
+}
+
+// ReadMapping decrypts a file written by WriteMapping.
+func (a *Anonymizer) ReadMapping(path string) (map[string]string, error) {
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    gcm, err := newGCM(a.mapKey())
+    if err != nil {
+        return nil, err
+    }
+    if len(b) < gcm.NonceSize() {
+        return nil, errors.New(path + ": truncated mapping file")
+    }
+    plain, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+    if err != nil {
+        return nil, errors.New(path + ": cannot decrypt mapping (wrong key?)")
+    }
+    m := map[string]string{}
+    return m, json.Unmarshal(plain, &m)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
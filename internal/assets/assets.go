@@ -0,0 +1,171 @@
+// File: internal/assets/assets.go
+package assets
+
+import (
+    "context"
+    "encoding/csv"
+    "fmt"
+    "net"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+
+    "goscant/internal/scanner"
+)
+
+// Columns lists the result fields filled in by a Map.
+var Columns = []string{"asset"}
+
+// Map groups addresses into named logical assets.
+type Map struct {
+    byIP map[string]string
+}
+
+// Load reads a CSV file with rows of asset,address[,address...]. Addresses
+// may be IPs or hostnames; hostnames are resolved now and every address
+// they resolve to joins the asset. A leading header row and '#' comments
+// are ignored.
+func Load(ctx context.Context, path string) (*Map, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    r := csv.NewReader(f)
+    r.Comment = '#'
+    r.FieldsPerRecord = -1
+    recs, err := r.ReadAll()
+    if err != nil {
+        return nil, err
+    }
+
+    m := &Map{byIP: map[string]string{}}
+    for i, rec := range recs {
+        name := strings.TrimSpace(rec[0])
+        if i == 0 && strings.EqualFold(name, "asset") {
+            continue
+        }
+        if name == "" || len(rec) < 2 {
+            return nil, fmt.Errorf("%s line %d: want asset,address[,address...]", path, i+1)
+        }
+        for _, a := range rec[1:] {
+            a = strings.TrimSpace(a)
+            if a == "" {
+                continue
+            }
+            addrs := []string{a}
+            if ip := net.ParseIP(a); ip != nil {
+                addrs = []string{ip.String()}
+            } else if addrs, err = net.DefaultResolver.LookupHost(ctx, a); err != nil {
+                return nil, fmt.Errorf("%s line %d: %w", path, i+1, err)
+            }
+            for _, ip := range addrs {
+                if prev, ok := m.byIP[ip]; ok && prev != name {
+                    return nil, fmt.Errorf("%s line %d: %s already belongs to asset %q", path, i+1, ip, prev)
+                }
+                m.byIP[ip] = name
+            }
+        }
+    }
+    return m, nil
+}
+
+// Name returns the asset ip belongs to; unmapped addresses are their own asset.
+func (m *Map) Name(ip string) string {
+    if name, ok := m.byIP[ip]; ok {
+        return name
+    }
+    return ip
+}
+
+// Enrich annotates r with its asset name.
+func (m *Map) Enrich(r *scanner.Result) {
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    r.Meta["asset"] = m.Name(r.IP)
+}
+
+// Asset is the merged view of one asset across its addresses.
+type Asset struct {
+    Name      string
+    Addresses []string
+    Open      []string // "port/proto", the union over all addresses
+}
+
+// Merge folds results into per-asset records, sorted by name.
+func (m *Map) Merge(results []scanner.Result) []Asset {
+    addrs := map[string]map[string]bool{}
+    open := map[string]map[string]bool{}
+    for _, r := range results {
+        name := m.Name(r.IP)
+        if addrs[name] == nil {
+            addrs[name], open[name] = map[string]bool{}, map[string]bool{}
+        }
+        addrs[name][r.IP] = true
+        if r.Status == scanner.Open {
+            open[name][strconv.Itoa(r.Port)+"/"+r.Proto] = true
+        }
+    }
+    out := make([]Asset, 0, len(addrs))
+    for name := range addrs {
+        out = append(out, Asset{Name: name, Addresses: sortedKeys(addrs[name]), Open: sortedKeys(open[name])})
+    }
+    sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+    return out
+}
+
+// Change is a port that opened or closed on an asset between two runs.
+type Change struct {
+    Asset  string
+    Port   string // "port/proto"
+    Opened bool   // false means the port is no longer open
+}
+
+// Diff compares two merged views. Assets missing from either side are
+// treated as having no open ports there.
+func Diff(before, after []Asset) []Change {
+    was := map[string]map[string]bool{}
+    names := map[string]bool{}
+    for _, a := range before {
+        was[a.Name] = setOf(a.Open)
+        names[a.Name] = true
+    }
+    now := map[string]map[string]bool{}
+    for _, a := range after {
+        now[a.Name] = setOf(a.Open)
+        names[a.Name] = true
+    }
+    changes := []Change{}
+    for _, name := range sortedKeys(names) {
+        for _, p := range sortedKeys(now[name]) {
+            if !was[name][p] {
+                changes = append(changes, Change{Asset: name, Port: p, Opened: true})
+            }
+        }
+        for _, p := range sortedKeys(was[name]) {
+            if !now[name][p] {
+                changes = append(changes, Change{Asset: name, Port: p})
+            }
+        }
+    }
+    return changes
+}
+
+func setOf(list []string) map[string]bool {
+    s := map[string]bool{}
+    for _, v := range list {
+        s[v] = true
+    }
+    return s
+}
+
+func sortedKeys(m map[string]bool) []string {
+    out := make([]string, 0, len(m))
+    for k := range m {
+        out = append(out, k)
+    }
+    sort.Strings(out)
+    return out
+}
@@ -2,35 +2,73 @@
 package checkpoint
 
 import (
-    "context"
     "encoding/json"
+    "fmt"
     "os"
+    "path/filepath"
+    "strings"
     "time"
 
     "goscant/internal/input"
-    "goscant/internal/logger"
+    "goscant/internal/seal"
 )
 
 type cpFile struct {
     Remaining [][]interface{} `json:"remaining"`
+    Output    string          `json:"output,omitempty"` // results completed so far
     Version   string          `json:"version"`
     Time      time.Time       `json:"time"`
 }
 
-func Handle(ctx context.Context, cfg *config.Config, taskCh <-chan input.ProbeTarget, log *logger.Logger) {
-    <-ctx.Done()
-    log.Info("interrupt received – dumping checkpoint")
+// Save writes targets to a new checkpoint file and returns its path. output
+// names the file holding the results completed so far; the checkpoint goes
+// next to it as checkpoint-<output name>-<time>.json (in the working
+// directory when output is ""), so concurrent plan jobs do not overwrite
+// each other. The checkpoint is sealed if key is not nil.
+func Save(targets []input.ProbeTarget, output string, key []byte) (string, error) {
     rem := [][]interface{}{}
-    for t := range taskCh {
+    for _, t := range targets {
+        if t.Proto != "" {
+            rem = append(rem, []interface{}{t.IP, t.Port, t.Proto})
+            continue
+        }
         rem = append(rem, []interface{}{t.IP, t.Port})
     }
-    f := cpFile{Remaining: rem, Version: "1", Time: time.Now()}
-    tmp := "checkpoint-" + f.Time.Format("2006-01-02T150405") + ".json.tmp"
-    final := strings.TrimSuffix(tmp, ".tmp")
-    os.WriteFile(tmp, mustJSON(f), 0644)
-    os.Rename(tmp, final)
-    log.Info("checkpoint saved to " + final)
-    os.Exit(0)
+    f := cpFile{Remaining: rem, Output: output, Version: "1", Time: time.Now()}
+    dir, name := ".", "scan"
+    if output != "" {
+        dir = filepath.Dir(output)
+        name = strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+    }
+    final := filepath.Join(dir, "checkpoint-"+name+"-"+f.Time.Format("2006-01-02T150405")+".json")
+    tmp, err := os.CreateTemp(dir, filepath.Base(final)+".*.tmp")
+    if err != nil {
+        return "", err
+    }
+    tmp.Close()
+    if err := seal.WriteFile(tmp.Name(), mustJSON(f), key); err != nil {
+        os.Remove(tmp.Name())
+        return "", err
+    }
+    if err := os.Rename(tmp.Name(), final); err != nil {
+        os.Remove(tmp.Name())
+        return "", err
+    }
+    return final, nil
+}
+
+// Output returns the results file recorded in the checkpoint at path, or
+// "" for checkpoints written before it was recorded.
+func Output(path string) (string, error) {
+    b, err := seal.ReadFile(path)
+    if err != nil {
+        return "", err
+    }
+    var f cpFile
+    if err := json.Unmarshal(b, &f); err != nil {
+        return "", fmt.Errorf("%s: %w", path, err)
+    }
+    return f.Output, nil
 }
 
 func mustJSON(v interface{}) []byte { b, _ := json.MarshalIndent(v, "", "  "); return b }
\ No newline at end of file
@@ -0,0 +1,159 @@
+// File: internal/compare/compare.go
+// Package compare diffs the open ports of two stored runs and decides,
+// from severity-weighted rules, whether the difference should fail a
+// pipeline.
+package compare
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+
+    "goscant/internal/scanner"
+    "goscant/internal/triage"
+)
+
+// Kinds of change between two runs.
+const (
+    New     = "new"     // open now, not open before
+    Closed  = "closed"  // open before, scanned again and no longer open
+    Raised  = "raised"  // open in both, severity went up
+    Lowered = "lowered" // open in both, severity went down
+)
+
+// Kinds lists the change kinds in report order.
+var Kinds = []string{New, Raised, Lowered, Closed}
+
+// Change is one port whose exposure differs between the runs.
+type Change struct {
+    Kind     string
+    IP       string
+    Port     int
+    Proto    string
+    Severity string // in the new run, or the old one for Closed
+    Previous string // old severity for Raised and Lowered
+    Service  string
+}
+
+// Weights score severities for Score; each step doubles.
+var Weights = map[string]int{"info": 1, "low": 2, "medium": 4, "high": 8, "critical": 16}
+
+func key(r scanner.Result) string {
+    return r.IP + "|" + strconv.Itoa(r.Port) + "|" + r.Proto
+}
+
+// severityOf returns r's triage severity; unlabeled rows count as info.
+func severityOf(r scanner.Result) string {
+    if s := r.Meta["severity"]; triage.Rank(s) >= 0 {
+        return s
+    }
+    return "info"
+}
+
+// Diff compares two runs. Ports the new run did not scan are not changes,
+// so comparing against a partial run reports no spurious closures.
+func Diff(before, after []scanner.Result) []Change {
+    was := map[string]scanner.Result{}
+    for _, r := range before {
+        if r.Status == scanner.Open {
+            was[key(r)] = r
+        }
+    }
+    scanned := map[string]bool{}
+    changes := []Change{}
+    for _, r := range after {
+        k := key(r)
+        scanned[k] = true
+        old, wasOpen := was[k]
+        if r.Status != scanner.Open {
+            if wasOpen {
+                changes = append(changes, Change{Kind: Closed, IP: r.IP, Port: r.Port, Proto: r.Proto, Severity: severityOf(old), Service: old.Service})
+                delete(was, k) // duplicate rows report once
+            }
+            continue
+        }
+        c := Change{IP: r.IP, Port: r.Port, Proto: r.Proto, Severity: severityOf(r), Service: r.Service}
+        switch {
+        case !wasOpen:
+            c.Kind = New
+        case triage.Rank(c.Severity) > triage.Rank(severityOf(old)):
+            c.Kind, c.Previous = Raised, severityOf(old)
+        case triage.Rank(c.Severity) < triage.Rank(severityOf(old)):
+            c.Kind, c.Previous = Lowered, severityOf(old)
+        default:
+            continue
+        }
+        was[k] = r // a later duplicate row is unchanged
+        changes = append(changes, c)
+    }
+    sort.SliceStable(changes, func(i, j int) bool {
+        a, b := changes[i], changes[j]
+        if ra, rb := triage.Rank(a.Severity), triage.Rank(b.Severity); ra != rb {
+            return ra > rb
+        }
+        if a.IP != b.IP {
+            return a.IP < b.IP
+        }
+        return a.Port < b.Port
+    })
+    return changes
+}
+
+// Score sums the weights of changes that increase exposure (New and Raised).
+func Score(changes []Change) int {
+    total := 0
+    for _, c := range changes {
+        if c.Kind == New || c.Kind == Raised {
+            total += Weights[c.Severity]
+        }
+    }
+    return total
+}
+
+// Rule fails a comparison on any change of Kind ("any" for every kind)
+// at or above severity Min.
+type Rule struct {
+    Kind string
+    Min  string
+}
+
+func (r Rule) String() string { return r.Kind + "-" + r.Min }
+
+// ParseRules parses a comma-separated list such as "new-critical,raised-high".
+func ParseRules(s string) ([]Rule, error) {
+    var rules []Rule
+    for _, tok := range strings.Split(s, ",") {
+        tok = strings.ToLower(strings.TrimSpace(tok))
+        if tok == "" {
+            continue
+        }
+        kind, sev, ok := strings.Cut(tok, "-")
+        if !ok || (kind != "any" && !validKind(kind)) || triage.Rank(sev) < 0 {
+            return nil, fmt.Errorf("invalid rule %q (want KIND-SEVERITY: KIND one of any, %s; SEVERITY one of %s)",
+                tok, strings.Join(Kinds, ", "), strings.Join(triage.Severities, ", "))
+        }
+        rules = append(rules, Rule{Kind: kind, Min: sev})
+    }
+    return rules, nil
+}
+
+func validKind(k string) bool {
+    for _, v := range Kinds {
+        if v == k {
+            return true
+        }
+    }
+    return false
+}
+
+// Match returns the changes r fails on.
+func (r Rule) Match(changes []Change) []Change {
+    var out []Change
+    for _, c := range changes {
+        if (r.Kind == "any" || r.Kind == c.Kind) && triage.Rank(c.Severity) >= triage.Rank(r.Min) {
+            out = append(out, c)
+        }
+    }
+    return out
+}
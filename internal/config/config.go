@@ -1,18 +1,163 @@
 // File: internal/config/config.go
 package config
 
-import "time"
+import (
+    "time"
+
+    "goscant/internal/consul"
+    "goscant/internal/egress"
+)
+
+// ScanTypes lists the accepted values of Config.ScanType.
+var ScanTypes = []string{"tcp", "sctp", "ipproto", "quic", "tls"}
+
+// ValidScanType reports whether t is one of ScanTypes.
+func ValidScanType(t string) bool {
+    for _, s := range ScanTypes {
+        if s == t {
+            return true
+        }
+    }
+    return false
+}
 
 // Config centralises all runtime parameters.
 type Config struct {
     IPInput    string
     PortInput  string
+    IPColumn   string // CSV column of IPInput: header name or 1-based index
+    PortColumn string // CSV column of PortInput, likewise
+
+    Exclude      string // IPs, CIDRs and hostnames removed from the targets
+    ExcludeFile  string
+    ExcludePorts string // ports and ranges removed from the port set
+    TopPorts     int    // most commonly open TCP ports added to the port set
+    Sample       string // host count or percentage to scan at random
+    SampleSeed   int64
+    Randomize    bool   // shuffle the host order
+    RandomSeed   int64  // seed for Randomize; 0 picks one
+    Order        string // "port" (one port across all hosts first) or "host"
+    V6MinPrefix  int    // shortest IPv6 prefix expanded host by host
+    V6ExpandAll  bool   // expand IPv6 prefixes of any length
+    AllowSelf    bool   // keep the scanner's own addresses in the targets
+    AllowPublic  bool   // allow targets outside private address space
+    KeepReserved bool   // keep multicast, reserved and documentation ranges
+    SkipNetBcast bool   // drop network and broadcast addresses of IPv4 CIDRs
+    NmapPorts    bool   // scan the open ports of an nmap XML --ip, not --port
+
+    TargetsFrom    string          // k8s, aws or consul: targets come from an inventory
+    K8sAPI         string          // API server URL; "" uses the in-cluster service account
+    K8sNamespace   string          // "" lists every namespace
+    K8sSelector    string          // label selector
+    AWSRegions     string          // comma-separated regions for --targets-from aws
+    AWSFilter      string          // DescribeInstances filters, name=value[|value],...
+    AWSPublic      bool            // also scan instances' public addresses
+    ConsulAddr     string          // agent URL for --targets-from consul
+    ConsulDC       string          // datacenter; "" is the agent's
+    ConsulServices string          // comma-separated services; "" for all
+    Consul         *consul.Catalog // loaded from the agent when the scan starts
+
     NumWorkers int
     Timeout    time.Duration
     Delay      time.Duration
     QueueSize  int
     DryRun     bool
     ResumeFile string
+    Rescan     string // earlier results whose open ports are the targets
+    Masscan    string // masscan output whose open ports are the targets
     OutputPath string
+    Format     string
+    AddrFormat string
     LogPath    string
+    OwnersFile string
+    AssetsFile string
+    ScanType   string
+    TriageFile string
+    Rate       int
+    PlanFile   string
+
+    ConfirmAbove int  // target count above which the scan must be confirmed
+    Yes          bool // confirm without asking
+
+    ControlSocket string
+    PingMethods   string
+    PingCount     int // attempts per discovery probe and round
+    PingRetry     int // extra discovery rounds for silent hosts
+    TraceTargets  string
+    MonoOffset    bool
+    Retries       int
+
+    Via               string        // ssh://user@host jump host for connect scans
+    EgressFile        string        // JSON file of named egress profiles
+    Egress            string        // profile forced for every target
+    EgressProfiles    *egress.Set   // loaded from EgressFile
+    ReportDown        bool          // write a HOST_DOWN row per host dropped by discovery
+    DiscoveryCache    string        // file of per-host discovery verdicts
+    DiscoveryCacheTTL time.Duration // how long a verdict is reused; 0 = forever
+
+    AdaptiveTimeout bool
+    MinTimeout      time.Duration
+
+    Filters   string
+    OpenOnly  bool // --open: shorthand for --filter open
+    Mirrors   string
+    OutputAll string // base name written in every writer.AllFormats
+
+    InventoryPath string
+    MarkdownPath  string
+    PartitionBy   string
+    RotateRows    int64
+    RotateSize    string
+    RotateBytes   int64 // parsed from RotateSize
+
+    AggregateOnly    bool
+    AggregateMin     int
+    AggregateEpsilon float64
+
+    Encrypt    bool
+    EncryptKey []byte // the secret in $GOSCANT_SEAL_KEY when Encrypt is set
+
+    TLSCerts bool
+
+    ServiceDetect  bool
+    ServiceTimeout time.Duration
+    ServiceDB      string
+
+    SNMPCommunities string
+    SNMPTimeout     time.Duration
+
+    PTR        bool // add a hostname column from reverse DNS
+    PTRWorkers int
+
+    Banner        bool
+    BannerBytes   int
+    BannerTimeout time.Duration
+
+    FragileFile string
+    FragileGap  time.Duration
+
+    Anonymize    bool
+    AnonymizeMap string
+
+    BatchPorts    int
+    NoRST         bool
+    Stateless     bool
+    StatelessWait time.Duration
+
+    Traceroute       string // probe method; empty disables path tracing
+    TracerouteSample int
+    TracerouteHops   int
+    TracerouteReport string
+
+    HostDownAfter  int    // consecutive errors; 0 disables the policy
+    HostDownErrors string // error kinds that count, see hostdown.Kinds
+
+    MaxMem string // e.g. "2G"; empty means no cap
+    MaxCPU string // e.g. "50%"; empty means no cap
+
+    // Shutdown phase timeouts, applied in this order after an interrupt
+    ShutdownProduce    time.Duration
+    ShutdownDrain      time.Duration
+    ShutdownFlush      time.Duration
+    ShutdownCheckpoint time.Duration
 }
\ No newline at end of file
@@ -0,0 +1,130 @@
+// File: internal/consul/consul.go
+// Package consul reads service registrations from a Consul catalog, so a
+// scan can check that nodes listen on the ports they registered and on
+// nothing else.
+package consul
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Endpoint is one registered service instance.
+type Endpoint struct {
+    IP      string
+    Port    int
+    Service string
+}
+
+// Catalog is a snapshot of the registrations of some services.
+type Catalog struct {
+    Endpoints []Endpoint
+    nodes     []string            // addresses, in order of first appearance
+    byAddr    map[string][]string // "ip:port" -> services registered there
+}
+
+// Load fetches the instances of services (all services if empty) from the
+// agent at addr (default $CONSUL_HTTP_ADDR, else http://127.0.0.1:8500) in
+// datacenter dc ("" for the agent's), using $CONSUL_HTTP_TOKEN if set.
+func Load(ctx context.Context, addr, dc string, services []string) (*Catalog, error) {
+    if addr == "" {
+        addr = os.Getenv("CONSUL_HTTP_ADDR")
+    }
+    if addr == "" {
+        addr = "http://127.0.0.1:8500"
+    }
+    if !strings.Contains(addr, "://") {
+        addr = "http://" + addr
+    }
+    c := &client{base: strings.TrimSuffix(addr, "/"), dc: dc, token: os.Getenv("CONSUL_HTTP_TOKEN"), http: &http.Client{Timeout: 30 * time.Second}}
+
+    if len(services) == 0 {
+        var all map[string][]string
+        if err := c.get(ctx, "/v1/catalog/services", &all); err != nil {
+            return nil, err
+        }
+        for name := range all {
+            services = append(services, name)
+        }
+        sort.Strings(services)
+    }
+    cat := &Catalog{byAddr: map[string][]string{}}
+    seen := map[string]bool{}
+    for _, name := range services {
+        var instances []struct {
+            Address        string
+            ServiceAddress string
+            ServicePort    int
+        }
+        if err := c.get(ctx, "/v1/catalog/service/"+url.PathEscape(name), &instances); err != nil {
+            return nil, err
+        }
+        for _, in := range instances {
+            ip := in.ServiceAddress
+            if ip == "" {
+                ip = in.Address
+            }
+            if net.ParseIP(ip) == nil || in.ServicePort == 0 {
+                continue
+            }
+            if !seen[ip] {
+                seen[ip] = true
+                cat.nodes = append(cat.nodes, ip)
+            }
+            key := net.JoinHostPort(ip, strconv.Itoa(in.ServicePort))
+            cat.byAddr[key] = append(cat.byAddr[key], name)
+            cat.Endpoints = append(cat.Endpoints, Endpoint{IP: ip, Port: in.ServicePort, Service: name})
+        }
+    }
+    return cat, nil
+}
+
+// Nodes returns the distinct addresses with registrations.
+func (c *Catalog) Nodes() []string { return c.nodes }
+
+// Services returns the services registered on ip and port.
+func (c *Catalog) Services(ip string, port int) []string {
+    return c.byAddr[net.JoinHostPort(ip, strconv.Itoa(port))]
+}
+
+type client struct {
+    base, dc, token string
+    http            *http.Client
+}
+
+// get fetches one catalog endpoint into v.
+func (c *client) get(ctx context.Context, path string, v any) error {
+    if c.dc != "" {
+        path += "?dc=" + url.QueryEscape(c.dc)
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+    if err != nil {
+        return err
+    }
+    if c.token != "" {
+        req.Header.Set("X-Consul-Token", c.token)
+    }
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return fmt.Errorf("consul: %w", err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        return fmt.Errorf("consul: %s: %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+    }
+    if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+        return fmt.Errorf("consul: %s: %w", path, err)
+    }
+    return nil
+}
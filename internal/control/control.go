@@ -0,0 +1,161 @@
+// File: internal/control/control.go
+package control
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "net"
+    "os"
+    "runtime/pprof"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+
+    "goscant/internal/checkpoint"
+    "goscant/internal/input"
+    "goscant/internal/logger"
+    "goscant/internal/ratelimit"
+)
+
+const help = "commands: status | pause | resume | set-rate <pps> | checkpoint | dump-goroutines | help"
+
+// Job exposes one running scan to the control socket.
+type Job struct {
+    Name      string
+    Total     int
+    Written   func() int64
+    Remaining func() []input.ProbeTarget // nil if the job cannot checkpoint; returns nil until probing starts
+    Key       []byte                     // seals checkpoints when set
+}
+
+// Server answers line-based commands on a local Unix socket so wrapper
+// tooling can manage a running scan without POSIX signals.
+type Server struct {
+    path    string
+    ln      net.Listener
+    limiter *ratelimit.Limiter
+    log     *logger.Logger
+
+    mu   sync.Mutex
+    jobs map[string]*Job
+}
+
+// Listen creates the socket (owner-only) and starts serving it.
+func Listen(path string, limiter *ratelimit.Limiter, log *logger.Logger) (*Server, error) {
+    os.Remove(path) // stale socket from a crashed run
+    ln, err := net.Listen("unix", path)
+    if err != nil {
+        return nil, err
+    }
+    if err := os.Chmod(path, 0600); err != nil {
+        ln.Close()
+        return nil, err
+    }
+    s := &Server{path: path, ln: ln, limiter: limiter, log: log, jobs: map[string]*Job{}}
+    go s.serve()
+    return s, nil
+}
+
+// Attach registers a running job; Detach removes it when it finishes.
+func (s *Server) Attach(j *Job) {
+    s.mu.Lock()
+    s.jobs[j.Name] = j
+    s.mu.Unlock()
+}
+
+func (s *Server) Detach(name string) {
+    s.mu.Lock()
+    delete(s.jobs, name)
+    s.mu.Unlock()
+}
+
+// Close stops serving and removes the socket file.
+func (s *Server) Close() error {
+    err := s.ln.Close()
+    os.Remove(s.path)
+    return err
+}
+
+func (s *Server) serve() {
+    for {
+        conn, err := s.ln.Accept()
+        if err != nil {
+            return
+        }
+        go s.handle(conn)
+    }
+}
+
+func (s *Server) handle(conn net.Conn) {
+    defer conn.Close()
+    in := bufio.NewScanner(conn)
+    for in.Scan() {
+        f := strings.Fields(in.Text())
+        if len(f) == 0 {
+            continue
+        }
+        s.log.Info("control: " + strings.Join(f, " "))
+        s.exec(conn, f)
+    }
+}
+
+func (s *Server) exec(w io.Writer, f []string) {
+    switch f[0] {
+    case "status":
+        fmt.Fprintf(w, "paused=%v rate=%d backoff=%s\n", s.limiter.Paused(), s.limiter.Rate(), s.limiter.Backoff())
+        for _, j := range s.sortedJobs() {
+            fmt.Fprintf(w, "job %s: written=%d total=%d\n", j.Name, j.Written(), j.Total)
+        }
+    case "pause":
+        s.limiter.Pause()
+        fmt.Fprintln(w, "ok")
+    case "resume":
+        s.limiter.Resume()
+        fmt.Fprintln(w, "ok")
+    case "set-rate":
+        n, err := strconv.Atoi(strings.Join(f[1:], ""))
+        if err != nil || n < 0 {
+            fmt.Fprintln(w, "error: usage set-rate <probes per second, 0 = unlimited>")
+            return
+        }
+        s.limiter.SetRate(n)
+        fmt.Fprintln(w, "ok")
+    case "checkpoint":
+        for _, j := range s.sortedJobs() {
+            if j.Remaining == nil {
+                fmt.Fprintf(w, "job %s: checkpoint not supported\n", j.Name)
+                continue
+            }
+            rem := j.Remaining()
+            if rem == nil {
+                fmt.Fprintf(w, "job %s: not probing yet, nothing to checkpoint\n", j.Name)
+                continue
+            }
+            path, err := checkpoint.Save(rem, j.Name, j.Key)
+            if err != nil {
+                fmt.Fprintf(w, "job %s: error: %v\n", j.Name, err)
+                continue
+            }
+            fmt.Fprintf(w, "job %s: %d targets saved to %s\n", j.Name, len(rem), path)
+        }
+    case "dump-goroutines":
+        pprof.Lookup("goroutine").WriteTo(w, 1)
+    case "help":
+        fmt.Fprintln(w, help)
+    default:
+        fmt.Fprintln(w, "error: unknown command; "+help)
+    }
+}
+
+func (s *Server) sortedJobs() []*Job {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    jobs := make([]*Job, 0, len(s.jobs))
+    for _, j := range s.jobs {
+        jobs = append(jobs, j)
+    }
+    sort.Slice(jobs, func(a, b int) bool { return jobs[a].Name < jobs[b].Name })
+    return jobs
+}
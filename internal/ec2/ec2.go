@@ -0,0 +1,128 @@
+// File: internal/ec2/ec2.go
+// Package ec2 lists the addresses of EC2 instances, so cloud teams can scan
+// an account's fleet and catch security groups that drifted open. It uses
+// the AWS SDK, so credentials and settings resolve as for the aws CLI:
+// environment, shared config profiles and SSO, web identity, and the ECS
+// or instance role.
+package ec2
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/config"
+    "github.com/aws/aws-sdk-go-v2/service/ec2"
+    "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Instance is one instance and the addresses of all its network interfaces.
+type Instance struct {
+    ID      string
+    Name    string // the Name tag
+    Region  string
+    Private []string
+    Public  []string
+    IPv6    []string // globally routable, like Public
+}
+
+// Filter is one DescribeInstances filter, e.g. {"tag:Env", ["prod"]} or
+// {"vpc-id", ["vpc-0abc"]}.
+type Filter struct {
+    Name   string
+    Values []string
+}
+
+// ParseFilters parses "name=value[|value...],..." into filters, e.g.
+// "tag:Env=prod|staging,vpc-id=vpc-0abc".
+func ParseFilters(s string) ([]Filter, error) {
+    var out []Filter
+    for _, part := range strings.Split(s, ",") {
+        if part = strings.TrimSpace(part); part == "" {
+            continue
+        }
+        name, values, ok := strings.Cut(part, "=")
+        if !ok || name == "" || values == "" {
+            return nil, fmt.Errorf("aws filter %q: want name=value", part)
+        }
+        out = append(out, Filter{Name: strings.TrimSpace(name), Values: strings.Split(values, "|")})
+    }
+    return out, nil
+}
+
+// Client calls the EC2 API of one region.
+type Client struct {
+    region string
+    api    *ec2.Client
+}
+
+// NewClient returns a client for region with the default credential chain.
+// Missing credentials surface on the first call.
+func NewClient(ctx context.Context, region string) (*Client, error) {
+    cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+    if err != nil {
+        return nil, fmt.Errorf("aws %s: %w", region, err)
+    }
+    return &Client{region: region, api: ec2.NewFromConfig(cfg)}, nil
+}
+
+// Instances lists the instances matching filters, following pagination.
+func (c *Client) Instances(ctx context.Context, filters []Filter) ([]Instance, error) {
+    input := &ec2.DescribeInstancesInput{MaxResults: aws.Int32(1000)}
+    for _, f := range filters {
+        input.Filters = append(input.Filters, types.Filter{Name: aws.String(f.Name), Values: f.Values})
+    }
+    var out []Instance
+    pages := ec2.NewDescribeInstancesPaginator(c.api, input)
+    for pages.HasMorePages() {
+        page, err := pages.NextPage(ctx)
+        if err != nil {
+            return nil, fmt.Errorf("aws %s: %w", c.region, err)
+        }
+        for _, r := range page.Reservations {
+            for _, in := range r.Instances {
+                out = append(out, c.instance(in))
+            }
+        }
+    }
+    return out, nil
+}
+
+// instance collects the name and addresses of one described instance.
+func (c *Client) instance(in types.Instance) Instance {
+    inst := Instance{ID: aws.ToString(in.InstanceId), Region: c.region}
+    for _, t := range in.Tags {
+        if aws.ToString(t.Key) == "Name" {
+            inst.Name = aws.ToString(t.Value)
+        }
+    }
+    for _, eni := range in.NetworkInterfaces {
+        for _, a := range eni.PrivateIpAddresses {
+            if ip := aws.ToString(a.PrivateIpAddress); ip != "" {
+                inst.Private = append(inst.Private, ip)
+            }
+            if a.Association != nil {
+                if ip := aws.ToString(a.Association.PublicIp); ip != "" {
+                    inst.Public = append(inst.Public, ip)
+                }
+            }
+        }
+        for _, a := range eni.Ipv6Addresses {
+            if ip := aws.ToString(a.Ipv6Address); ip != "" {
+                inst.IPv6 = append(inst.IPv6, ip)
+            }
+        }
+    }
+    return inst
+}
+
+// Label returns the instance as written to target_host, e.g.
+// "ec2/us-east-1/i-0abc (web-1)".
+func (in Instance) Label() string {
+    l := "ec2/" + in.Region + "/" + in.ID
+    if in.Name != "" {
+        l += " (" + in.Name + ")"
+    }
+    return l
+}
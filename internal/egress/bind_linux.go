@@ -0,0 +1,19 @@
+// File: internal/egress/bind_linux.go
+//go:build linux
+
+package egress
+
+import "syscall"
+
+// bindToDevice pins the socket behind rc to the named interface, so its
+// packets leave there whatever the routing table says.
+func bindToDevice(rc syscall.RawConn, ifname string) error {
+    var serr error
+    err := rc.Control(func(fd uintptr) {
+        serr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, ifname)
+    })
+    if err != nil {
+        return err
+    }
+    return serr
+}
@@ -0,0 +1,13 @@
+// File: internal/egress/bind_other.go
+//go:build !linux
+
+package egress
+
+import (
+    "errors"
+    "syscall"
+)
+
+func bindToDevice(rc syscall.RawConn, ifname string) error {
+    return errors.New("egress: binding to an interface needs Linux; use a source address instead")
+}
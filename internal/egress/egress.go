@@ -0,0 +1,149 @@
+// File: internal/egress/egress.go
+// Package egress binds probes to named egress profiles, so internal ranges
+// can be reached through a VPN interface (e.g. wg0) while external ranges
+// leave through the ordinary uplink, in one scan and one configuration.
+// Only socket-based probes are bound; host discovery follows the routing
+// table.
+package egress
+
+import (
+    "encoding/json"
+    "fmt"
+    "net"
+    "os"
+    "syscall"
+    "time"
+)
+
+// Profile is one way out of the host: a source address, an interface to
+// bind to, or both, and the ranges ("routes") it is used for.
+type Profile struct {
+    Name      string   `json:"name"`
+    Interface string   `json:"interface"`
+    Source    string   `json:"source"`
+    Routes    []string `json:"routes"`
+
+    src  net.IP
+    nets []*net.IPNet
+}
+
+// Set is the loaded profiles. A nil Set, like a nil Profile, leaves every
+// choice to the kernel's routing table.
+type Set struct {
+    profiles []*Profile
+    forced   *Profile // --egress: one profile for every target
+}
+
+// Load reads a JSON array of profiles, e.g.
+//
+//	[{"name": "corp", "interface": "wg0", "routes": ["10.0.0.0/8"]},
+//	 {"name": "uplink", "source": "203.0.113.7"}]
+//
+// If name is not empty, that profile is used for every target; otherwise
+// each target uses the profile with the most specific route containing it.
+func Load(path, name string) (*Set, error) {
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var profiles []*Profile
+    if err := json.Unmarshal(b, &profiles); err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    s := &Set{profiles: profiles}
+    seen := map[string]bool{}
+    for i, p := range profiles {
+        if p.Name == "" || seen[p.Name] {
+            return nil, fmt.Errorf("%s profile %d: missing or duplicate name", path, i+1)
+        }
+        seen[p.Name] = true
+        if p.Interface == "" && p.Source == "" {
+            return nil, fmt.Errorf("%s profile %q: give an interface, a source or both", path, p.Name)
+        }
+        if p.Interface != "" {
+            if _, err := net.InterfaceByName(p.Interface); err != nil {
+                return nil, fmt.Errorf("%s profile %q: %w", path, p.Name, err)
+            }
+        }
+        if p.Source != "" {
+            if p.src = net.ParseIP(p.Source); p.src == nil {
+                return nil, fmt.Errorf("%s profile %q: source %q is not an IP", path, p.Name, p.Source)
+            }
+        }
+        for _, r := range p.Routes {
+            _, n, err := net.ParseCIDR(r)
+            if err != nil {
+                return nil, fmt.Errorf("%s profile %q: %w", path, p.Name, err)
+            }
+            p.nets = append(p.nets, n)
+        }
+        if p.Name == name {
+            s.forced = p
+        }
+    }
+    if name != "" && s.forced == nil {
+        return nil, fmt.Errorf("%s: no profile named %q", path, name)
+    }
+    return s, nil
+}
+
+// For returns the profile to reach ip through, or nil for the default route.
+func (s *Set) For(ip string) *Profile {
+    if s == nil {
+        return nil
+    }
+    if s.forced != nil {
+        return s.forced
+    }
+    dst := net.ParseIP(ip)
+    if dst == nil {
+        return nil
+    }
+    var best *Profile
+    bestLen := -1
+    for _, p := range s.profiles {
+        for _, n := range p.nets {
+            if ones, _ := n.Mask.Size(); n.Contains(dst) && ones > bestLen {
+                best, bestLen = p, ones
+            }
+        }
+    }
+    return best
+}
+
+// Dialer returns a dialer for network ("tcp" or "udp") leaving through p
+// toward ip. A source address of the other family than ip is not used.
+func (p *Profile) Dialer(network, ip string, timeout time.Duration) *net.Dialer {
+    d := &net.Dialer{Timeout: timeout}
+    if p == nil {
+        return d
+    }
+    if dst := net.ParseIP(ip); p.src != nil && dst != nil && (p.src.To4() == nil) == (dst.To4() == nil) {
+        if network == "udp" {
+            d.LocalAddr = &net.UDPAddr{IP: p.src}
+        } else {
+            d.LocalAddr = &net.TCPAddr{IP: p.src}
+        }
+    }
+    if p.Interface != "" {
+        d.Control = func(network, address string, rc syscall.RawConn) error {
+            return bindToDevice(rc, p.Interface)
+        }
+    }
+    return d
+}
+
+// Summary describes the profiles in use for the log.
+func (s *Set) Summary() []string {
+    if s == nil {
+        return nil
+    }
+    var lines []string
+    for _, p := range s.profiles {
+        if s.forced != nil && p != s.forced {
+            continue
+        }
+        lines = append(lines, fmt.Sprintf("%s: interface=%q source=%q routes=%v", p.Name, p.Interface, p.Source, p.Routes))
+    }
+    return lines
+}
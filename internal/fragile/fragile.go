@@ -0,0 +1,144 @@
+// File: internal/fragile/fragile.go
+package fragile
+
+import (
+    "context"
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/scanner"
+)
+
+// ErrSkipped marks results for fragile targets the scan type may not touch.
+var ErrSkipped = errors.New("fragile target: scan type sends payloads, skipped")
+
+// Guard enforces the fragile-device profile for tagged targets: one probe
+// per host at a time, at least gap between probes of a host, and no
+// payload-carrying or application-layer probes. A nil Guard tags nothing.
+type Guard struct {
+    blocks []*net.IPNet
+    gap    time.Duration
+    safe   scanner.Scanner // engine for fragile targets; nil skips them
+
+    mu    sync.Mutex
+    hosts map[string]*host
+}
+
+type host struct {
+    busy chan struct{} // one-slot semaphore
+    next time.Time
+}
+
+// Load reads fragile addresses or CIDRs, one per row in the first CSV
+// column. A leading "cidr" header row and '#' comments are ignored.
+func Load(path string, gap time.Duration) (*Guard, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    r := csv.NewReader(f)
+    r.Comment = '#'
+    r.FieldsPerRecord = -1
+    recs, err := r.ReadAll()
+    if err != nil {
+        return nil, err
+    }
+
+    g := &Guard{gap: gap, hosts: map[string]*host{}}
+    for i, rec := range recs {
+        cidr := strings.TrimSpace(rec[0])
+        if i == 0 && strings.EqualFold(cidr, "cidr") {
+            continue
+        }
+        if !strings.Contains(cidr, "/") {
+            if strings.Contains(cidr, ":") {
+                cidr += "/128"
+            } else {
+                cidr += "/32"
+            }
+        }
+        _, block, err := net.ParseCIDR(cidr)
+        if err != nil {
+            return nil, fmt.Errorf("%s line %d: %w", path, i+1, err)
+        }
+        g.blocks = append(g.blocks, block)
+    }
+    return g, nil
+}
+
+// UseEngine derives the engine for fragile targets from the scan's own:
+// TLS handshakes and banner reads become plain connects, QUIC (a UDP
+// payload) is refused, header-only engines are kept.
+func (g *Guard) UseEngine(cfg *config.Config, engine scanner.Scanner) {
+    if g == nil {
+        return
+    }
+    switch {
+    case cfg.ScanType == "quic":
+        g.safe = nil
+    case cfg.ScanType == "tls" || cfg.Banner:
+        plain := *cfg
+        plain.ScanType, plain.Banner = "tcp", false
+        g.safe = scanner.NewSocketScanner(&plain)
+    default:
+        g.safe = engine
+    }
+}
+
+// Fragile reports whether ip is tagged.
+func (g *Guard) Fragile(ip string) bool {
+    if g == nil {
+        return false
+    }
+    addr := net.ParseIP(ip)
+    for _, b := range g.blocks {
+        if addr != nil && b.Contains(addr) {
+            return true
+        }
+    }
+    return false
+}
+
+// Engine returns the engine to probe fragile targets with, or false if
+// they must be skipped.
+func (g *Guard) Engine() (scanner.Scanner, bool) {
+    return g.safe, g.safe != nil
+}
+
+// Acquire waits until ip has no probe in flight and its gap has elapsed.
+// The returned release must be called once the probe finishes.
+func (g *Guard) Acquire(ctx context.Context, ip string) (release func(), err error) {
+    g.mu.Lock()
+    h, ok := g.hosts[ip]
+    if !ok {
+        h = &host{busy: make(chan struct{}, 1)}
+        g.hosts[ip] = h
+    }
+    g.mu.Unlock()
+
+    select {
+    case h.busy <- struct{}{}:
+    case <-ctx.Done():
+        return nil, ctx.Err()
+    }
+    if wait := time.Until(h.next); wait > 0 {
+        select {
+        case <-time.After(wait):
+        case <-ctx.Done():
+            <-h.busy
+            return nil, ctx.Err()
+        }
+    }
+    return func() {
+        h.next = time.Now().Add(g.gap)
+        <-h.busy
+    }, nil
+}
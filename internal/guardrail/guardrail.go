@@ -0,0 +1,160 @@
+// File: internal/guardrail/guardrail.go
+// Package guardrail watches the scanner's own CPU, memory and socket usage
+// and slows the workers down while it exceeds the configured caps, so a
+// scan sharing a jump host does not starve other tooling on it.
+package guardrail
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "runtime"
+    "runtime/debug"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/shirou/gopsutil/v3/process"
+
+    "goscant/internal/logger"
+    "goscant/internal/ratelimit"
+)
+
+const (
+    sampleEvery = time.Second
+    minBackoff  = 10 * time.Millisecond // first step of throttling
+    maxBackoff  = 2 * time.Second
+    relax       = 0.8 // below relax × cap the throttle eases off
+)
+
+// Caps are the optional limits; zero disables one.
+type Caps struct {
+    Mem uint64  // resident memory in bytes
+    CPU float64 // percent of the whole machine (all cores)
+}
+
+// Usage is one sample of the process's resource use.
+type Usage struct {
+    Mem uint64
+    CPU float64 // percent of the whole machine (all cores)
+    FDs int32   // open descriptors, sockets included
+}
+
+// ParseSize parses a byte count with an optional K, M, G or T suffix
+// (powers of 1024), e.g. "2G".
+func ParseSize(s string) (uint64, error) {
+    num := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+    mult := uint64(1)
+    if n := len(num); n > 0 {
+        if i := strings.IndexByte("KMGT", num[n-1]); i >= 0 {
+            mult, num = 1<<(10*(i+1)), num[:n-1]
+        }
+    }
+    v, err := strconv.ParseFloat(num, 64)
+    if err != nil || v <= 0 {
+        return 0, fmt.Errorf("invalid size %q", s)
+    }
+    return uint64(v * float64(mult)), nil
+}
+
+// ParsePercent parses "50%" or "50" as a share of the whole machine.
+func ParsePercent(s string) (float64, error) {
+    v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+    if err != nil || v <= 0 || v > 100 {
+        return 0, fmt.Errorf("invalid percentage %q (want 1-100%%)", s)
+    }
+    return v, nil
+}
+
+// Guard samples usage and drives the limiter's backoff: doubling it while
+// any cap is exceeded, halving it once usage is comfortably below them.
+type Guard struct {
+    caps    Caps
+    proc    *process.Process
+    limiter *ratelimit.Limiter
+    log     *logger.Logger
+}
+
+// New returns a Guard for the running process.
+func New(caps Caps, limiter *ratelimit.Limiter, log *logger.Logger) (*Guard, error) {
+    proc, err := process.NewProcess(int32(os.Getpid()))
+    if err != nil {
+        return nil, fmt.Errorf("guardrail: %w", err)
+    }
+    proc.Percent(0) // primes the CPU counter; the first reading is meaningless
+    return &Guard{caps: caps, proc: proc, limiter: limiter, log: log}, nil
+}
+
+// Run samples usage every second until ctx is done.
+func (g *Guard) Run(ctx context.Context) {
+    t := time.NewTicker(sampleEvery)
+    defer t.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-t.C:
+        }
+        u, err := g.Sample()
+        if err != nil {
+            g.log.Debugf("guardrail: sample failed: %v", err)
+            continue
+        }
+        g.adjust(u)
+    }
+}
+
+// Sample reads the process's current usage.
+func (g *Guard) Sample() (Usage, error) {
+    mem, err := g.proc.MemoryInfo()
+    if err != nil {
+        return Usage{}, err
+    }
+    cpu, err := g.proc.Percent(0)
+    if err != nil {
+        return Usage{}, err
+    }
+    fds, _ := g.proc.NumFDs() // not available everywhere
+    return Usage{Mem: mem.RSS, CPU: cpu / float64(runtime.NumCPU()), FDs: fds}, nil
+}
+
+func (g *Guard) adjust(u Usage) {
+    b := g.limiter.Backoff()
+    g.log.Debugf("guardrail: mem=%dMiB cpu=%.0f%% fds=%d backoff=%s", u.Mem>>20, u.CPU, u.FDs, b)
+    if reason := g.over(u); reason != "" {
+        if u.Mem > g.caps.Mem && g.caps.Mem > 0 {
+            debug.FreeOSMemory()
+        }
+        next := min(max(2*b, minBackoff), maxBackoff)
+        if b == 0 {
+            g.log.Warn(fmt.Sprintf("guardrail: %s; throttling workers (%d open descriptors)", reason, u.FDs))
+        }
+        g.limiter.SetBackoff(next)
+        return
+    }
+    if b > 0 && g.relaxed(u) {
+        next := b / 2
+        if next < minBackoff {
+            next = 0
+            g.log.Info("guardrail: usage back under the caps; throttle lifted")
+        }
+        g.limiter.SetBackoff(next)
+    }
+}
+
+// over describes the first cap u exceeds, or returns "".
+func (g *Guard) over(u Usage) string {
+    if g.caps.Mem > 0 && u.Mem > g.caps.Mem {
+        return fmt.Sprintf("memory %dMiB over the %dMiB cap", u.Mem>>20, g.caps.Mem>>20)
+    }
+    if g.caps.CPU > 0 && u.CPU > g.caps.CPU {
+        return fmt.Sprintf("CPU %.0f%% over the %.0f%% cap", u.CPU, g.caps.CPU)
+    }
+    return ""
+}
+
+func (g *Guard) relaxed(u Usage) bool {
+    memOK := g.caps.Mem == 0 || float64(u.Mem) < relax*float64(g.caps.Mem)
+    cpuOK := g.caps.CPU == 0 || u.CPU < relax*g.caps.CPU
+    return memOK && cpuOK
+}
@@ -0,0 +1,146 @@
+// File: internal/hostdown/hostdown.go
+// Package hostdown gives up on hosts whose probes keep failing with errors
+// that mean the host itself is unreachable (e.g. EHOSTUNREACH), so their
+// remaining ports fail fast instead of each waiting out its own error.
+// Silence is not an error here: timeouts stay FILTERED and never count.
+package hostdown
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "syscall"
+
+    "goscant/internal/models"
+    "goscant/internal/scanner"
+)
+
+// ErrHostDown marks results fast-failed because their host was marked down.
+var ErrHostDown = errors.New("host marked down after consecutive errors")
+
+// Kinds maps the error names accepted by New to their errnos.
+var Kinds = map[string]syscall.Errno{
+    "EHOSTUNREACH":  syscall.EHOSTUNREACH,
+    "ENETUNREACH":   syscall.ENETUNREACH,
+    "EHOSTDOWN":     syscall.EHOSTDOWN,
+    "EADDRNOTAVAIL": syscall.EADDRNOTAVAIL,
+    "EACCES":        syscall.EACCES,
+    "EPERM":         syscall.EPERM,
+}
+
+// DefaultKinds is the error list used when none is given.
+const DefaultKinds = "EHOSTUNREACH,ENETUNREACH,EHOSTDOWN"
+
+// Policy counts consecutive matching errors per host and marks a host down
+// once the count reaches the threshold. A nil Policy marks nothing.
+type Policy struct {
+    after int
+    kinds []syscall.Errno
+
+    mu      sync.Mutex
+    streak  map[string]int
+    down    map[string]bool
+    skipped map[string]int // fast-failed targets per down host
+}
+
+// New returns a Policy marking hosts down after n consecutive errors of
+// the comma-separated kinds (see Kinds), or nil if n is not positive.
+func New(n int, kinds string) (*Policy, error) {
+    if n <= 0 {
+        return nil, nil
+    }
+    p := &Policy{after: n, streak: map[string]int{}, down: map[string]bool{}, skipped: map[string]int{}}
+    for _, k := range strings.Split(kinds, ",") {
+        k = strings.ToUpper(strings.TrimSpace(k))
+        if k == "" {
+            continue
+        }
+        errno, ok := Kinds[k]
+        if !ok {
+            names := make([]string, 0, len(Kinds))
+            for name := range Kinds {
+                names = append(names, name)
+            }
+            sort.Strings(names)
+            return nil, fmt.Errorf("unknown error kind %q (want %s)", k, strings.Join(names, ", "))
+        }
+        p.kinds = append(p.kinds, errno)
+    }
+    if len(p.kinds) == 0 {
+        return nil, errors.New("no error kinds given")
+    }
+    return p, nil
+}
+
+// Down reports whether ip has been marked down.
+func (p *Policy) Down(ip string) bool {
+    if p == nil {
+        return false
+    }
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return p.down[ip]
+}
+
+// Observe records the outcome of one probe attempt and reports whether it
+// just marked the host down. Any outcome other than a matching error
+// resets the host's streak.
+func (p *Policy) Observe(r scanner.Result) bool {
+    if p == nil {
+        return false
+    }
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if p.down[r.IP] {
+        return false
+    }
+    if r.Status != scanner.Error || !p.matches(r.Err) {
+        delete(p.streak, r.IP)
+        return false
+    }
+    p.streak[r.IP]++
+    if p.streak[r.IP] < p.after {
+        return false
+    }
+    delete(p.streak, r.IP)
+    p.down[r.IP] = true
+    return true
+}
+
+func (p *Policy) matches(err error) bool {
+    for _, k := range p.kinds {
+        if errors.Is(err, k) {
+            return true
+        }
+    }
+    return false
+}
+
+// Fail returns the fast-failed result recorded for t on a down host.
+func (p *Policy) Fail(t models.ScanTarget, proto string) scanner.Result {
+    p.mu.Lock()
+    p.skipped[t.IP]++
+    p.mu.Unlock()
+    return scanner.Result{IP: t.IP, Port: t.Port, Proto: proto, Status: scanner.Error, Err: ErrHostDown}
+}
+
+// Summary lists each down host and how many targets it fast-failed.
+func (p *Policy) Summary() []string {
+    if p == nil {
+        return nil
+    }
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    hosts := make([]string, 0, len(p.down))
+    for ip := range p.down {
+        hosts = append(hosts, ip)
+    }
+    sort.Strings(hosts)
+    lines := make([]string, 0, len(hosts))
+    for _, ip := range hosts {
+        lines = append(lines, fmt.Sprintf("%s marked down; %d remaining targets fast-failed", ip, p.skipped[ip]))
+    }
+    return lines
+}
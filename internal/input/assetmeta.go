@@ -0,0 +1,138 @@
+// File: internal/input/assetmeta.go
+package input
+
+import (
+    "context"
+    "net"
+    "slices"
+    "strings"
+
+    "goscant/internal/config"
+    "goscant/internal/scanner"
+)
+
+// coreColumns are the fixed result columns; an input column of the same
+// name, or of a column another enricher fills, is carried as
+// "asset_<name>" instead.
+var coreColumns = map[string]bool{
+    "timestamp": true, "dst_ip": true, "addr_family": true, "dst_port": true, "proto": true,
+    "status": true, "latency_ms": true, "attempts": true, "confidence": true, "engine": true,
+}
+
+// AssetMeta carries the extra columns of an --ip CSV (asset owner,
+// environment, ticket, ...) onto the results of the addresses each row
+// names. As an enricher it fills Columns. The values are copied as they
+// are: --anonymize does not pseudonymize them.
+type AssetMeta struct {
+    Columns []string
+    ips     map[string][]string
+    nets    []netMeta
+}
+
+type netMeta struct {
+    n      *net.IPNet
+    values []string
+}
+
+// LoadAssetMeta reads the extra columns of cfg.IPInput when it is a CSV
+// file with a header. It returns nil when there is nothing to carry. When
+// --port reads the same file, its port column is not carried either.
+// reserved lists the columns the rest of the scan fills.
+func LoadAssetMeta(ctx context.Context, cfg *config.Config, reserved []string) (*AssetMeta, error) {
+    if !strings.HasSuffix(cfg.IPInput, ".csv") {
+        return nil, nil
+    }
+    header, idx, rows, err := readTable(cfg.IPInput, cfg.IPColumn, 0, looksLikeHost)
+    if err != nil || header == nil {
+        return nil, err
+    }
+    skip := map[int]bool{idx: true}
+    if cfg.PortInput == cfg.IPInput {
+        portIdx, _, err := columnIndex(cfg.IPInput, header, cfg.PortColumn, 1)
+        if err != nil {
+            return nil, err
+        }
+        skip[portIdx] = true
+    }
+
+    m := &AssetMeta{ips: map[string][]string{}}
+    var keep []int
+    for i, h := range header {
+        name := strings.ToLower(strings.Join(strings.Fields(h), "_"))
+        if skip[i] || name == "" {
+            continue
+        }
+        if coreColumns[name] || slices.Contains(reserved, name) {
+            name = "asset_" + name
+        }
+        m.Columns = append(m.Columns, name)
+        keep = append(keep, i)
+    }
+    if len(keep) == 0 {
+        return nil, nil
+    }
+
+    tokens := make([]string, len(rows))
+    for i, rec := range rows {
+        if idx < len(rec) {
+            tokens[i] = trimToken(rec[idx])
+        }
+    }
+    resolved := resolveHostnames(ctx, tokens)
+    for i, rec := range rows {
+        values := make([]string, len(keep))
+        for j, c := range keep {
+            if c < len(rec) {
+                values[j] = strings.TrimSpace(rec[c])
+            }
+        }
+        tok := tokens[i]
+        if _, n, err := net.ParseCIDR(tok); err == nil {
+            m.nets = append(m.nets, netMeta{n, values})
+            continue
+        }
+        addrs := resolved[tok]
+        if ip := net.ParseIP(tok); ip != nil {
+            addrs = []string{ip.String()}
+        }
+        for _, a := range addrs {
+            if _, dup := m.ips[a]; !dup {
+                m.ips[a] = values // the first row naming an address wins
+            }
+        }
+    }
+    return m, nil
+}
+
+// lookup returns the values for ip: its own row, else the row of the
+// most specific CIDR containing it.
+func (m *AssetMeta) lookup(ip string) []string {
+    addr, _, _ := strings.Cut(ip, "%")
+    if v, ok := m.ips[addr]; ok {
+        return v
+    }
+    parsed := net.ParseIP(addr)
+    var best []string
+    bestOnes := -1
+    for _, nm := range m.nets {
+        if ones, _ := nm.n.Mask.Size(); parsed != nil && ones > bestOnes && nm.n.Contains(parsed) {
+            best, bestOnes = nm.values, ones
+        }
+    }
+    return best
+}
+
+// Enrich copies the input row's extra columns onto r.
+func (m *AssetMeta) Enrich(r *scanner.Result) {
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    values := m.lookup(r.IP)
+    for i, c := range m.Columns {
+        if i < len(values) {
+            r.Meta[c] = values[i]
+        } else {
+            r.Meta[c] = ""
+        }
+    }
+}
@@ -0,0 +1,68 @@
+// File: internal/input/aws.go
+package input
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "slices"
+    "strings"
+
+    "goscant/internal/config"
+    "goscant/internal/ec2"
+    "goscant/internal/logger"
+)
+
+// awsTargets lists the addresses of the EC2 instances selected by the
+// --aws-* flags as a comma-separated target list, with each address
+// labelled by its instance. Only running instances are listed unless the
+// filters name instance-state-name themselves; public and IPv6 addresses
+// are included with --aws-public.
+func awsTargets(ctx context.Context, cfg *config.Config, log *logger.Logger) (string, HostNames, error) {
+    regions := cfg.AWSRegions
+    if regions == "" {
+        regions = os.Getenv("AWS_REGION")
+    }
+    if regions == "" {
+        regions = os.Getenv("AWS_DEFAULT_REGION")
+    }
+    if regions == "" {
+        return "", nil, errors.New("aws: no region; pass --aws-region or set AWS_REGION")
+    }
+    filters, err := ec2.ParseFilters(cfg.AWSFilter)
+    if err != nil {
+        return "", nil, err
+    }
+    if !slices.ContainsFunc(filters, func(f ec2.Filter) bool { return f.Name == "instance-state-name" }) {
+        filters = append(filters, ec2.Filter{Name: "instance-state-name", Values: []string{"running"}})
+    }
+
+    var ips []string
+    names := HostNames{}
+    for _, region := range strings.Split(regions, ",") {
+        region = strings.TrimSpace(region)
+        client, err := ec2.NewClient(ctx, region)
+        if err != nil {
+            return "", nil, err
+        }
+        instances, err := client.Instances(ctx, filters)
+        if err != nil {
+            return "", nil, err
+        }
+        for _, in := range instances {
+            addrs := in.Private
+            if cfg.AWSPublic {
+                addrs = append(append(addrs, in.Public...), in.IPv6...)
+            }
+            for _, ip := range addrs {
+                if len(names[ip]) == 0 {
+                    ips = append(ips, ip)
+                }
+                names[ip] = append(names[ip], in.Label())
+            }
+        }
+        log.Info(fmt.Sprintf("aws: %d instances in %s", len(instances), region))
+    }
+    return strings.Join(ips, ","), names, nil
+}
@@ -0,0 +1,141 @@
+// File: internal/input/braces.go
+package input
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// maxBraceExpand bounds the names one brace pattern may expand to.
+const maxBraceExpand = 65536
+
+// splitList splits a comma-separated target list, leaving commas inside
+// braces ("db{1,2}.example.com") to the pattern.
+func splitList(s string) []string {
+    var out []string
+    depth, start := 0, 0
+    for i, r := range s {
+        switch r {
+        case '{':
+            depth++
+        case '}':
+            if depth > 0 {
+                depth--
+            }
+        case ',':
+            if depth == 0 {
+                out = append(out, s[start:i])
+                start = i + 1
+            }
+        }
+    }
+    return append(out, s[start:])
+}
+
+// expandBraces expands shell-style brace patterns in a hostname, as fleet
+// inventories name hosts: "web{01..20}.prod" is web01 to web20 (zero
+// padding kept), "{a..c}" runs over letters and "{db,cache}1" lists
+// alternatives. Patterns may repeat and nest; a token without braces is
+// returned as is.
+func expandBraces(tok string) ([]string, error) {
+    open := strings.IndexByte(tok, '{')
+    if open < 0 {
+        return []string{tok}, nil
+    }
+    end, depth := -1, 0
+    for i := open; i < len(tok) && end < 0; i++ {
+        switch tok[i] {
+        case '{':
+            depth++
+        case '}':
+            if depth--; depth == 0 {
+                end = i
+            }
+        }
+    }
+    if end < 0 {
+        return nil, fmt.Errorf("%q: unbalanced brace", tok)
+    }
+    alts, err := braceAlternatives(tok[open+1 : end])
+    if err != nil {
+        return nil, fmt.Errorf("%q: %w", tok, err)
+    }
+    rest, err := expandBraces(tok[end+1:])
+    if err != nil {
+        return nil, err
+    }
+    var out []string
+    for _, a := range alts {
+        heads, err := expandBraces(tok[:open] + a)
+        if err != nil {
+            return nil, err
+        }
+        for _, h := range heads {
+            for _, r := range rest {
+                if len(out) == maxBraceExpand {
+                    return nil, fmt.Errorf("%q: expands to more than %d names", tok, maxBraceExpand)
+                }
+                out = append(out, h+r)
+            }
+        }
+    }
+    return out, nil
+}
+
+// braceAlternatives returns the choices of one brace body: a "lo..hi"
+// range or a comma-separated list.
+func braceAlternatives(body string) ([]string, error) {
+    if lo, hi, ok := strings.Cut(body, ".."); ok && !strings.ContainsAny(body, ",{") {
+        return braceRange(lo, hi)
+    }
+    alts := splitList(body)
+    if len(alts) < 2 {
+        return nil, fmt.Errorf("{%s}: want a lo..hi range or a comma-separated list", body)
+    }
+    return alts, nil
+}
+
+func braceRange(lo, hi string) ([]string, error) {
+    a, errA := strconv.Atoi(lo)
+    b, errB := strconv.Atoi(hi)
+    if errA == nil && errB == nil {
+        width := 0
+        if len(lo) > 1 && lo[0] == '0' || len(hi) > 1 && hi[0] == '0' {
+            width = max(len(lo), len(hi))
+        }
+        step := 1
+        if b < a {
+            step = -1
+        }
+        if n := (b-a)*step + 1; n > maxBraceExpand {
+            return nil, fmt.Errorf("{%s..%s}: more than %d values", lo, hi, maxBraceExpand)
+        }
+        var out []string
+        for i := a; ; i += step {
+            out = append(out, fmt.Sprintf("%0*d", width, i))
+            if i == b {
+                return out, nil
+            }
+        }
+    }
+    if len(lo) == 1 && len(hi) == 1 && isLetter(lo[0]) && isLetter(hi[0]) {
+        var out []string
+        for c := lo[0]; ; {
+            out = append(out, string(c))
+            if c == hi[0] {
+                return out, nil
+            }
+            if c < hi[0] {
+                c++
+            } else {
+                c--
+            }
+        }
+    }
+    return nil, fmt.Errorf("{%s..%s}: range ends must both be numbers or single letters", lo, hi)
+}
+
+func isLetter(c byte) bool {
+    return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
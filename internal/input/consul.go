@@ -0,0 +1,27 @@
+// File: internal/input/consul.go
+package input
+
+import (
+    "strings"
+
+    "goscant/internal/consul"
+    "goscant/internal/scanner"
+)
+
+// ConsulColumns lists the result fields filled in by ConsulServices.
+var ConsulColumns = []string{"consul_service"}
+
+// ConsulServices annotates results with the Consul services registered on
+// their address and port; an open port with an empty consul_service is
+// listening unregistered.
+type ConsulServices struct {
+    *consul.Catalog
+}
+
+// Enrich names the services registered on r's address and port.
+func (c ConsulServices) Enrich(r *scanner.Result) {
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    r.Meta["consul_service"] = strings.Join(c.Services(r.IP, r.Port), ";")
+}
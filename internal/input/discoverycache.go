@@ -0,0 +1,104 @@
+// File: internal/input/discoverycache.go
+package input
+
+import (
+    "bytes"
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io/fs"
+    "sort"
+    "time"
+
+    "goscant/internal/seal"
+)
+
+// discoveryCache remembers the discovery verdict for each host so later
+// runs can skip probing hosts checked within ttl. Hosts whose probes could
+// not run are never cached. A nil cache remembers nothing.
+type discoveryCache struct {
+    path  string
+    ttl   time.Duration // 0 keeps entries forever
+    hosts map[string]cacheEntry
+}
+
+type cacheEntry struct {
+    up      bool
+    checked time.Time
+}
+
+// loadDiscoveryCache reads the cache at path; a missing file is an empty
+// cache. Rows are "ip,status,checked" with status "up" or "down".
+func loadDiscoveryCache(path string, ttl time.Duration) (*discoveryCache, error) {
+    c := &discoveryCache{path: path, ttl: ttl, hosts: map[string]cacheEntry{}}
+    b, err := seal.ReadFile(path)
+    if errors.Is(err, fs.ErrNotExist) {
+        return c, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    recs, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    for i, rec := range recs {
+        if i == 0 && rec[0] == "ip" {
+            continue
+        }
+        if len(rec) != 3 || (rec[1] != "up" && rec[1] != "down") {
+            return nil, fmt.Errorf("%s: row %d is not ip,up|down,time", path, i+1)
+        }
+        checked, err := time.Parse(time.RFC3339, rec[2])
+        if err != nil {
+            return nil, fmt.Errorf("%s: row %d: %w", path, i+1, err)
+        }
+        c.hosts[rec[0]] = cacheEntry{up: rec[1] == "up", checked: checked}
+    }
+    return c, nil
+}
+
+// lookup returns the cached verdict for ip if there is a fresh one.
+func (c *discoveryCache) lookup(ip string) (up, ok bool) {
+    if c == nil {
+        return false, false
+    }
+    e, ok := c.hosts[ip]
+    if !ok || c.ttl > 0 && time.Since(e.checked) > c.ttl {
+        return false, false
+    }
+    return e.up, true
+}
+
+func (c *discoveryCache) record(ip string, up bool) {
+    if c != nil {
+        c.hosts[ip] = cacheEntry{up: up, checked: time.Now()}
+    }
+}
+
+// save rewrites the cache file, sealed if key is not nil.
+func (c *discoveryCache) save(key []byte) error {
+    if c == nil {
+        return nil
+    }
+    ips := make([]string, 0, len(c.hosts))
+    for ip := range c.hosts {
+        ips = append(ips, ip)
+    }
+    sort.Strings(ips)
+    var buf bytes.Buffer
+    w := csv.NewWriter(&buf)
+    w.Write([]string{"ip", "status", "checked"})
+    for _, ip := range ips {
+        e, status := c.hosts[ip], "down"
+        if e.up {
+            status = "up"
+        }
+        w.Write([]string{ip, status, e.checked.UTC().Format(time.RFC3339)})
+    }
+    w.Flush()
+    if err := w.Error(); err != nil {
+        return err
+    }
+    return seal.WriteFile(c.path, buf.Bytes(), key)
+}
@@ -0,0 +1,176 @@
+// File: internal/input/exclude.go
+package input
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "net"
+    "os"
+    "strings"
+)
+
+// exclusions are the out-of-scope addresses and networks removed from the
+// target set. A nil exclusions excludes nothing.
+type exclusions struct {
+    ips  map[string]bool
+    nets []*net.IPNet
+}
+
+// loadExclusions parses the comma-separated list and the file (one entry
+// per line, '#' comments) given to --exclude and --exclude-file. Entries
+// are IPs, CIDRs or hostnames; a hostname excludes every address it
+// resolves to, and one that does not resolve is an error, since a silent
+// miss would scan an out-of-scope host.
+func loadExclusions(ctx context.Context, list, file string) (*exclusions, error) {
+    tokens := []string{}
+    for _, p := range splitList(list) {
+        names, err := expandBraces(trimToken(p))
+        if err != nil {
+            return nil, fmt.Errorf("exclude: %w", err)
+        }
+        for _, n := range names {
+            if n != "" {
+                tokens = append(tokens, n)
+            }
+        }
+    }
+    if file != "" {
+        f, err := os.Open(file)
+        if err != nil {
+            return nil, err
+        }
+        defer f.Close()
+        in := bufio.NewScanner(f)
+        for in.Scan() {
+            line, _, _ := strings.Cut(in.Text(), "#")
+            for _, p := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+                tokens = append(tokens, trimToken(p))
+            }
+        }
+        if err := in.Err(); err != nil {
+            return nil, fmt.Errorf("%s: %w", file, err)
+        }
+    }
+    if len(tokens) == 0 {
+        return nil, nil
+    }
+
+    resolved := resolveHostnames(ctx, tokens)
+    ex := &exclusions{ips: map[string]bool{}}
+    for _, tok := range tokens {
+        if strings.Contains(tok, "/") {
+            _, n, err := net.ParseCIDR(tok)
+            if err != nil {
+                return nil, fmt.Errorf("exclude %q: %w", tok, err)
+            }
+            ex.nets = append(ex.nets, n)
+            continue
+        }
+        if ip := net.ParseIP(tok); ip != nil {
+            ex.ips[ip.String()] = true
+            continue
+        }
+        addrs := resolved[tok]
+        if len(addrs) == 0 {
+            return nil, fmt.Errorf("exclude %q: not an IP or CIDR and does not resolve", tok)
+        }
+        for _, a := range addrs {
+            ex.ips[a] = true
+        }
+    }
+    return ex, nil
+}
+
+// has reports whether ip is excluded; a zoned address matches without
+// its zone.
+func (ex *exclusions) has(ip string) bool {
+    if ex == nil {
+        return false
+    }
+    addr := unzoned(ip)
+    if ex.ips[addr] {
+        return true
+    }
+    parsed := net.ParseIP(addr)
+    for _, n := range ex.nets {
+        if parsed != nil && n.Contains(parsed) {
+            return true
+        }
+    }
+    return false
+}
+
+// unzoned strips the zone of a link-local address such as "fe80::1%eth0".
+func unzoned(ip string) string {
+    addr, _, _ := strings.Cut(ip, "%")
+    return addr
+}
+
+// selfAddrs returns the addresses of the local interfaces, which are left
+// out of the targets unless --allow-self is given: self-scans skew results
+// and can wedge raw-socket receive loops.
+func selfAddrs() (map[string]bool, error) {
+    addrs, err := net.InterfaceAddrs()
+    if err != nil {
+        return nil, fmt.Errorf("listing local addresses: %w", err)
+    }
+    self := map[string]bool{}
+    for _, a := range addrs {
+        if n, ok := a.(*net.IPNet); ok {
+            self[n.IP.String()] = true
+        }
+    }
+    return self, nil
+}
+
+// publicGuard refuses targets outside private address space (RFC 1918,
+// ULA fc00::/7, loopback and link-local) unless --allow-public is given,
+// so a typo such as 1.0.0.0/8 for 10.0.0.0/8 does not scan the internet.
+type publicGuard struct {
+    allow    bool
+    n        int
+    examples []string
+}
+
+// check records ip if it is public.
+func (g *publicGuard) check(ip string) {
+    if g.allow {
+        return
+    }
+    addr := net.ParseIP(unzoned(ip))
+    if addr == nil || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+        return
+    }
+    if g.n++; len(g.examples) < 3 {
+        g.examples = append(g.examples, ip)
+    }
+}
+
+// err reports the public targets seen, if any.
+func (g *publicGuard) err() error {
+    if g.n == 0 {
+        return nil
+    }
+    return fmt.Errorf("%d targets are public addresses (e.g. %s); check the input for typos or pass --allow-public", g.n, strings.Join(g.examples, ", "))
+}
+
+// reservedCIDRs are ranges no host answers on: "this network", multicast,
+// reserved and broadcast, documentation and benchmarking space. They are
+// dropped from expanded targets unless --keep-reserved is given.
+var reservedCIDRs = []string{
+    "0.0.0.0/8", "192.0.0.0/24", "192.0.2.0/24", "198.18.0.0/15", "198.51.100.0/24",
+    "203.0.113.0/24", "224.0.0.0/4", "240.0.0.0/4",
+    "::/128", "100::/64", "2001:db8::/32", "ff00::/8",
+}
+
+// reservedRanges returns reservedCIDRs as exclusions.
+func reservedRanges() *exclusions {
+    ex := &exclusions{}
+    for _, c := range reservedCIDRs {
+        if _, n, err := net.ParseCIDR(c); err == nil {
+            ex.nets = append(ex.nets, n)
+        }
+    }
+    return ex
+}
@@ -0,0 +1,48 @@
+// File: internal/input/k8s.go
+package input
+
+import (
+    "context"
+    "fmt"
+    "slices"
+
+    "goscant/internal/config"
+    "goscant/internal/k8s"
+    "goscant/internal/logger"
+)
+
+// k8sTargets lists the pod and service ports selected by the --k8s-*
+// flags. Each address is labelled with the objects behind it (target_host
+// "pod/ns/name"). Ports are kept as listedTarget decides.
+func k8sTargets(ctx context.Context, cfg *config.Config, log *logger.Logger) ([]ProbeTarget, HostNames, error) {
+    client, err := k8s.NewClient(cfg.K8sAPI)
+    if err != nil {
+        return nil, nil, err
+    }
+    eps, err := client.Endpoints(ctx, cfg.K8sNamespace, cfg.K8sSelector)
+    if err != nil {
+        return nil, nil, err
+    }
+    out := []ProbeTarget{}
+    names := HostNames{}
+    seen := map[ProbeTarget]bool{}
+    skipped := 0
+    for _, ep := range eps {
+        t, ok := listedTarget(cfg, ep.IP, ep.Port, ep.Proto)
+        if !ok {
+            skipped++
+            continue
+        }
+        if !slices.Contains(names[ep.IP], ep.Name) {
+            names[ep.IP] = append(names[ep.IP], ep.Name)
+        }
+        if !seen[t] {
+            seen[t] = true
+            out = append(out, t)
+        }
+    }
+    if skipped > 0 {
+        log.Warn(fmt.Sprintf("k8s: %d declared ports skipped: their protocol does not match --scan %s", skipped, cfg.ScanType))
+    }
+    return out, names, nil
+}
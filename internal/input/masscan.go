@@ -0,0 +1,82 @@
+// File: internal/input/masscan.go
+package input
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+
+    "goscant/internal/config"
+)
+
+// masscanRecord is one host entry of masscan's JSON output (-oJ, -oD).
+type masscanRecord struct {
+    IP    string `json:"ip"`
+    Ports []struct {
+        Port   int    `json:"port"`
+        Proto  string `json:"proto"`
+        Status string `json:"status"`
+    } `json:"ports"`
+}
+
+// masscanTargets reads the open ports of a masscan run, in its list (-oL)
+// or JSON (-oJ, -oD) format, so a connect scan can confirm findings that a
+// high-rate SYN scan may have got wrong. It also returns how many ports
+// were skipped for their protocol (see listedTarget).
+func masscanTargets(cfg *config.Config, path string) ([]ProbeTarget, int, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer f.Close()
+    out := []ProbeTarget{}
+    seen := map[ProbeTarget]bool{}
+    skipped := 0
+    add := func(ip string, port int, proto string) {
+        t, ok := listedTarget(cfg, ip, port, proto)
+        switch {
+        case !ok:
+            skipped++
+        case !seen[t]:
+            seen[t] = true
+            out = append(out, t)
+        }
+    }
+    in := bufio.NewScanner(f)
+    for line := 1; in.Scan(); line++ {
+        text := strings.TrimSpace(in.Text())
+        switch {
+        case text == "" || text[0] == '#' || text == "[" || text == "]":
+        case text[0] == '{':
+            // -oJ puts one record per line between commas; its closing
+            // "{finished: 1}" is not JSON and is skipped
+            var rec masscanRecord
+            if json.Unmarshal([]byte(strings.TrimSuffix(text, ",")), &rec) != nil {
+                continue
+            }
+            for _, p := range rec.Ports {
+                if p.Status == "open" {
+                    add(rec.IP, p.Port, p.Proto)
+                }
+            }
+        default:
+            // -oL: "open tcp 80 10.0.0.1 1700000000"; banner lines are skipped
+            fields := strings.Fields(text)
+            if len(fields) < 4 || fields[0] != "open" {
+                continue
+            }
+            port, err := strconv.Atoi(fields[2])
+            if err != nil {
+                return nil, 0, fmt.Errorf("%s:%d: bad port %q", path, line, fields[2])
+            }
+            add(fields[3], port, fields[1])
+        }
+    }
+    if err := in.Err(); err != nil {
+        return nil, 0, fmt.Errorf("%s: %w", path, err)
+    }
+    return out, skipped, nil
+}
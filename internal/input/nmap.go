@@ -0,0 +1,91 @@
+// File: internal/input/nmap.go
+package input
+
+import (
+    "encoding/xml"
+    "fmt"
+    "os"
+    "slices"
+
+    "goscant/internal/config"
+)
+
+// nmapRun holds the fields read from an nmap XML report (-oX).
+type nmapRun struct {
+    Hosts []struct {
+        Status struct {
+            State string `xml:"state,attr"`
+        } `xml:"status"`
+        Addresses []struct {
+            Addr string `xml:"addr,attr"`
+            Type string `xml:"addrtype,attr"`
+        } `xml:"address"`
+        Ports []struct {
+            Proto string `xml:"protocol,attr"`
+            Num   int    `xml:"portid,attr"`
+            State struct {
+                State string `xml:"state,attr"`
+            } `xml:"state"`
+        } `xml:"ports>port"`
+    } `xml:"host"`
+}
+
+// readNmapXML returns the addresses of the hosts an nmap run found up and,
+// per address, the ports it reported open (proto "tcp", "udp" or "sctp").
+// Merged reports may list a host or port twice; each is returned once.
+func readNmapXML(path string) ([]string, map[string][]Port, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer f.Close()
+    var run nmapRun
+    if err := xml.NewDecoder(f).Decode(&run); err != nil {
+        return nil, nil, fmt.Errorf("%s: %w", path, err)
+    }
+    var hosts []string
+    open := map[string][]Port{}
+    for _, h := range run.Hosts {
+        if h.Status.State != "up" {
+            continue
+        }
+        for _, a := range h.Addresses {
+            if a.Type != "ipv4" && a.Type != "ipv6" {
+                continue // mac
+            }
+            if _, seen := open[a.Addr]; !seen {
+                hosts = append(hosts, a.Addr)
+                open[a.Addr] = nil
+            }
+            for _, p := range h.Ports {
+                port := Port{Num: p.Num, Proto: p.Proto}
+                if p.State.State == "open" && !slices.Contains(open[a.Addr], port) {
+                    open[a.Addr] = append(open[a.Addr], port)
+                }
+            }
+        }
+    }
+    return hosts, open, nil
+}
+
+// nmapTargets returns the ports an nmap run reported open as targets, and
+// how many were skipped for their protocol (see listedTarget).
+func nmapTargets(cfg *config.Config, path string) ([]ProbeTarget, int, error) {
+    hosts, open, err := readNmapXML(path)
+    if err != nil {
+        return nil, 0, err
+    }
+    out := []ProbeTarget{}
+    skipped := 0
+    for _, ip := range hosts {
+        for _, p := range open[ip] {
+            t, ok := listedTarget(cfg, ip, p.Num, p.Proto)
+            if !ok {
+                skipped++
+                continue
+            }
+            out = append(out, t)
+        }
+    }
+    return out, skipped, nil
+}
@@ -0,0 +1,107 @@
+// File: internal/input/sample.go
+package input
+
+import (
+    "fmt"
+    "math/rand"
+    "net"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// hostSample keeps a uniformly random subset of the addresses fed to it,
+// either exactly n of them (reservoir sampling) or each one with a fixed
+// probability, without holding the full expansion in memory.
+type hostSample struct {
+    n    int     // reservoir size; 0 when sampling by fraction
+    frac float64 // keep probability when n is 0
+    rng  *rand.Rand
+    seed int64
+    seen int
+    kept []sampled
+}
+
+type sampled struct {
+    idx int // position in the expansion, to restore input order
+    ip  string
+}
+
+// parseSample parses --sample: a host count ("5000") or a percentage
+// ("0.5%"). The seed makes the subset reproducible; 0 picks one.
+func parseSample(spec string, seed int64) (*hostSample, error) {
+    if seed == 0 {
+        seed = time.Now().UnixNano()
+    }
+    s := &hostSample{rng: rand.New(rand.NewSource(seed)), seed: seed}
+    if pct, ok := strings.CutSuffix(strings.TrimSpace(spec), "%"); ok {
+        v, err := strconv.ParseFloat(pct, 64)
+        if err != nil || v <= 0 || v > 100 {
+            return nil, fmt.Errorf("--sample %q: want a host count or a percentage in (0, 100]", spec)
+        }
+        s.frac = v / 100
+        return s, nil
+    }
+    n, err := strconv.Atoi(strings.TrimSpace(spec))
+    if err != nil || n <= 0 {
+        return nil, fmt.Errorf("--sample %q: want a host count or a percentage in (0, 100]", spec)
+    }
+    s.n = n
+    return s, nil
+}
+
+func (s *hostSample) add(ip string) {
+    i := s.seen
+    s.seen++
+    switch {
+    case s.n == 0:
+        if s.rng.Float64() < s.frac {
+            s.kept = append(s.kept, sampled{i, ip})
+        }
+    case len(s.kept) < s.n:
+        s.kept = append(s.kept, sampled{i, ip})
+    default:
+        if j := s.rng.Intn(i + 1); j < s.n {
+            s.kept[j] = sampled{i, ip}
+        }
+    }
+}
+
+// hosts returns the kept addresses in input order.
+func (s *hostSample) hosts() []string {
+    sort.Slice(s.kept, func(a, b int) bool { return s.kept[a].idx < s.kept[b].idx })
+    out := make([]string, len(s.kept))
+    for i, k := range s.kept {
+        out[i] = k.ip
+    }
+    return out
+}
+
+// shuffleHosts permutes ips in place so probes spread over subnets instead
+// of walking one range in order, and returns the seed used (picked when
+// seed is 0) so the order can be replayed.
+func shuffleHosts(ips []string, seed int64) int64 {
+    if seed == 0 {
+        seed = time.Now().UnixNano()
+    }
+    rand.New(rand.NewSource(seed)).Shuffle(len(ips), func(i, j int) { ips[i], ips[j] = ips[j], ips[i] })
+    return seed
+}
+
+// randomAddrs emits n distinct addresses drawn uniformly from ipnet, which
+// must hold more than n.
+func randomAddrs(ipnet *net.IPNet, n int, rng *rand.Rand, emit func(string)) {
+    seen := make(map[string]bool, n)
+    buf := make(net.IP, len(ipnet.IP))
+    for len(seen) < n {
+        rng.Read(buf)
+        for i := range buf {
+            buf[i] = ipnet.IP[i] | buf[i]&^ipnet.Mask[i]
+        }
+        if s := buf.String(); !seen[s] {
+            seen[s] = true
+            emit(s)
+        }
+    }
+}
@@ -0,0 +1,37 @@
+// File: internal/input/services.go
+package input
+
+import (
+    "context"
+    "net"
+    "strings"
+)
+
+// serviceNames resolves the names people most often type for a port,
+// including common ones /etc/services lacks or spells differently.
+var serviceNames = map[string]int{
+    "ftp-data": 20, "ftp": 21, "ssh": 22, "telnet": 23, "smtp": 25, "dns": 53, "domain": 53,
+    "tftp": 69, "http": 80, "kerberos": 88, "pop3": 110, "rpcbind": 111, "sunrpc": 111, "ntp": 123,
+    "msrpc": 135, "netbios-ns": 137, "netbios-ssn": 139, "imap": 143, "snmp": 161, "snmptrap": 162,
+    "bgp": 179, "ldap": 389, "https": 443, "smb": 445, "microsoft-ds": 445, "smtps": 465,
+    "syslog": 514, "submission": 587, "ipp": 631, "ldaps": 636, "rsync": 873, "imaps": 993,
+    "pop3s": 995, "socks": 1080, "mssql": 1433, "oracle": 1521, "pptp": 1723, "mqtt": 1883,
+    "nfs": 2049, "docker": 2375, "mysql": 3306, "rdp": 3389, "ms-wbt-server": 3389,
+    "postgres": 5432, "postgresql": 5432, "sip": 5060, "amqp": 5672, "vnc": 5900, "winrm": 5985,
+    "redis": 6379, "irc": 6667, "http-alt": 8080, "https-alt": 8443, "elasticsearch": 9200,
+    "memcached": 11211, "mongodb": 27017,
+}
+
+// lookupService returns the port of a service name, from the table above
+// or else the system's services database (/etc/services) for proto.
+func lookupService(name, proto string) (int, bool) {
+    name = strings.ToLower(name)
+    if p, ok := serviceNames[name]; ok {
+        return p, true
+    }
+    if proto == "" {
+        proto = "tcp"
+    }
+    p, err := net.DefaultResolver.LookupPort(context.Background(), proto, name)
+    return p, err == nil
+}
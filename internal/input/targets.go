@@ -2,103 +2,689 @@
 package input
 
 import (
-    "bufio"
     "context"
     "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
     "net"
     "os"
+    "slices"
     "strconv"
     "strings"
+    "sync"
+    "unicode"
 
     "goscant/internal/config"
+    "goscant/internal/logger"
+    "goscant/internal/models"
     "goscant/internal/ping"
+    "goscant/internal/results"
+    "goscant/internal/scanner"
+    "goscant/internal/seal"
 )
 
 // ProbeTarget represents a single IP+port tuple.
-type ProbeTarget struct {
-    IP   string
-    Port int
-}
+type ProbeTarget = models.ScanTarget
 
-// ParseTargets returns slice of targets after ping filtering.
-func ParseTargets(ctx context.Context, cfg *config.Config) ([]ProbeTarget, error) {
+// ParseTargets returns the targets left after exclusions and ping
+// filtering, the hosts the filter dropped, and the input hostnames each
+// address came from. confirm is given the number of targets before any
+// host is pinged; an error from it aborts.
+func ParseTargets(ctx context.Context, cfg *config.Config, log *logger.Logger, confirm func(targets int) error) (*Targets, []string, HostNames, error) {
+    ex, err := loadExclusions(ctx, cfg.Exclude, cfg.ExcludeFile)
+    if err != nil {
+        return nil, nil, nil, err
+    }
+    skipPorts := map[int]bool{}
+    if cfg.ExcludePorts != "" {
+        excluded, err := ParsePorts(cfg.ExcludePorts)
+        if err != nil {
+            return nil, nil, nil, err
+        }
+        for _, p := range excluded {
+            skipPorts[p] = true
+        }
+    }
+    self := map[string]bool{}
+    if !cfg.AllowSelf {
+        if self, err = selfAddrs(); err != nil {
+            return nil, nil, nil, err
+        }
+    }
+    public := &publicGuard{allow: cfg.AllowPublic}
+    // earlier results and cluster listings name their own ports
+    consulList := cfg.Consul != nil && cfg.PortInput == "" && cfg.TopPorts == 0
+    if cfg.ResumeFile == "" && (cfg.Rescan != "" || cfg.Masscan != "" || cfg.TargetsFrom == "k8s" || consulList || cfg.NmapPorts) {
+        var targets []ProbeTarget
+        var names HostNames
+        source := cfg.Rescan
+        switch {
+        case cfg.TargetsFrom == "k8s":
+            targets, names, err = k8sTargets(ctx, cfg, log)
+            source = "the cluster"
+        case consulList:
+            for _, ep := range cfg.Consul.Endpoints {
+                targets = append(targets, ProbeTarget{IP: ep.IP, Port: ep.Port})
+            }
+            source = "the Consul catalog"
+        case cfg.NmapPorts:
+            var skipped int
+            if targets, skipped, err = nmapTargets(cfg, cfg.IPInput); skipped > 0 {
+                log.Warn(fmt.Sprintf("nmap: %d open ports skipped: their protocol does not match --scan %s", skipped, cfg.ScanType))
+            }
+            source = cfg.IPInput
+        case cfg.Masscan != "":
+            var skipped int
+            if targets, skipped, err = masscanTargets(cfg, cfg.Masscan); skipped > 0 {
+                log.Warn(fmt.Sprintf("masscan: %d open ports skipped: their protocol does not match --scan %s", skipped, cfg.ScanType))
+            }
+            source = cfg.Masscan
+        default:
+            targets, err = rescanTargets(cfg.Rescan)
+        }
+        if err != nil {
+            return nil, nil, nil, err
+        }
+        kept := targets[:0]
+        for _, t := range targets {
+            if !ex.has(t.IP) && !self[unzoned(t.IP)] && !skipPorts[t.Port] {
+                public.check(t.IP)
+                kept = append(kept, t)
+            }
+        }
+        if err := public.err(); err != nil {
+            return nil, nil, nil, err
+        }
+        kept = dedupeTargets(kept, log)
+        log.Info(fmt.Sprintf("targets: %d ports from %s", len(kept), source))
+        if err := confirm(len(kept)); err != nil {
+            return nil, nil, nil, err
+        }
+        if cfg.Order == "host" {
+            return ListTargets(kept).ByHost(), nil, names, nil
+        }
+        return ListTargets(kept), nil, names, nil
+    }
     if cfg.ResumeFile != "" {
-        return loadCheckpoint(cfg.ResumeFile)
+        targets, err := loadCheckpoint(cfg.ResumeFile)
+        kept := targets[:0]
+        for _, t := range targets {
+            if !ex.has(t.IP) && !self[unzoned(t.IP)] && !skipPorts[t.Port] {
+                public.check(t.IP)
+                kept = append(kept, t)
+            }
+        }
+        if err := public.err(); err != nil {
+            return nil, nil, nil, err
+        }
+        if n := len(targets) - len(kept); n > 0 {
+            log.Info(fmt.Sprintf("exclude: %d checkpoint targets removed", n))
+        }
+        if err != nil {
+            return nil, nil, nil, err
+        }
+        kept = dedupeTargets(kept, log)
+        if err := confirm(len(kept)); err != nil {
+            return nil, nil, nil, err
+        }
+        if cfg.Order == "host" {
+            return ListTargets(kept).ByHost(), nil, nil, nil
+        }
+        return ListTargets(kept), nil, nil, nil
     }
 
-    ips, err := parseIPs(cfg.IPInput)
+    // exclusions apply before sampling, so the sample is all in scope
+    var sample *hostSample
+    if cfg.Sample != "" {
+        if sample, err = parseSample(cfg.Sample, cfg.SampleSeed); err != nil {
+            return nil, nil, nil, err
+        }
+    }
+    var reservedSpace *exclusions
+    if !cfg.KeepReserved {
+        reservedSpace = reservedRanges()
+    }
+    ipInput := cfg.IPInput
+    var cloudNames HostNames
+    switch {
+    case cfg.TargetsFrom == "aws":
+        if ipInput, cloudNames, err = awsTargets(ctx, cfg, log); err != nil {
+            return nil, nil, nil, err
+        }
+    case cfg.Consul != nil:
+        // every port of --port on the registered nodes; consul_service
+        // tells registered ports from unregistered listeners
+        ipInput = strings.Join(cfg.Consul.Nodes(), ",")
+    }
+    total, excluded, selfSkipped, bogons := 0, 0, 0, 0
+    ips, names, err := parseIPs(ctx, ipInput, cfg.IPColumn, &expansion{
+        keep: func(ip string) bool {
+            total++
+            if ex.has(ip) {
+                excluded++
+                return false
+            }
+            if self[unzoned(ip)] {
+                selfSkipped++
+                return false
+            }
+            if reservedSpace.has(ip) {
+                bogons++
+                return false
+            }
+            public.check(ip)
+            return true
+        },
+        sample:      sample,
+        v6MinPrefix: cfg.V6MinPrefix,
+        v6All:       cfg.V6ExpandAll,
+        skipEdges:   cfg.SkipNetBcast,
+    })
+    if err == nil {
+        err = public.err()
+    }
     if err != nil {
-        return nil, err
+        return nil, nil, nil, err
+    }
+    for ip, labels := range cloudNames {
+        names[ip] = append(names[ip], labels...)
     }
-    ports, err := parsePorts(cfg.PortInput)
+    if ex != nil {
+        log.Info(fmt.Sprintf("exclude: %d of %d addresses removed as out of scope", excluded, total))
+    }
+    if bogons > 0 {
+        log.Info(fmt.Sprintf("reserved: %d multicast, reserved or documentation addresses skipped (--keep-reserved scans them)", bogons))
+    }
+    if selfSkipped > 0 {
+        log.Info(fmt.Sprintf("self: %d of the scanner's own addresses skipped (--allow-self scans them)", selfSkipped))
+    }
+    if sample != nil {
+        log.Info(fmt.Sprintf("sample: scanning %d of %d hosts chosen at random (--sample-seed %d)", len(ips), sample.seen, sample.seed))
+    }
+    // overlapping CIDRs, files and lists may name an address twice
+    listedIPs := len(ips)
+    ips = dedupeHosts(ips)
+    parsed, err := ParsePortSpecColumn(cfg.PortInput, cfg.PortColumn)
     if err != nil {
-        return nil, err
+        return nil, nil, nil, err
+    }
+    if cfg.TopPorts > 0 {
+        top, err := TopPorts(cfg.TopPorts)
+        if err != nil {
+            return nil, nil, nil, err
+        }
+        listed := map[int]bool{}
+        for _, p := range parsed {
+            if p.Proto != "udp" {
+                listed[p.Num] = true
+            }
+        }
+        for _, p := range top {
+            if !listed[p.Num] {
+                parsed = append(parsed, p)
+            }
+        }
+    }
+    ports := make([]Port, 0, len(parsed))
+    seenPorts := map[Port]bool{}
+    dupPorts := 0
+    for _, p := range parsed {
+        if p.Proto == "udp" && (cfg.ScanType != "tcp" || cfg.Stateless || cfg.Via != "") {
+            return nil, nil, nil, errors.New("U: ports need --scan tcp without --stateless or --via")
+        }
+        if skipPorts[p.Num] {
+            continue
+        }
+        key := Port{Num: p.Num}
+        if p.Proto == "udp" {
+            key.Proto = "udp"
+        }
+        if seenPorts[key] {
+            dupPorts++
+            continue
+        }
+        seenPorts[key] = true
+        ports = append(ports, p)
+    }
+    if len(skipPorts) > 0 {
+        log.Info(fmt.Sprintf("exclude: %d of %d ports removed", len(parsed)-len(ports)-dupPorts, len(parsed)))
+    }
+    if dupIPs := listedIPs - len(ips); dupIPs > 0 || dupPorts > 0 {
+        dropped := listedIPs*(len(ports)+dupPorts) - len(ips)*len(ports)
+        log.Info(fmt.Sprintf("dedupe: %d duplicate targets dropped (%d addresses and %d ports listed more than once)", dropped, dupIPs, dupPorts))
     }
 
-    // ping filter
+    // ask before discovery, so a mistyped range is not swept first
+    if err := confirm(len(ips) * len(ports)); err != nil {
+        return nil, nil, nil, err
+    }
+
+    // ping filter; hosts that could not be pinged at all (e.g. no raw
+    // socket privileges) are kept rather than silently dropped
+    methods, err := ping.ParseMethods(cfg.PingMethods)
+    if err != nil {
+        return nil, nil, nil, err
+    }
+    var cache *discoveryCache
+    if cfg.DiscoveryCache != "" {
+        if cache, err = loadDiscoveryCache(cfg.DiscoveryCache, cfg.DiscoveryCacheTTL); err != nil {
+            return nil, nil, nil, err
+        }
+    }
     reachable := make([]string, 0, len(ips))
+    var down []string
+    cached := 0
+    answered := map[string]int{} // probe -> hosts it found up
+    failed := map[string]error{} // probe -> why it could not run
+    var order []string
     for _, ip := range ips {
-        ok, _ := ping.Ping(ctx, ip, cfg.Timeout)
-        if ok {
+        if cfg.Via != "" {
+            // only the jump host can reach them; probes from here prove nothing
+            reachable = append(reachable, ip)
+            continue
+        }
+        if strings.Contains(ip, "%") {
+            // zoned link-local hosts were found by link discovery, so are up
             reachable = append(reachable, ip)
+            continue
+        }
+        if up, ok := cache.lookup(ip); ok {
+            if up {
+                reachable = append(reachable, ip)
+            } else {
+                down = append(down, ip)
+            }
+            cached++
+            continue
+        }
+        // a host is only dropped once every round has gone unanswered
+        var answers []ping.Answer
+        round := 0
+        for ; round <= cfg.PingRetry && ctx.Err() == nil; round++ {
+            answers = ping.Discover(ctx, ip, methods, cfg.Timeout, cfg.PingCount)
+            if ok, err := ping.Up(answers); ok || err != nil {
+                break
+            }
+        }
+        line := []string{}
+        for _, a := range answers {
+            if _, ok := answered[a.Probe]; !ok {
+                answered[a.Probe] = 0
+                order = append(order, a.Probe)
+            }
+            switch {
+            case a.Up:
+                answered[a.Probe]++
+                line = append(line, a.Probe+"=up")
+            case a.Err != nil:
+                failed[a.Probe] = a.Err
+                line = append(line, a.Probe+"=error")
+            default:
+                line = append(line, a.Probe+"=none")
+            }
+        }
+        log.Debugf("discovery %s: %s (round %d)", ip, strings.Join(line, " "), min(round+1, cfg.PingRetry+1))
+        ok, err := ping.Up(answers)
+        if ok || err != nil {
+            reachable = append(reachable, ip)
+        } else if ctx.Err() == nil {
+            down = append(down, ip)
+        }
+        if err == nil && ctx.Err() == nil {
+            cache.record(ip, ok)
+        }
+    }
+    if cache != nil {
+        log.Info(fmt.Sprintf("discovery: %d hosts answered from cache %s", cached, cfg.DiscoveryCache))
+        if err := cache.save(cfg.EncryptKey); err != nil {
+            log.Warn("discovery: cannot save cache: " + err.Error())
+        }
+    }
+    summary := make([]string, 0, len(order))
+    for _, p := range order {
+        summary = append(summary, fmt.Sprintf("%s=%d", p, answered[p]))
+    }
+    log.Info(fmt.Sprintf("discovery: %d of %d hosts kept; up by probe: %s", len(reachable), len(ips), strings.Join(summary, " ")))
+    for _, p := range order {
+        if err, ok := failed[p]; ok {
+            log.Warn(fmt.Sprintf("discovery: probe %s could not run for some hosts: %v", p, err))
         }
     }
 
-    targets := make([]ProbeTarget, 0, len(reachable)*len(ports))
+    probes := make([]ProbeTarget, 0, len(ports))
     for _, port := range ports {
-        for _, ip := range reachable {
-            targets = append(targets, ProbeTarget{IP: ip, Port: port})
+        proto := ""
+        if port.Proto == "udp" {
+            proto = "udp"
         }
+        probes = append(probes, ProbeTarget{Port: port.Num, Proto: proto})
+    }
+    if cfg.Randomize {
+        seed := shuffleHosts(reachable, cfg.RandomSeed)
+        log.Info(fmt.Sprintf("randomize: host order shuffled (--randomize-hosts=%d repeats it)", seed))
+    }
+    targets := NewTargets(reachable, probes)
+    if cfg.Order == "host" {
+        targets = targets.ByHost()
     }
-    return targets, nil
+    return targets, down, names, nil
 }
 
-// parseIPs handles IPv4/CIDR/hostname or CSV file.
-func parseIPs(arg string) ([]string, error) {
-    if strings.HasSuffix(arg, ".csv") {
-        f, err := os.Open(arg)
-        if err != nil { return nil, err }
-        defer f.Close()
-        r := csv.NewReader(f)
-        _ , _ = r.Read() // skip header
-        out := []string{}
-        for {
-            rec, err := r.Read()
-            if err != nil { break }
-            cidr := strings.TrimSpace(rec[0])
-            ips, _ := cidrExpand(cidr)
-            out = append(out, ips...)
+// listedTarget makes a target of a port listed with its protocol ("tcp",
+// "udp", "sctp") by an inventory. UDP ports are probed over UDP alongside a
+// TCP scan; other ports only when --scan matches their protocol.
+func listedTarget(cfg *config.Config, ip string, port int, proto string) (ProbeTarget, bool) {
+    t := ProbeTarget{IP: ip, Port: port}
+    switch {
+    case proto == "udp" && cfg.ScanType == "tcp" && !cfg.Stateless && cfg.Via == "":
+        t.Proto = "udp"
+    case proto == cfg.ScanType:
+    default:
+        return t, false
+    }
+    return t, true
+}
+
+// dedupeHosts drops repeated addresses, keeping the first occurrence.
+func dedupeHosts(ips []string) []string {
+    seen := make(map[string]bool, len(ips))
+    out := ips[:0]
+    for _, ip := range ips {
+        if !seen[ip] {
+            seen[ip] = true
+            out = append(out, ip)
         }
-        return out, nil
     }
-    // simple list separated by comma
-    parts := strings.Split(arg, ",")
-    out := []string{}
-    for _, p := range parts {
-        p = strings.TrimSpace(p)
-        ips, _ := cidrExpand(p)
-        out = append(out, ips...)
+    return out
+}
+
+// dedupeTargets drops repeated targets of a listed source, keeping the
+// first occurrence: merged reports and checkpoints may name a port twice.
+func dedupeTargets(ts []ProbeTarget, log *logger.Logger) []ProbeTarget {
+    seen := make(map[ProbeTarget]bool, len(ts))
+    out := ts[:0]
+    for _, t := range ts {
+        if !seen[t] {
+            seen[t] = true
+            out = append(out, t)
+        }
+    }
+    if n := len(ts) - len(out); n > 0 {
+        log.Info(fmt.Sprintf("dedupe: %d duplicate targets dropped", n))
+    }
+    return out
+}
+
+// rescanTargets returns the open ports of an earlier run (CSV, JSON lines
+// or a partitioned output directory), for verifying remediation without
+// repeating discovery. UDP rows are probed over UDP; the rest use --scan.
+func rescanTargets(path string) ([]ProbeTarget, error) {
+    prev, err := results.Read(path)
+    if err != nil {
+        return nil, err
+    }
+    out := []ProbeTarget{}
+    seen := map[ProbeTarget]bool{}
+    for _, r := range prev {
+        if r.Status != scanner.Open {
+            continue
+        }
+        t := ProbeTarget{IP: r.IP, Port: r.Port}
+        if r.Proto == "udp" {
+            t.Proto = "udp"
+        }
+        if !seen[t] {
+            seen[t] = true
+            out = append(out, t)
+        }
     }
     return out, nil
 }
 
-func cidrExpand(val string) ([]string, error) {
+// ParseIPs handles IPv4/IPv6/CIDR/hostname lists or a CSV file. A
+// "link:IFACE" token expands to the IPv6 hosts found on that interface's
+// link by multicast discovery, as zoned link-local addresses.
+func ParseIPs(ctx context.Context, arg string) ([]string, error) {
+    return ParseIPsColumn(ctx, arg, "")
+}
+
+// ParseIPsColumn is ParseIPs reading addresses from column of a CSV file
+// (see readColumn); column defaults to the first.
+func ParseIPsColumn(ctx context.Context, arg, column string) ([]string, error) {
+    out, _, err := parseIPs(ctx, arg, column, nil)
+    return out, err
+}
+
+// HostNames maps each address resolved from a hostname token to the
+// hostnames that produced it. As an enricher it fills the target_host
+// column, so every address of a round-robin name traces back to it.
+type HostNames map[string][]string
+
+// HostColumns lists the result fields filled in by HostNames.
+var HostColumns = []string{"target_host"}
+
+// Enrich annotates r with the input hostnames of its address, if any.
+func (h HostNames) Enrich(r *scanner.Result) {
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    r.Meta["target_host"] = strings.Join(h[r.IP], ";")
+}
+
+// expansion controls how parseIPs turns tokens into addresses. A nil
+// expansion keeps everything and applies the default IPv6 bound.
+type expansion struct {
+    keep        func(string) bool // drops addresses it rejects, if set
+    sample      *hostSample       // sees every kept address, if set
+    v6MinPrefix int               // shortest IPv6 prefix expanded in full; 0 means maxV6Expand
+    v6All       bool              // expand IPv6 prefixes of any length
+    skipEdges   bool              // leave out IPv4 network and broadcast addresses
+}
+
+// parseIPs is ParseIPsColumn also returning the hostnames behind resolved
+// addresses. Every A and AAAA record of a hostname is kept; an address
+// several names share is scanned once. Addresses go through x's filter and
+// sample as they are expanded, so a sampled /8 is never held in memory
+// whole.
+func parseIPs(ctx context.Context, arg, column string, x *expansion) ([]string, HostNames, error) {
+    if x == nil {
+        x = &expansion{}
+    }
+    keep, sample := x.keep, x.sample
+    raw := []string{}
+    switch {
+    case strings.HasSuffix(arg, ".csv"):
+        cells, err := readColumn(arg, column, 0, looksLikeHost)
+        if err != nil {
+            return nil, nil, err
+        }
+        raw = cells
+    case strings.HasSuffix(arg, ".xml"):
+        // hosts an nmap run found up
+        hosts, _, err := readNmapXML(arg)
+        if err != nil {
+            return nil, nil, err
+        }
+        raw = hosts
+    default:
+        // simple list separated by comma
+        raw = splitList(arg)
+    }
+    // brace patterns expand before resolution
+    tokens := []string{}
+    for _, p := range raw {
+        expanded, err := expandBraces(trimToken(p))
+        if err != nil {
+            return nil, nil, err
+        }
+        tokens = append(tokens, expanded...)
+    }
+
+    resolved := resolveHostnames(ctx, tokens)
+    out := []string{}
+    names := HostNames{}
+    emit := func(ip string) {
+        switch {
+        case keep != nil && !keep(ip):
+        case sample != nil:
+            sample.add(ip)
+        default:
+            out = append(out, ip)
+        }
+    }
+    for _, tok := range tokens {
+        if ifname, ok := strings.CutPrefix(tok, ping.LinkPrefix); ok {
+            neighbors, err := ping.Neighbors(ctx, ifname)
+            if err != nil {
+                return nil, nil, fmt.Errorf("%s: %w", tok, err)
+            }
+            for _, n := range neighbors {
+                emit(n.Addr)
+            }
+            continue
+        }
+        if addrs, ok := resolved[tok]; ok {
+            for _, ip := range addrs {
+                if !slices.Contains(names[ip], tok) {
+                    if len(names[ip]) == 0 {
+                        emit(ip)
+                    }
+                    names[ip] = append(names[ip], tok)
+                }
+            }
+            continue
+        }
+        if err := cidrEach(tok, x, emit); err != nil {
+            return nil, nil, err
+        }
+    }
+    if sample != nil {
+        out = sample.hosts()
+    }
+    return out, names, nil
+}
+
+// trimToken strips whitespace and the brackets of "[v6addr]" literals.
+func trimToken(tok string) string {
+    tok = strings.TrimSpace(tok)
+    if strings.HasPrefix(tok, "[") && strings.HasSuffix(tok, "]") {
+        tok = tok[1 : len(tok)-1]
+    }
+    return tok
+}
+
+// resolverWorkers bounds concurrent DNS lookups during pre-resolution.
+const resolverWorkers = 16
+
+// resolveHostnames looks up every hostname token concurrently before any
+// probing starts, so DNS latency is not charged to the scan itself.
+func resolveHostnames(ctx context.Context, tokens []string) map[string][]string {
+    hosts := make(chan string)
+    out := map[string][]string{}
+    mu := sync.Mutex{}
+    done := resolverPool(resolverWorkers, hosts, func(h string) {
+        addrs, _ := net.DefaultResolver.LookupHost(ctx, h)
+        mu.Lock()
+        out[h] = addrs
+        mu.Unlock()
+    })
+    seen := map[string]bool{}
+    for _, tok := range tokens {
+        if tok == "" || seen[tok] || strings.Contains(tok, "/") || strings.HasPrefix(tok, ping.LinkPrefix) || net.ParseIP(tok) != nil {
+            continue
+        }
+        seen[tok] = true
+        hosts <- tok
+    }
+    close(hosts)
+    done()
+    return out
+}
+
+// ReverseLookup resolves PTR names for every address read from ips using
+// workers concurrent lookups, calling emit (concurrently) with each answer.
+// It returns once ips is closed and all lookups have finished.
+func ReverseLookup(ctx context.Context, ips <-chan string, workers int, emit func(ip string, names []string, err error)) {
+    resolverPool(workers, ips, func(ip string) {
+        names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+        emit(ip, names, err)
+    })()
+}
+
+// resolverPool runs lookup over in with n goroutines; the returned func
+// waits for them to finish after in is closed.
+func resolverPool(n int, in <-chan string, lookup func(string)) (wait func()) {
+    wg := &sync.WaitGroup{}
+    for i := 0; i < n; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for v := range in {
+                lookup(v)
+            }
+        }()
+    }
+    return wg.Wait
+}
+
+// maxV6Expand is the default shortest IPv6 prefix that is expanded host
+// by host (--ipv6-min-prefix).
+const maxV6Expand = 112
+
+// cidrEach calls emit with every address val stands for, one at a time.
+// An IPv6 prefix shorter than x's bound is refused unless x expands all
+// prefixes or samples a fixed count, which is then drawn at random from
+// the prefix instead of walking it.
+func cidrEach(val string, x *expansion, emit func(string)) error {
     // try CIDR
     if strings.Contains(val, "/") {
         ip, ipnet, err := net.ParseCIDR(val)
-        if err != nil { return nil, err }
-        ips := []string{}
+        if err != nil { return err }
+        bound := x.v6MinPrefix
+        if bound == 0 {
+            bound = maxV6Expand
+        }
+        if ones, bits := ipnet.Mask.Size(); bits == 128 && ones < bound && !x.v6All {
+            hostBits := bits - ones
+            if x.sample == nil || x.sample.n == 0 || hostBits < 63 && x.sample.n >= 1<<hostBits {
+                return fmt.Errorf("%s: IPv6 prefix shorter than /%d is too large to expand; use --sample N, --ipv6-min-prefix or --ipv6-expand-all", val, bound)
+            }
+            randomAddrs(ipnet, x.sample.n, x.sample.rng, emit)
+            return nil
+        }
+        // the network and broadcast addresses of an IPv4 subnet may be
+        // left out; a /31 has neither (RFC 3021), a /32 is one host
+        first, last := "", ""
+        if ones, bits := ipnet.Mask.Size(); x.skipEdges && bits == 32 && ones <= 30 {
+            first, last = ipnet.IP.String(), lastAddr(ipnet).String()
+        }
         for ip := ip.Mask(ipnet.Mask); ipnet.Contains(ip); incIP(ip) {
-            ips = append(ips, ip.String())
+            if s := ip.String(); s != first && s != last {
+                emit(s)
+            }
         }
-        return ips, nil
+        return nil
     }
-    // hostname or raw IP
-    if net.ParseIP(val) != nil {
-        return []string{val}, nil
+    // hostname or raw IP (IPv4 or IPv6, in canonical form)
+    if ip := net.ParseIP(val); ip != nil {
+        emit(ip.String())
+        return nil
     }
     addrs, _ := net.LookupHost(val)
-    return addrs, nil
+    for _, a := range addrs {
+        emit(a)
+    }
+    return nil
+}
+
+// lastAddr returns the highest address of n, the IPv4 broadcast address.
+func lastAddr(n *net.IPNet) net.IP {
+    out := make(net.IP, len(n.IP))
+    for i := range n.IP {
+        out[i] = n.IP[i] | ^n.Mask[i]
+    }
+    return out
 }
 
 func incIP(ip net.IP) {
@@ -110,39 +696,250 @@ func incIP(ip net.IP) {
     }
 }
 
-func parsePorts(arg string) ([]int, error) {
-    if strings.HasSuffix(arg, ".csv") {
-        f, err := os.Open(arg)
-        if err != nil { return nil, err }
-        defer f.Close()
-        r := csv.NewReader(f)
-        out := []int{}
-        for {
-            rec, err := r.Read()
-            if err != nil { break }
-            portProto := strings.Split(rec[1], "/")[0]
-            p, _ := strconv.Atoi(portProto)
-            out = append(out, p)
+// Port is one entry of a port spec. Proto is "tcp" or "udp" when the spec
+// named one (a T: or U: prefix, or a CSV "/proto" suffix) and "" otherwise.
+type Port struct {
+    Num   int
+    Proto string
+}
+
+// ParsePorts handles port lists/ranges or a CSV file, including nmap-style
+// specs (see ParsePortSpec); protocols are dropped.
+func ParsePorts(arg string) ([]int, error) {
+    return ParsePortsColumn(arg, "")
+}
+
+// ParsePortsColumn is ParsePorts reading ports from column of a CSV file
+// (see readColumn); column defaults to the second.
+func ParsePortsColumn(arg, column string) ([]int, error) {
+    spec, err := ParsePortSpecColumn(arg, column)
+    if err != nil {
+        return nil, err
+    }
+    ports := make([]int, len(spec))
+    for i, p := range spec {
+        ports[i] = p.Num
+    }
+    return ports, nil
+}
+
+// ParsePortSpecColumn is ParsePortSpec reading ports from column of a CSV
+// file. Cells may hold a list or range and a "/proto" suffix, e.g. "53/udp".
+func ParsePortSpecColumn(arg, column string) ([]Port, error) {
+    if !strings.HasSuffix(arg, ".csv") {
+        return ParsePortSpec(arg)
+    }
+    cells, err := readColumn(arg, column, 1, looksLikePort)
+    if err != nil {
+        return nil, err
+    }
+    out := []Port{}
+    for _, c := range cells {
+        for _, part := range strings.Split(c, ",") {
+            num, proto, _ := strings.Cut(part, "/")
+            ports, _ := ParsePortSpec(num)
+            proto = strings.ToLower(strings.TrimSpace(proto))
+            for _, p := range ports {
+                if proto == "tcp" || proto == "udp" {
+                    p.Proto = proto
+                }
+                out = append(out, p)
+            }
         }
-        return out, nil
     }
-    ports := []int{}
+    return out, nil
+}
+
+// ParsePortSpec parses ports and ranges in the style of nmap's -p. A "T:"
+// or "U:" prefix selects TCP or UDP for the entries that follow it, a range
+// may be open-ended ("-1024", "60000-"), and "-" (or "-p-") means every
+// port from 1 to 65535. E.g. "T:22,80,U:53,161" scans TCP 22 and 80 and
+// UDP 53 and 161. Service names such as "ssh" or "https" stand for their
+// port (see lookupService).
+func ParsePortSpec(arg string) ([]Port, error) {
+    arg = strings.TrimSpace(arg)
+    if arg == "-p-" {
+        arg = "-"
+    }
+    proto := ""
+    out := []Port{}
     for _, part := range strings.Split(arg, ",") {
         part = strings.TrimSpace(part)
-        if strings.Contains(part, "-") {
-            pair := strings.Split(part, "-")
-            start, _ := strconv.Atoi(pair[0])
-            end, _ := strconv.Atoi(pair[1])
-            for p := start; p <= end; p++ {
-                ports = append(ports, p)
+        if len(part) >= 2 && part[1] == ':' {
+            switch part[0] {
+            case 'T', 't':
+                proto = "tcp"
+            case 'U', 'u':
+                proto = "udp"
+            default:
+                return nil, fmt.Errorf("port %q: unknown protocol prefix (want T: or U:)", part)
             }
-        } else {
-            p, _ := strconv.Atoi(part)
-            ports = append(ports, p)
+            part = strings.TrimSpace(part[2:])
+        }
+        if part == "" {
+            continue
+        }
+        if strings.ContainsFunc(part, unicode.IsLetter) {
+            n, ok := lookupService(part, proto)
+            if !ok {
+                return nil, fmt.Errorf("port %q: unknown service name", part)
+            }
+            out = append(out, Port{Num: n, Proto: proto})
+            continue
+        }
+        lo, hi, err := portRange(part)
+        if err != nil {
+            return nil, fmt.Errorf("port %q: %w", part, err)
+        }
+        for n := lo; n <= hi; n++ {
+            out = append(out, Port{Num: n, Proto: proto})
         }
     }
-    return ports, nil
+    return out, nil
 }
 
-// TODO: loadCheckpoint implementation placeholder
-func loadCheckpoint(path string) ([]ProbeTarget, error) { return nil, nil }
\ No newline at end of file
+// portRange parses "N", "N-M", "-M", "N-" or "-".
+func portRange(s string) (lo, hi int, err error) {
+    a, b, isRange := strings.Cut(s, "-")
+    if !isRange {
+        n, err := strconv.Atoi(s)
+        if err != nil || n < 0 || n > 65535 {
+            return 0, 0, errors.New("not a port number")
+        }
+        return n, n, nil
+    }
+    lo, hi = 1, 65535
+    if a != "" {
+        if lo, err = strconv.Atoi(a); err != nil {
+            return 0, 0, errors.New("bad range start")
+        }
+    }
+    if b != "" {
+        if hi, err = strconv.Atoi(b); err != nil {
+            return 0, 0, errors.New("bad range end")
+        }
+    }
+    if lo < 0 || hi > 65535 || lo > hi {
+        return 0, 0, errors.New("range out of order or beyond 0-65535")
+    }
+    return lo, hi, nil
+}
+
+// readColumn returns one column of the CSV file at path. column is a
+// header name or a 1-based index; "" means index def (0-based). A first
+// row is taken as a header, and skipped, when column names it or when its
+// cell in the column fails looksLike.
+func readColumn(path, column string, def int, looksLike func(string) bool) ([]string, error) {
+    _, idx, recs, err := readTable(path, column, def, looksLike)
+    if err != nil {
+        return nil, err
+    }
+    out := make([]string, 0, len(recs))
+    for i, rec := range recs {
+        if idx >= len(rec) {
+            return nil, fmt.Errorf("%s: row %d has no column %d", path, i+1, idx+1)
+        }
+        if cell := strings.TrimSpace(rec[idx]); cell != "" {
+            out = append(out, cell)
+        }
+    }
+    return out, nil
+}
+
+// readTable reads the CSV file at path as readColumn does, returning the
+// header (nil if the first row is data), the 0-based index of column and
+// the data rows.
+func readTable(path, column string, def int, looksLike func(string) bool) ([]string, int, [][]string, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, 0, nil, err
+    }
+    defer f.Close()
+    r := csv.NewReader(f)
+    r.FieldsPerRecord = -1
+    r.TrimLeadingSpace = true
+    recs, err := r.ReadAll()
+    if err != nil {
+        return nil, 0, nil, fmt.Errorf("%s: %w", path, err)
+    }
+    if len(recs) == 0 {
+        return nil, 0, nil, nil
+    }
+    idx, named, err := columnIndex(path, recs[0], column, def)
+    if err != nil {
+        return nil, 0, nil, err
+    }
+    if named || idx >= len(recs[0]) || !looksLike(strings.TrimSpace(recs[0][idx])) {
+        return recs[0], idx, recs[1:], nil
+    }
+    return nil, idx, recs, nil
+}
+
+// columnIndex resolves column (a header name or 1-based index, "" for def)
+// against the first row of path, reporting whether it matched by name.
+func columnIndex(path string, first []string, column string, def int) (int, bool, error) {
+    if column == "" {
+        return def, false, nil
+    }
+    if n, err := strconv.Atoi(column); err == nil {
+        if n < 1 {
+            return 0, false, fmt.Errorf("%s: column index %d must be 1 or more", path, n)
+        }
+        return n - 1, false, nil
+    }
+    for i, h := range first {
+        if strings.EqualFold(strings.TrimSpace(h), column) {
+            return i, true, nil
+        }
+    }
+    return 0, false, fmt.Errorf("%s: no column named %q in header %q", path, column, strings.Join(first, ","))
+}
+
+// looksLikeHost reports whether a cell could be an address, CIDR,
+// hostname or link token rather than a header such as "ip".
+func looksLikeHost(s string) bool {
+    s = trimToken(s)
+    if _, _, err := net.ParseCIDR(s); err == nil {
+        return true
+    }
+    return net.ParseIP(s) != nil || strings.Contains(s, ".") || strings.HasPrefix(s, ping.LinkPrefix)
+}
+
+// looksLikePort reports whether a cell starts with a port number or is a
+// service name.
+func looksLikePort(s string) bool {
+    f := strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == '-' || r == ',' })
+    if len(f) == 0 {
+        return false
+    }
+    if _, err := strconv.Atoi(strings.TrimSpace(f[0])); err == nil {
+        return true
+    }
+    name, _, _ := strings.Cut(s, "/")
+    _, ok := lookupService(strings.TrimSpace(name), "")
+    return ok
+}
+
+// loadCheckpoint returns the targets a checkpoint file left unscanned.
+func loadCheckpoint(path string) ([]ProbeTarget, error) {
+    b, err := seal.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var cp struct {
+        Remaining [][]json.RawMessage `json:"remaining"`
+    }
+    if err := json.Unmarshal(b, &cp); err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    out := make([]ProbeTarget, 0, len(cp.Remaining))
+    for i, pair := range cp.Remaining {
+        var t ProbeTarget
+        if len(pair) < 2 || len(pair) > 3 || json.Unmarshal(pair[0], &t.IP) != nil || json.Unmarshal(pair[1], &t.Port) != nil ||
+            (len(pair) == 3 && json.Unmarshal(pair[2], &t.Proto) != nil) {
+            return nil, fmt.Errorf("%s: remaining[%d] is not an [ip, port] pair or [ip, port, proto] triple", path, i)
+        }
+        out = append(out, t)
+    }
+    return out, nil
+}
\ No newline at end of file
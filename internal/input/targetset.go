@@ -0,0 +1,140 @@
+// File: internal/input/targetset.go
+package input
+
+// Orders are the accepted --order values: "port" tries each port on every
+// host before the next port, "host" finishes one host's ports first.
+var Orders = []string{"port", "host"}
+
+// Targets is a scan's target set. Address × port products are generated
+// on demand, so a /12 × 1000 ports holds the address and port lists in
+// memory rather than a billion pairs.
+type Targets struct {
+    ips       []string
+    ports     []ProbeTarget // Port and Proto only
+    byHost    bool          // host-major order: all ports of one address together
+    list      []ProbeTarget // explicit targets (from a checkpoint), used as given
+    listIndex []int         // dispatch order of list, if regrouped
+}
+
+// NewTargets returns the product of ips and ports. By default each port is
+// tried on every address before the next port, spreading load over hosts.
+func NewTargets(ips []string, ports []ProbeTarget) *Targets {
+    return &Targets{ips: ips, ports: ports}
+}
+
+// ListTargets wraps an explicit list of targets.
+func ListTargets(list []ProbeTarget) *Targets {
+    return &Targets{list: list}
+}
+
+// Len returns the number of targets.
+func (t *Targets) Len() int {
+    if t.list != nil {
+        return len(t.list)
+    }
+    return len(t.ips) * len(t.ports)
+}
+
+// At returns the i-th target in dispatch order.
+func (t *Targets) At(i int) ProbeTarget {
+    if t.list != nil {
+        if t.listIndex != nil {
+            i = t.listIndex[i]
+        }
+        return t.list[i]
+    }
+    var ip string
+    var p ProbeTarget
+    if t.byHost {
+        ip, p = t.ips[i/len(t.ports)], t.ports[i%len(t.ports)]
+    } else {
+        p, ip = t.ports[i/len(t.ips)], t.ips[i%len(t.ips)]
+    }
+    p.IP = ip
+    return p
+}
+
+// ByHost returns the same targets ordered host by host, as batching needs.
+func (t *Targets) ByHost() *Targets {
+    if t.list == nil {
+        return &Targets{ips: t.ips, ports: t.ports, byHost: true}
+    }
+    var hosts []string
+    idx := map[string][]int{}
+    for i, pt := range t.list {
+        if _, ok := idx[pt.IP]; !ok {
+            hosts = append(hosts, pt.IP)
+        }
+        idx[pt.IP] = append(idx[pt.IP], i)
+    }
+    order := make([]int, 0, len(t.list))
+    for _, ip := range hosts {
+        order = append(order, idx[ip]...)
+    }
+    return &Targets{list: t.list, listIndex: order}
+}
+
+// Iterator streams targets in dispatch order.
+type Iterator struct {
+    t *Targets
+    i int
+}
+
+// Iter returns an iterator starting at target from.
+func (t *Targets) Iter(from int) *Iterator {
+    return &Iterator{t: t, i: from}
+}
+
+// Next returns the next target, or false once all have been returned.
+func (it *Iterator) Next() (ProbeTarget, bool) {
+    if it.i >= it.t.Len() {
+        return ProbeTarget{}, false
+    }
+    it.i++
+    return it.t.At(it.i - 1), true
+}
+
+// From materializes the targets from position i on, e.g. for a checkpoint.
+func (t *Targets) From(i int) []ProbeTarget {
+    out := make([]ProbeTarget, 0, max(t.Len()-i, 0))
+    for it := t.Iter(i); ; {
+        pt, ok := it.Next()
+        if !ok {
+            return out
+        }
+        out = append(out, pt)
+    }
+}
+
+// IPs returns the distinct addresses, in order of first appearance.
+func (t *Targets) IPs() []string {
+    if t.list == nil {
+        if len(t.ports) == 0 {
+            return nil
+        }
+        return t.ips
+    }
+    seen := map[string]bool{}
+    var out []string
+    for _, pt := range t.list {
+        if !seen[pt.IP] {
+            seen[pt.IP] = true
+            out = append(out, pt.IP)
+        }
+    }
+    return out
+}
+
+// HasUDP reports whether any target came from a U: port spec.
+func (t *Targets) HasUDP() bool {
+    all := t.ports
+    if t.list != nil {
+        all = t.list
+    }
+    for _, pt := range all {
+        if pt.Proto == "udp" {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,37 @@
+// File: internal/input/topports.go
+package input
+
+import (
+    _ "embed"
+    "fmt"
+    "strings"
+)
+
+// topPortsTable ranks TCP ports by how often they are found open.
+//
+//go:embed topports.txt
+var topPortsTable string
+
+// TopPorts returns the n most commonly open TCP ports, most common first.
+func TopPorts(n int) ([]Port, error) {
+    out := []Port{}
+    for _, line := range strings.Split(topPortsTable, "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        if len(out) == n {
+            break
+        }
+        num, proto, _ := strings.Cut(line, "/")
+        ports, err := ParsePortSpec(num)
+        if err != nil || len(ports) != 1 {
+            return nil, fmt.Errorf("top-ports table: bad entry %q", line)
+        }
+        out = append(out, Port{Num: ports[0].Num, Proto: proto})
+    }
+    if len(out) < n {
+        return nil, fmt.Errorf("--top-ports %d: the built-in table ranks only the %d most common ports; list further ports with --port", n, len(out))
+    }
+    return out, nil
+}
@@ -0,0 +1,195 @@
+// File: internal/k8s/k8s.go
+// Package k8s lists pod and service addresses, with their declared ports,
+// from the Kubernetes API, so a scan from inside a cluster can check what
+// its NetworkPolicies actually let through.
+package k8s
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "os"
+    "strings"
+    "time"
+)
+
+// In-cluster service account files.
+const (
+    tokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+    caFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// Endpoint is one address and declared port of a pod or service.
+type Endpoint struct {
+    IP    string
+    Port  int
+    Proto string // "tcp", "udp" or "sctp"
+    Name  string // kind/namespace/name, e.g. "svc/default/web"
+}
+
+// Client talks to one API server.
+type Client struct {
+    base  string
+    token string
+    http  *http.Client
+}
+
+// NewClient returns a client for api, e.g. "http://127.0.0.1:8001" for a
+// local "kubectl proxy" (which brings the kubeconfig's credentials). With
+// api empty the in-cluster service account is used. Kubeconfig files are
+// not read: run kubectl proxy for another cluster or context.
+func NewClient(api string) (*Client, error) {
+    if api != "" {
+        return &Client{base: strings.TrimSuffix(api, "/"), http: &http.Client{Timeout: 30 * time.Second}}, nil
+    }
+    host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+    if host == "" || port == "" {
+        return nil, errors.New("k8s: not running in a cluster; pass --k8s-api (e.g. the address of kubectl proxy)")
+    }
+    token, err := os.ReadFile(tokenFile)
+    if err != nil {
+        return nil, fmt.Errorf("k8s: service account token: %w", err)
+    }
+    ca, err := os.ReadFile(caFile)
+    if err != nil {
+        return nil, fmt.Errorf("k8s: service account CA: %w", err)
+    }
+    pool := x509.NewCertPool()
+    if !pool.AppendCertsFromPEM(ca) {
+        return nil, fmt.Errorf("k8s: no certificates in %s", caFile)
+    }
+    return &Client{
+        base:  "https://" + net.JoinHostPort(host, port),
+        token: strings.TrimSpace(string(token)),
+        http: &http.Client{
+            Timeout:   30 * time.Second,
+            Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+        },
+    }, nil
+}
+
+// objectMeta, podList and serviceList hold the fields read from the API.
+type objectMeta struct {
+    Name      string `json:"name"`
+    Namespace string `json:"namespace"`
+}
+
+type podList struct {
+    Items []struct {
+        Metadata objectMeta `json:"metadata"`
+        Spec     struct {
+            Containers []struct {
+                Ports []struct {
+                    ContainerPort int    `json:"containerPort"`
+                    Protocol      string `json:"protocol"`
+                } `json:"ports"`
+            } `json:"containers"`
+        } `json:"spec"`
+        Status struct {
+            Phase  string `json:"phase"`
+            PodIPs []struct {
+                IP string `json:"ip"`
+            } `json:"podIPs"`
+        } `json:"status"`
+    } `json:"items"`
+}
+
+type serviceList struct {
+    Items []struct {
+        Metadata objectMeta `json:"metadata"`
+        Spec     struct {
+            ClusterIPs []string `json:"clusterIPs"`
+            Ports      []struct {
+                Port     int    `json:"port"`
+                Protocol string `json:"protocol"`
+            } `json:"ports"`
+        } `json:"spec"`
+    } `json:"items"`
+}
+
+// Endpoints lists the pods and services of namespace ("" for all) that
+// match the label selector, one entry per address and declared port.
+// Pods that are not Running (pending, or finished with their IP possibly
+// reused) and headless services are skipped.
+func (c *Client) Endpoints(ctx context.Context, namespace, selector string) ([]Endpoint, error) {
+    var pods podList
+    if err := c.list(ctx, "pods", namespace, selector, &pods); err != nil {
+        return nil, err
+    }
+    var svcs serviceList
+    if err := c.list(ctx, "services", namespace, selector, &svcs); err != nil {
+        return nil, err
+    }
+    var out []Endpoint
+    for _, p := range pods.Items {
+        if p.Status.Phase != "Running" {
+            continue
+        }
+        name := "pod/" + p.Metadata.Namespace + "/" + p.Metadata.Name
+        for _, ip := range p.Status.PodIPs {
+            for _, ctr := range p.Spec.Containers {
+                for _, port := range ctr.Ports {
+                    out = append(out, Endpoint{IP: ip.IP, Port: port.ContainerPort, Proto: proto(port.Protocol), Name: name})
+                }
+            }
+        }
+    }
+    for _, s := range svcs.Items {
+        name := "svc/" + s.Metadata.Namespace + "/" + s.Metadata.Name
+        for _, ip := range s.Spec.ClusterIPs {
+            if net.ParseIP(ip) == nil {
+                continue // "None": headless
+            }
+            for _, port := range s.Spec.Ports {
+                out = append(out, Endpoint{IP: ip, Port: port.Port, Proto: proto(port.Protocol), Name: name})
+            }
+        }
+    }
+    return out, nil
+}
+
+// proto maps a Kubernetes protocol to a result proto; TCP is the default.
+func proto(p string) string {
+    if p == "" {
+        return "tcp"
+    }
+    return strings.ToLower(p)
+}
+
+// list fetches one resource collection into v.
+func (c *Client) list(ctx context.Context, resource, namespace, selector string, v any) error {
+    path := "/api/v1/" + resource
+    if namespace != "" {
+        path = "/api/v1/namespaces/" + url.PathEscape(namespace) + "/" + resource
+    }
+    if selector != "" {
+        path += "?labelSelector=" + url.QueryEscape(selector)
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+    if err != nil {
+        return err
+    }
+    if c.token != "" {
+        req.Header.Set("Authorization", "Bearer "+c.token)
+    }
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return fmt.Errorf("k8s: listing %s: %w", resource, err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        return fmt.Errorf("k8s: listing %s: %s: %s", resource, resp.Status, strings.TrimSpace(string(msg)))
+    }
+    if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+        return fmt.Errorf("k8s: listing %s: %w", resource, err)
+    }
+    return nil
+}
@@ -2,18 +2,24 @@
 package logger
 
 import (
+    "io"
     "log"
     "os"
 )
 
 type Logger struct { *log.Logger }
 
-func New(path string) *Logger {
+func New(path string) *Logger { return NewTo(path, os.Stdout) }
+
+// NewTo is New echoing to console instead of stdout, which is kept free
+// when results stream there.
+func NewTo(path string, console io.Writer) *Logger {
     f, _ := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-    mw := io.MultiWriter(os.Stdout, f)
+    mw := io.MultiWriter(console, f)
     return &Logger{log.New(mw, "", log.LstdFlags)}
 }
 
+func (l *Logger) Tracef(format string, v ...interface{}) { l.Printf("TRACE "+format, v...) }
 func (l *Logger) Debugf(format string, v ...interface{}) { l.Printf("DEBUG "+format, v...) }
 func (l *Logger) Info(msg string)                      { l.Println("INFO " + msg) }
 func (l *Logger) Warn(msg string)                      { l.Println("WARN " + msg) }
\ No newline at end of file
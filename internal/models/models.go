@@ -0,0 +1,10 @@
+// File: internal/models/models.go
+package models
+
+// ScanTarget is one probe destination: an address and a port (or, for
+// protocol scans, an IP protocol number).
+type ScanTarget struct {
+    IP    string
+    Port  int
+    Proto string // "udp" for U: ports of a mixed spec; "" is the scan type's
+}
@@ -0,0 +1,131 @@
+// File: internal/owners/owners.go
+package owners
+
+import (
+    "encoding/csv"
+    "fmt"
+    "net"
+    "os"
+    "sort"
+    "strings"
+    "sync"
+
+    "goscant/internal/scanner"
+)
+
+// Columns lists the result fields filled in by a Table.
+var Columns = []string{"owner", "team", "email"}
+
+// Owner identifies who is responsible for a netblock.
+type Owner struct {
+    Name  string
+    Team  string
+    Email string
+}
+
+type entry struct {
+    block *net.IPNet
+    owner Owner
+}
+
+// Table maps netblocks to owners and tallies results per owner.
+type Table struct {
+    entries []entry
+
+    mu     sync.Mutex
+    counts map[string]map[scanner.Status]int
+}
+
+// Load reads a CSV mapping file with rows of cidr,owner,team,email.
+// A leading header row and '#' comments are ignored.
+func Load(path string) (*Table, error) {
+    f, err := os.Open(path)
+    if err != nil { return nil, err }
+    defer f.Close()
+    r := csv.NewReader(f)
+    r.Comment = '#'
+    r.FieldsPerRecord = -1
+    recs, err := r.ReadAll()
+    if err != nil { return nil, err }
+
+    t := &Table{counts: map[string]map[scanner.Status]int{}}
+    for i, rec := range recs {
+        cidr := strings.TrimSpace(rec[0])
+        if i == 0 && strings.EqualFold(cidr, "cidr") {
+            continue
+        }
+        if !strings.Contains(cidr, "/") {
+            if strings.Contains(cidr, ":") {
+                cidr += "/128"
+            } else {
+                cidr += "/32"
+            }
+        }
+        _, block, err := net.ParseCIDR(cidr)
+        if err != nil {
+            return nil, fmt.Errorf("%s line %d: %w", path, i+1, err)
+        }
+        e := entry{block: block}
+        fields := []*string{&e.owner.Name, &e.owner.Team, &e.owner.Email}
+        for j := 1; j < len(rec) && j <= len(fields); j++ {
+            *fields[j-1] = strings.TrimSpace(rec[j])
+        }
+        t.entries = append(t.entries, e)
+    }
+    return t, nil
+}
+
+// Lookup returns the owner of the most specific netblock containing ip.
+func (t *Table) Lookup(ip string) (Owner, bool) {
+    addr := net.ParseIP(ip)
+    if addr == nil { return Owner{}, false }
+    best, bestLen := -1, -1
+    for i, e := range t.entries {
+        if !e.block.Contains(addr) { continue }
+        if ones, _ := e.block.Mask.Size(); ones > bestLen {
+            best, bestLen = i, ones
+        }
+    }
+    if best < 0 { return Owner{}, false }
+    return t.entries[best].owner, true
+}
+
+// Enrich annotates r with its owner and counts it towards the summary.
+func (t *Table) Enrich(r *scanner.Result) {
+    o, ok := t.Lookup(r.IP)
+    key := "(unowned)"
+    if ok {
+        if r.Meta == nil {
+            r.Meta = map[string]string{}
+        }
+        r.Meta["owner"] = o.Name
+        r.Meta["team"] = o.Team
+        r.Meta["email"] = o.Email
+        key = o.Name
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if t.counts[key] == nil {
+        t.counts[key] = map[scanner.Status]int{}
+    }
+    t.counts[key][r.Status]++
+}
+
+// Summary returns one line per owner with its result counts by status.
+func (t *Table) Summary() []string {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    names := make([]string, 0, len(t.counts))
+    for name := range t.counts {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    lines := make([]string, 0, len(names))
+    for _, name := range names {
+        c := t.counts[name]
+        lines = append(lines, fmt.Sprintf("%s: open=%d closed=%d filtered=%d error=%d",
+            name, c[scanner.Open], c[scanner.Closed], c[scanner.Filtered], c[scanner.Error]))
+    }
+    return lines
+}
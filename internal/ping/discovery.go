@@ -0,0 +1,193 @@
+// File: internal/ping/discovery.go
+package ping
+
+import (
+    "context"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "math/rand"
+    "net"
+    "os"
+    "strconv"
+    "time"
+
+    "golang.org/x/net/icmp"
+    "golang.org/x/net/ipv4"
+
+    "goscant/internal/rawnet"
+)
+
+// errIPv4Only is returned by probes that have no IPv6 counterpart.
+var errIPv4Only = errors.New("probe supports IPv4 targets only")
+
+// Timestamp sends one ICMP Timestamp Request, which hosts filtering echo
+// often still answer. It needs raw socket privileges.
+func Timestamp(ctx context.Context, ip string, timeout time.Duration) (bool, error) {
+    dst := net.ParseIP(ip).To4()
+    if dst == nil {
+        return false, errIPv4Only
+    }
+    conn, err := icmp.ListenPacket(family4.raw, "")
+    if err != nil {
+        return false, fmt.Errorf("ping: %w", err)
+    }
+    defer conn.Close()
+    defer closeOnCancel(ctx, conn)()
+
+    id, seq := (os.Getpid()^rand.Int())&0xffff, rand.Intn(0xffff)
+    body := make([]byte, 16) // identifier, sequence, originate/receive/transmit times
+    binary.BigEndian.PutUint16(body[0:2], uint16(id))
+    binary.BigEndian.PutUint16(body[2:4], uint16(seq))
+    binary.BigEndian.PutUint32(body[4:8], msSinceMidnightUTC())
+    msg := icmp.Message{Type: ipv4.ICMPTypeTimestamp, Body: &icmp.RawBody{Data: body}}
+    b, err := msg.Marshal(nil)
+    if err != nil {
+        return false, err
+    }
+    start := time.Now()
+    if _, err := conn.WriteTo(b, &net.IPAddr{IP: dst}); err != nil {
+        return false, fmt.Errorf("ping: %w", err)
+    }
+    conn.SetReadDeadline(start.Add(timeout))
+    buf := make([]byte, 1500)
+    for {
+        n, from, err := conn.ReadFrom(buf)
+        if err != nil {
+            return readOutcome(ctx, err)
+        }
+        if !fromAddr(from).Equal(dst) {
+            continue
+        }
+        reply, err := icmp.ParseMessage(protoICMP, buf[:n])
+        if err != nil || reply.Type != ipv4.ICMPTypeTimestampReply {
+            continue
+        }
+        if r, ok := reply.Body.(*icmp.RawBody); ok && len(r.Data) >= 4 &&
+            int(binary.BigEndian.Uint16(r.Data[0:2])) == id && int(binary.BigEndian.Uint16(r.Data[2:4])) == seq {
+            return true, nil
+        }
+    }
+}
+
+func msSinceMidnightUTC() uint32 {
+    now := time.Now().UTC()
+    midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+    return uint32(now.Sub(midnight).Milliseconds())
+}
+
+// tcpACKPing sends a bare ACK to ip:port over a raw socket. No connection
+// exists, so a live host answers RST whether the port is open or closed;
+// stateless firewalls that only block SYNs let it through.
+func tcpACKPing(ctx context.Context, ip string, port int, timeout time.Duration) (bool, error) {
+    dst := net.ParseIP(ip).To4()
+    if dst == nil {
+        return false, errIPv4Only
+    }
+    src, err := sourceFor(dst)
+    if err != nil {
+        return false, err
+    }
+    conn, err := rawnet.Listen("ip4:tcp")
+    if err != nil {
+        return false, err
+    }
+    defer conn.Close()
+    defer closeOnCancel(ctx, conn)()
+
+    sport := uint16(32768 + rand.Intn(28232))
+    ack := rand.Uint32()
+    seg := make([]byte, 20)
+    binary.BigEndian.PutUint16(seg[0:2], sport)
+    binary.BigEndian.PutUint16(seg[2:4], uint16(port))
+    binary.BigEndian.PutUint32(seg[4:8], rand.Uint32())
+    binary.BigEndian.PutUint32(seg[8:12], ack)
+    seg[12] = 5 << 4
+    seg[13] = 0x10 // ACK
+    binary.BigEndian.PutUint16(seg[14:16], 1024)
+    binary.BigEndian.PutUint16(seg[16:18], tcpChecksum(src, dst, seg))
+
+    start := time.Now()
+    if _, err := conn.WriteTo(seg, &net.IPAddr{IP: dst}); err != nil {
+        return false, err
+    }
+    conn.SetReadDeadline(start.Add(timeout))
+    buf := make([]byte, 1500)
+    for {
+        n, from, err := conn.ReadFrom(buf) // IPv4 header already stripped
+        if err != nil {
+            return readOutcome(ctx, err)
+        }
+        if n < 20 || !fromAddr(from).Equal(dst) {
+            continue
+        }
+        if binary.BigEndian.Uint16(buf[0:2]) == uint16(port) && binary.BigEndian.Uint16(buf[2:4]) == sport && buf[13]&0x04 != 0 {
+            return true, nil
+        }
+    }
+}
+
+// sourceFor returns the local address the kernel would route dst from.
+func sourceFor(dst net.IP) (net.IP, error) {
+    c, err := net.Dial("udp", net.JoinHostPort(dst.String(), "9"))
+    if err != nil {
+        return nil, err
+    }
+    defer c.Close()
+    return c.LocalAddr().(*net.UDPAddr).IP.To4(), nil
+}
+
+func tcpChecksum(src, dst net.IP, seg []byte) uint16 {
+    var sum uint32
+    add := func(b []byte) {
+        for i := 0; i+1 < len(b); i += 2 {
+            sum += uint32(binary.BigEndian.Uint16(b[i:]))
+        }
+        if len(b)%2 == 1 {
+            sum += uint32(b[len(b)-1]) << 8
+        }
+    }
+    add(src.To4())
+    add(dst.To4())
+    add([]byte{0, 6})
+    add([]byte{byte(len(seg) >> 8), byte(len(seg))})
+    add(seg)
+    for sum > 0xffff {
+        sum = sum>>16 + sum&0xffff
+    }
+    return ^uint16(sum)
+}
+
+// readOutcome maps a read error ending a probe: a deadline means no answer.
+func readOutcome(ctx context.Context, err error) (bool, error) {
+    if ctx.Err() != nil {
+        return false, ctx.Err()
+    }
+    var ne net.Error
+    if errors.As(err, &ne) && ne.Timeout() {
+        return false, nil
+    }
+    return false, fmt.Errorf("ping: %w", err)
+}
+
+// closeOnCancel closes c once ctx ends, unblocking a pending read. The
+// returned function stops the watch.
+func closeOnCancel(ctx context.Context, c interface{ Close() error }) func() {
+    done := make(chan struct{})
+    go func() {
+        select {
+        case <-ctx.Done():
+            c.Close()
+        case <-done:
+        }
+    }()
+    return func() { close(done) }
+}
+
+// probeName labels a method/port pair in discovery reports.
+func probeName(kind string, port int) string {
+    if port == 0 {
+        return kind
+    }
+    return kind + ":" + strconv.Itoa(port)
+}
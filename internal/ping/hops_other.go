@@ -0,0 +1,13 @@
+// File: internal/ping/hops_other.go
+//go:build !unix
+
+package ping
+
+import (
+    "errors"
+    "net"
+)
+
+func setHopLimit(c *net.IPConn, hops int) error {
+    return errors.New("setting the IPv6 hop limit is not supported on this platform")
+}
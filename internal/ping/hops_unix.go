@@ -0,0 +1,27 @@
+// File: internal/ping/hops_unix.go
+//go:build unix
+
+package ping
+
+import (
+    "net"
+    "syscall"
+)
+
+// setHopLimit sets the unicast and multicast hop limit of an IPv6 socket.
+func setHopLimit(c *net.IPConn, hops int) error {
+    rc, err := c.SyscallConn()
+    if err != nil {
+        return err
+    }
+    var serr error
+    err = rc.Control(func(fd uintptr) {
+        if serr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, hops); serr == nil {
+            serr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_HOPS, hops)
+        }
+    })
+    if err != nil {
+        return err
+    }
+    return serr
+}
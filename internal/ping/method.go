@@ -0,0 +1,203 @@
+// File: internal/ping/method.go
+package ping
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+)
+
+// Method is one host-discovery probe kind, with Ports for those that
+// target a port:
+//
+//   - icmp: echo request
+//   - timestamp: ICMP timestamp request (IPv4, raw sockets)
+//   - tcp: connect; a SYN/ACK or a RST proves the host is up
+//   - ack: bare TCP ACK (IPv4, raw sockets); a RST proves the host is up
+//   - udp: datagram; any reply or ICMP port unreachable proves it
+type Method struct {
+    Kind  string
+    Ports []int
+}
+
+// portMethods are the method kinds that take ports.
+var portMethods = map[string]bool{"tcp": true, "ack": true, "udp": true}
+
+// ParseMethods parses a --ping-method list such as "icmp,tcp:80,443,udp:53".
+// Bare numbers extend the port list of the preceding port method.
+func ParseMethods(spec string) ([]Method, error) {
+    var out []Method
+    for _, tok := range strings.Split(spec, ",") {
+        tok = strings.TrimSpace(tok)
+        kind, ports, hasPorts := strings.Cut(tok, ":")
+        switch {
+        case tok == "":
+        case (kind == "icmp" || kind == "timestamp") && !hasPorts:
+            out = append(out, Method{Kind: kind})
+        case portMethods[kind] && hasPorts:
+            out = append(out, Method{Kind: kind})
+            if err := addPort(out, ports); err != nil {
+                return nil, err
+            }
+        case len(out) > 0 && portMethods[out[len(out)-1].Kind] && !hasPorts:
+            if err := addPort(out, tok); err != nil {
+                return nil, err
+            }
+        default:
+            return nil, fmt.Errorf("ping method %q: want icmp, timestamp, tcp:PORTS, ack:PORTS or udp:PORTS", tok)
+        }
+    }
+    if len(out) == 0 {
+        return nil, errors.New("no ping method given")
+    }
+    return out, nil
+}
+
+func addPort(methods []Method, s string) error {
+    p, err := strconv.Atoi(strings.TrimSpace(s))
+    if err != nil || p < 1 || p > 65535 {
+        return fmt.Errorf("ping method %s: bad port %q", methods[len(methods)-1].Kind, s)
+    }
+    m := &methods[len(methods)-1]
+    m.Ports = append(m.Ports, p)
+    return nil
+}
+
+// Answer is the outcome of one discovery probe.
+type Answer struct {
+    Probe string // e.g. "icmp" or "tcp:443"
+    Up    bool
+    Err   error // the probe could not be attempted
+}
+
+// Discover runs every probe of methods against ip at once and returns
+// their outcomes, in method order, once all have answered or timed out.
+// Each probe is sent up to count times, stopping at the first answer.
+func Discover(ctx context.Context, ip string, methods []Method, timeout time.Duration, count int) []Answer {
+    var probes []func() (bool, error)
+    var answers []Answer
+    add := func(name string, probe func() (bool, error)) {
+        answers = append(answers, Answer{Probe: name})
+        probes = append(probes, probe)
+    }
+    for _, m := range methods {
+        switch m.Kind {
+        case "icmp":
+            add("icmp", func() (bool, error) { return Ping(ctx, ip, timeout) })
+        case "timestamp":
+            add("timestamp", func() (bool, error) { return Timestamp(ctx, ip, timeout) })
+        }
+        for _, port := range m.Ports {
+            switch m.Kind {
+            case "tcp":
+                add(probeName("tcp", port), func() (bool, error) { return tcpPing(ctx, ip, port, timeout) })
+            case "ack":
+                add(probeName("ack", port), func() (bool, error) { return tcpACKPing(ctx, ip, port, timeout) })
+            case "udp":
+                add(probeName("udp", port), func() (bool, error) { return udpPing(ctx, ip, port, timeout) })
+            }
+        }
+    }
+    var wg sync.WaitGroup
+    for i, probe := range probes {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for n := 0; n < max(count, 1) && ctx.Err() == nil; n++ {
+                if answers[i].Up, answers[i].Err = probe(); answers[i].Up || answers[i].Err != nil {
+                    return
+                }
+            }
+        }()
+    }
+    wg.Wait()
+    return answers
+}
+
+// Up reports whether any answer shows the host alive. An error means no
+// probe could be attempted at all.
+func Up(answers []Answer) (bool, error) {
+    var firstErr error
+    attempted := false
+    for _, a := range answers {
+        if a.Up {
+            return true, nil
+        }
+        if a.Err == nil {
+            attempted = true
+        } else if firstErr == nil {
+            firstErr = a.Err
+        }
+    }
+    if attempted || firstErr == nil {
+        return false, nil
+    }
+    return false, firstErr
+}
+
+// tcpPing connects to ip:port. An accepted or refused connection both
+// mean the host answered; silence or an unreachable error mean it did not.
+func tcpPing(ctx context.Context, ip string, port int, timeout time.Duration) (bool, error) {
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+    if err == nil {
+        conn.Close()
+        return true, nil
+    }
+    if errors.Is(err, syscall.ECONNREFUSED) {
+        return true, nil
+    }
+    var ne net.Error
+    if errors.As(err, &ne) && ne.Timeout() || errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH) {
+        return false, nil
+    }
+    return false, err
+}
+
+// udpPayloads are requests that well-known services answer; other ports
+// get an empty datagram and rely on ICMP port unreachable.
+var udpPayloads = map[int][]byte{
+    // DNS: standard query for the root's NS records
+    53: {0x13, 0x37, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0, 0x00, 0x00, 0x02, 0x00, 0x01},
+    // NTP: version 3 client request
+    123: append([]byte{0x1b}, make([]byte, 47)...),
+}
+
+// udpPing sends one datagram to ip:port. A reply, or the ICMP port
+// unreachable the kernel reports as a refused read, means the host is up.
+func udpPing(ctx context.Context, ip string, port int, timeout time.Duration) (bool, error) {
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "udp", net.JoinHostPort(ip, strconv.Itoa(port)))
+    if err != nil {
+        return false, err
+    }
+    defer conn.Close()
+    deadline := time.Now().Add(timeout)
+    if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+        deadline = dl
+    }
+    conn.SetDeadline(deadline)
+    if _, err := conn.Write(udpPayloads[port]); err != nil {
+        return false, err
+    }
+    _, err = conn.Read(make([]byte, 1500))
+    switch {
+    case err == nil, errors.Is(err, syscall.ECONNREFUSED):
+        return true, nil
+    case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+        return false, nil
+    }
+    var ne net.Error
+    if errors.As(err, &ne) && ne.Timeout() {
+        return false, nil
+    }
+    return false, err
+}
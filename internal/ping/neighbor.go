@@ -0,0 +1,139 @@
+// File: internal/ping/neighbor.go
+package ping
+
+import (
+    "bytes"
+    "context"
+    "encoding/binary"
+    "fmt"
+    "math/rand"
+    "net"
+    "os"
+    "strings"
+    "time"
+)
+
+// LinkPrefix starts an --ip token naming an interface whose on-link IPv6
+// hosts are found by multicast discovery, e.g. "link:eth0".
+const LinkPrefix = "link:"
+
+// linkWait is how long each multicast round collects answers.
+const linkWait = 2 * time.Second
+
+const (
+    icmp6EchoRequest = 128
+    icmp6EchoReply   = 129
+    icmp6NS          = 135
+    icmp6NA          = 136
+)
+
+// Neighbor is an on-link IPv6 host found by Neighbors.
+type Neighbor struct {
+    Addr string           // with zone, e.g. "fe80::1%eth0"
+    MAC  net.HardwareAddr // from its Neighbor Advertisement, if any
+}
+
+// Neighbors finds the IPv6 hosts on ifname's link without knowing their
+// addresses. An Echo Request to the all-nodes group (ff02::1) draws a
+// reply from every host that answers multicast echo; each responder is
+// then sent a Neighbor Solicitation on its solicited-node group, and its
+// Advertisement supplies the link-layer address. Needs raw ICMPv6 sockets.
+func Neighbors(ctx context.Context, ifname string) ([]Neighbor, error) {
+    ifi, err := net.InterfaceByName(ifname)
+    if err != nil {
+        return nil, fmt.Errorf("ping: %w", err)
+    }
+    pc, err := net.ListenPacket("ip6:ipv6-icmp", "::")
+    if err != nil {
+        return nil, fmt.Errorf("ping: %w", err)
+    }
+    conn := pc.(*net.IPConn)
+    defer conn.Close()
+    defer closeOnCancel(ctx, conn)()
+    // Receivers drop neighbor discovery packets whose hop limit is not 255
+    if err := setHopLimit(conn, 255); err != nil {
+        return nil, fmt.Errorf("ping: %w", err)
+    }
+
+    id := (os.Getpid() ^ rand.Int()) & 0xffff
+    echo := make([]byte, 8+len("goscant"))
+    echo[0] = icmp6EchoRequest
+    binary.BigEndian.PutUint16(echo[4:6], uint16(id))
+    copy(echo[8:], "goscant") // the kernel fills in the checksum
+    if _, err := conn.WriteTo(echo, &net.IPAddr{IP: net.ParseIP("ff02::1"), Zone: ifname}); err != nil {
+        return nil, fmt.Errorf("ping: %w", err)
+    }
+    var found []Neighbor
+    seen := map[string]int{}
+    err = collect(ctx, conn, func(b []byte, from *net.IPAddr) {
+        if b[0] != icmp6EchoReply || len(b) < 8 || int(binary.BigEndian.Uint16(b[4:6])) != id {
+            return
+        }
+        if _, ok := seen[from.IP.String()]; !ok {
+            seen[from.IP.String()] = len(found)
+            found = append(found, Neighbor{Addr: from.IP.String() + "%" + ifname})
+        }
+    })
+    if err != nil || len(found) == 0 {
+        return found, err
+    }
+
+    for _, n := range found {
+        target := net.ParseIP(strings.TrimSuffix(n.Addr, "%"+ifname))
+        if _, err := conn.WriteTo(solicitation(target, ifi.HardwareAddr), &net.IPAddr{IP: solicitedNode(target), Zone: ifname}); err != nil {
+            return found, fmt.Errorf("ping: %w", err)
+        }
+    }
+    err = collect(ctx, conn, func(b []byte, from *net.IPAddr) {
+        if b[0] != icmp6NA || len(b) < 24 {
+            return
+        }
+        i, ok := seen[net.IP(b[8:24]).String()]
+        if !ok {
+            return
+        }
+        for opt := b[24:]; len(opt) >= 8 && opt[1] > 0 && len(opt) >= int(opt[1])*8; opt = opt[int(opt[1])*8:] {
+            if opt[0] == 2 { // target link-layer address
+                found[i].MAC = net.HardwareAddr(bytes.Clone(opt[2:8]))
+            }
+        }
+    })
+    return found, err
+}
+
+// collect hands every ICMPv6 message read during linkWait to handle.
+func collect(ctx context.Context, conn *net.IPConn, handle func(b []byte, from *net.IPAddr)) error {
+    conn.SetReadDeadline(time.Now().Add(linkWait))
+    buf := make([]byte, 1500)
+    for {
+        n, from, err := conn.ReadFrom(buf)
+        if err != nil {
+            _, err := readOutcome(ctx, err)
+            return err
+        }
+        if n > 0 {
+            handle(buf[:n], from.(*net.IPAddr))
+        }
+    }
+}
+
+// solicitation builds a Neighbor Solicitation for target carrying our
+// link-layer address, so the answer can be unicast straight back.
+func solicitation(target net.IP, mac net.HardwareAddr) []byte {
+    b := make([]byte, 24, 32)
+    b[0] = icmp6NS
+    copy(b[8:24], target.To16())
+    if len(mac) == 6 {
+        b = append(b, 1, 1) // source link-layer address, 8 bytes
+        b = append(b, mac...)
+    }
+    return b
+}
+
+// solicitedNode returns the solicited-node multicast group of ip,
+// ff02::1:ffXX:XXXX with its low 24 bits.
+func solicitedNode(ip net.IP) net.IP {
+    g := net.ParseIP("ff02::1:ff00:0")
+    copy(g[13:], ip.To16()[13:])
+    return g
+}
@@ -4,42 +4,128 @@ package ping
 import (
     "context"
     "errors"
+    "fmt"
+    "math/rand"
     "net"
+    "os"
     "time"
 
-    "github.com/google/gopacket"
-    "github.com/google/gopacket/layers"
-    "github.com/google/gopacket/pcap"
+    "golang.org/x/net/icmp"
+    "golang.org/x/net/ipv4"
+    "golang.org/x/net/ipv6"
 )
 
-// Ping sends one ICMP Echo and waits for reply.
+// ErrNoReply is returned by Echo when no matching reply arrived in time.
+var ErrNoReply = errors.New("no echo reply")
+
+const (
+    protoICMP   = 1
+    protoICMPv6 = 58
+)
+
+// family holds what differs between ICMPv4 and ICMPv6 echo.
+type family struct {
+    raw, dgram  string // icmp.ListenPacket networks
+    proto       int
+    echo, reply icmp.Type
+}
+
+var (
+    family4 = family{raw: "ip4:icmp", dgram: "udp4", proto: protoICMP, echo: ipv4.ICMPTypeEcho, reply: ipv4.ICMPTypeEchoReply}
+    family6 = family{raw: "ip6:ipv6-icmp", dgram: "udp6", proto: protoICMPv6, echo: ipv6.ICMPTypeEchoRequest, reply: ipv6.ICMPTypeEchoReply}
+)
+
+// listen opens an ICMP socket for fam. Without root, an unprivileged
+// datagram socket (Linux net.ipv4.ping_group_range, macOS) is tried first
+// so pinging works without CAP_NET_RAW; raw sockets are the fallback.
+func listen(fam family) (conn *icmp.PacketConn, dgram bool, err error) {
+    if os.Geteuid() != 0 {
+        if conn, err := icmp.ListenPacket(fam.dgram, ""); err == nil {
+            return conn, true, nil
+        }
+    }
+    conn, err = icmp.ListenPacket(fam.raw, "")
+    return conn, false, err
+}
+
+// Ping sends one ICMP Echo and reports whether a reply arrived within
+// timeout. An error means the echo could not be attempted at all.
 func Ping(ctx context.Context, ip string, timeout time.Duration) (bool, error) {
-    // fallback: if no raw privilege, use system ping
-    handle, err := pcap.OpenLive("", 65535, false, pcap.BlockForever)
-    if err != nil {
-        return systemPing(ip, timeout)
+    _, err := Echo(ctx, ip, timeout)
+    if errors.Is(err, ErrNoReply) {
+        return false, nil
     }
-    defer handle.Close()
+    return err == nil, err
+}
 
-    // Build ICMP packet using gopacket
-    icmp := layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0), Id: 0x1234, Seq: 1}
-    buffer := gopacket.NewSerializeBuffer()
-    opts := gopacket.SerializeOptions{ComputeChecksums: true}
-    if err := icmp.SerializeTo(buffer, opts); err != nil {
-        return false, err
+// Echo sends one ICMP Echo Request to ip and returns the round-trip time
+// of the matching reply.
+func Echo(ctx context.Context, ip string, timeout time.Duration) (time.Duration, error) {
+    dst := net.ParseIP(ip)
+    if dst == nil {
+        return 0, fmt.Errorf("ping: invalid address %q", ip)
+    }
+    fam := family4
+    if dst.To4() == nil {
+        fam = family6
+    }
+    conn, dgram, err := listen(fam)
+    if err != nil {
+        return 0, fmt.Errorf("ping: %w", err)
     }
+    defer conn.Close()
+    defer closeOnCancel(ctx, conn)()
 
-    // NOTE: For brevity we skip crafting IPv4 + Ethernet layers; on Linux AF_INET raw is simpler.
-    // Send/receive steps omitted here.
-    select {
-    case <-time.After(timeout):
-        return false, nil
-    case <-ctx.Done():
-        return false, ctx.Err()
+    // Raw sockets see every echo reply on the host, so replies are matched
+    // on source, identifier and sequence number. Datagram sockets only see
+    // their own, but the kernel rewrites the identifier.
+    id, seq := (os.Getpid()^rand.Int())&0xffff, rand.Intn(0xffff)
+    msg := icmp.Message{Type: fam.echo, Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("goscant")}}
+    b, err := msg.Marshal(nil) // the kernel fills in the ICMPv6 checksum
+    if err != nil {
+        return 0, err
+    }
+    var to net.Addr = &net.IPAddr{IP: dst}
+    if dgram {
+        to = &net.UDPAddr{IP: dst}
+    }
+    start := time.Now()
+    if _, err := conn.WriteTo(b, to); err != nil {
+        return 0, fmt.Errorf("ping: %w", err)
+    }
+    conn.SetReadDeadline(start.Add(timeout))
+    buf := make([]byte, 1500)
+    for {
+        n, from, err := conn.ReadFrom(buf)
+        if err != nil {
+            if ctx.Err() != nil {
+                return 0, ctx.Err()
+            }
+            var ne net.Error
+            if errors.As(err, &ne) && ne.Timeout() {
+                return 0, ErrNoReply
+            }
+            return 0, fmt.Errorf("ping: %w", err)
+        }
+        if !fromAddr(from).Equal(dst) {
+            continue
+        }
+        reply, err := icmp.ParseMessage(fam.proto, buf[:n])
+        if err != nil || reply.Type != fam.reply {
+            continue
+        }
+        if e, ok := reply.Body.(*icmp.Echo); ok && (dgram || e.ID == id) && e.Seq == seq {
+            return time.Since(start), nil
+        }
     }
 }
 
-func systemPing(ip string, timeout time.Duration) (bool, error) {
-    // For Windows or unprivileged environment – rely on exec.Command("ping")
-    return false, errors.New("not implemented")
-}
\ No newline at end of file
+func fromAddr(a net.Addr) net.IP {
+    switch a := a.(type) {
+    case *net.IPAddr:
+        return a.IP
+    case *net.UDPAddr:
+        return a.IP
+    }
+    return nil
+}
@@ -0,0 +1,113 @@
+// File: internal/plan/plan.go
+package plan
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "time"
+
+    "goscant/internal/config"
+)
+
+// Job is one scan within a plan. Empty fields inherit the command-line values.
+type Job struct {
+    Name     string `json:"name"`
+    IP       string `json:"ip"`
+    Port     string `json:"port"`
+    Scan     string `json:"scan"`
+    Output   string `json:"output"`
+    Workers  int    `json:"workers"`
+    Timeout  string `json:"timeout"`
+    Delay    string `json:"delay"`
+    Owners   string `json:"owners"`
+    Triage   string `json:"triage"`
+    Parallel bool   `json:"parallel"` // run alongside the preceding job
+}
+
+// Plan lists the jobs of one invocation, which share a single probe rate.
+type Plan struct {
+    Rate int   `json:"rate"` // probes per second across all jobs, 0 = unlimited
+    Jobs []Job `json:"jobs"`
+}
+
+// Load reads and validates a JSON plan file.
+func Load(path string) (*Plan, error) {
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    p := &Plan{}
+    if err := json.Unmarshal(b, p); err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    if len(p.Jobs) == 0 {
+        return nil, fmt.Errorf("%s: plan has no jobs", path)
+    }
+    outputs := map[string]string{}
+    for i := range p.Jobs {
+        j := &p.Jobs[i]
+        if j.Name == "" {
+            j.Name = fmt.Sprintf("job-%d", i+1)
+        }
+        if j.Output == "" {
+            j.Output = j.Name + ".csv"
+        }
+        if other, ok := outputs[j.Output]; ok {
+            return nil, fmt.Errorf("%s: jobs %q and %q write the same output %s", path, other, j.Name, j.Output)
+        }
+        outputs[j.Output] = j.Name
+        if j.Scan != "" && !config.ValidScanType(j.Scan) {
+            return nil, fmt.Errorf("%s job %q: unknown scan type %q", path, j.Name, j.Scan)
+        }
+        for _, d := range []string{j.Timeout, j.Delay} {
+            if d == "" {
+                continue
+            }
+            if _, err := time.ParseDuration(d); err != nil {
+                return nil, fmt.Errorf("%s job %q: %w", path, j.Name, err)
+            }
+        }
+    }
+    return p, nil
+}
+
+// Config returns the job's configuration layered over base.
+func (j Job) Config(base config.Config) *config.Config {
+    cfg := base
+    set := func(dst *string, v string) {
+        if v != "" {
+            *dst = v
+        }
+    }
+    set(&cfg.IPInput, j.IP)
+    set(&cfg.PortInput, j.Port)
+    set(&cfg.ScanType, j.Scan)
+    set(&cfg.OutputPath, j.Output)
+    set(&cfg.OwnersFile, j.Owners)
+    set(&cfg.TriageFile, j.Triage)
+    if j.Workers > 0 {
+        cfg.NumWorkers = j.Workers
+    }
+    if d, err := time.ParseDuration(j.Timeout); err == nil {
+        cfg.Timeout = d
+    }
+    if d, err := time.ParseDuration(j.Delay); err == nil {
+        cfg.Delay = d
+    }
+    return &cfg
+}
+
+// Stages groups jobs into sequential stages; a job marked parallel joins
+// the stage of the job before it.
+func (p *Plan) Stages() [][]Job {
+    stages := [][]Job{}
+    for i, j := range p.Jobs {
+        if i == 0 || !j.Parallel {
+            stages = append(stages, []Job{j})
+            continue
+        }
+        stages[len(stages)-1] = append(stages[len(stages)-1], j)
+    }
+    return stages
+}
@@ -3,25 +3,102 @@ package prober
 
 import (
     "context"
+    "errors"
+    "fmt"
+    "sort"
+    "sync"
     "time"
 
     "goscant/internal/config"
+    "goscant/internal/fragile"
+    "goscant/internal/hostdown"
     "goscant/internal/input"
     "goscant/internal/logger"
+    "goscant/internal/ratelimit"
     "goscant/internal/scanner"
     "goscant/internal/writer"
 )
 
+// Enricher decorates a result with additional fields before it is written.
+type Enricher interface {
+    Enrich(r *scanner.Result)
+}
+
+// Active is implemented by enrichers that contact the target themselves.
+// They are skipped for fragile targets and hosts marked down.
+type Active interface {
+    Active() bool
+}
+
+// Enrich runs enrichers over r, leaving out active ones for fragile hosts
+// and for results of hosts found or marked down.
+func Enrich(r *scanner.Result, enrichers []Enricher, guard *fragile.Guard) {
+    passive := guard.Fragile(r.IP) || r.Status == scanner.HostDown || errors.Is(r.Err, hostdown.ErrHostDown)
+    for _, e := range enrichers {
+        if a, ok := e.(Active); ok && passive && a.Active() {
+            continue
+        }
+        e.Enrich(r)
+    }
+}
+
+// InFlight records the targets each worker has taken off the queue but not
+// yet submitted, so a checkpoint can include probes a shutdown interrupted.
+type InFlight struct {
+    mu   sync.Mutex
+    held map[int][]input.ProbeTarget
+}
+
+func NewInFlight() *InFlight { return &InFlight{held: map[int][]input.ProbeTarget{}} }
+
+// set records ts as held by worker id; nil or empty clears it.
+func (f *InFlight) set(id int, ts []input.ProbeTarget) {
+    if f == nil {
+        return
+    }
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if len(ts) == 0 {
+        delete(f.held, id)
+        return
+    }
+    f.held[id] = ts
+}
+
+// Targets returns the held targets, by worker.
+func (f *InFlight) Targets() []input.ProbeTarget {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    ids := make([]int, 0, len(f.held))
+    for id := range f.held {
+        ids = append(ids, id)
+    }
+    sort.Ints(ids)
+    var out []input.ProbeTarget
+    for _, id := range ids {
+        out = append(out, f.held[id]...)
+    }
+    return out
+}
+
 type Worker struct {
-    id     int
-    scan   scanner.Scanner
-    writer *writer.CSVWriter
-    cfg    *config.Config
-    log    *logger.Logger
+    id        int
+    scan      scanner.Scanner
+    writer    *writer.CSVWriter
+    enrichers []Enricher
+    limiter   *ratelimit.Limiter
+    guard     *fragile.Guard
+    down      *hostdown.Policy
+    cfg       *config.Config
+    log       *logger.Logger
+    inflight  *InFlight
 }
 
-func New(id int, s scanner.Scanner, w *writer.CSVWriter, cfg *config.Config, log *logger.Logger) *Worker {
-    return &Worker{id: id, scan: s, writer: w, cfg: cfg, log: log}
+// Track makes w record its in-flight targets in f. Call before Run.
+func (w *Worker) Track(f *InFlight) { w.inflight = f }
+
+func New(id int, s scanner.Scanner, w *writer.CSVWriter, enrichers []Enricher, limiter *ratelimit.Limiter, guard *fragile.Guard, down *hostdown.Policy, cfg *config.Config, log *logger.Logger) *Worker {
+    return &Worker{id: id, scan: s, writer: w, enrichers: enrichers, limiter: limiter, guard: guard, down: down, cfg: cfg, log: log}
 }
 
 func (w *Worker) Run(ctx context.Context, tasks <-chan input.ProbeTarget) {
@@ -31,10 +108,165 @@ func (w *Worker) Run(ctx context.Context, tasks <-chan input.ProbeTarget) {
             return
         case t, ok := <-tasks:
             if !ok { return }
-            res := w.scan.Scan(ctx, t.IP, t.Port)
+            if w.down.Down(t.IP) {
+                w.fastFail(t)
+                continue
+            }
+            w.inflight.set(w.id, []input.ProbeTarget{t})
+            res, ok := w.probe(ctx, t)
+            if !ok {
+                return // interrupted probe: still in flight, so the checkpoint keeps it
+            }
+            res.Time = time.Now()
+            Enrich(&res, w.enrichers, w.guard)
             w.writer.Submit(res)
-            w.log.Debugf("[WRK-%d] scanned %s:%d -> %v", w.id, t.IP, t.Port, res.Status)
+            w.inflight.set(w.id, nil)
+            w.log.Debugf("[WRK-%d] scanned %s:%d -> %v (attempts=%d)", w.id, t.IP, t.Port, res.Status, res.Attempts)
+            time.Sleep(w.cfg.Delay)
+        }
+    }
+}
+
+// probe scans t, retrying up to --retries times while the outcome is
+// Filtered or Error. Fragile targets go through the guard and its engine.
+// It returns false if ctx was cancelled.
+func (w *Worker) probe(ctx context.Context, t input.ProbeTarget) (scanner.Result, bool) {
+    var res scanner.Result
+    engine := w.scan
+    fragileHost := w.guard.Fragile(t.IP)
+    if fragileHost {
+        // the safe engine is header-only TCP; UDP probes carry payloads
+        safe, ok := w.guard.Engine()
+        if !ok || t.Proto == "udp" {
+            return scanner.Result{IP: t.IP, Port: t.Port, Proto: w.proto(t), Status: scanner.Error, Err: fragile.ErrSkipped}, true
+        }
+        engine = safe
+    }
+    for attempt := 1; ; attempt++ {
+        if err := w.limiter.Wait(ctx); err != nil {
+            return res, false
+        }
+        if fragileHost {
+            release, err := w.guard.Acquire(ctx, t.IP)
+            if err != nil {
+                return res, false
+            }
+            res = engine.Scan(ctx, t)
+            release()
+        } else {
+            res = engine.Scan(ctx, t)
+        }
+        if ctx.Err() != nil {
+            return res, false
+        }
+        res.Attempts = attempt
+        if w.markDown(res) || !retryable(res.Status) || attempt > w.cfg.Retries {
+            return res, true
+        }
+    }
+}
+
+// markDown feeds res to the host-down policy, logging if it marks the host.
+func (w *Worker) markDown(res scanner.Result) bool {
+    if !w.down.Observe(res) {
+        return false
+    }
+    w.log.Warn(fmt.Sprintf("[WRK-%d] %s marked down after %d consecutive errors (last: %v); failing its remaining targets fast", w.id, res.IP, w.cfg.HostDownAfter, res.Err))
+    return true
+}
+
+// fastFail records t as failed without probing it.
+func (w *Worker) fastFail(t input.ProbeTarget) {
+    res := w.down.Fail(t, w.proto(t))
+    res.Time = time.Now()
+    Enrich(&res, w.enrichers, w.guard)
+    w.writer.Submit(res)
+}
+
+// proto is the protocol recorded for t when it is not probed.
+func (w *Worker) proto(t input.ProbeTarget) string {
+    if t.Proto != "" {
+        return t.Proto
+    }
+    return w.cfg.ScanType
+}
+
+// retryable reports whether a probe outcome may change on another attempt.
+func retryable(st scanner.Status) bool {
+    return st == scanner.Filtered || st == scanner.Error
+}
+
+// RunBatches probes one host's ports per batch with a single send pass.
+// Ports left Filtered or Error are re-sent together on each retry. Batches
+// for fragile hosts are probed one port at a time instead.
+func (w *Worker) RunBatches(ctx context.Context, batches <-chan []input.ProbeTarget) {
+    bs := w.scan.(scanner.BatchScanner)
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case batch, ok := <-batches:
+            if !ok { return }
+            if w.down.Down(batch[0].IP) {
+                for _, t := range batch {
+                    w.fastFail(t)
+                }
+                continue
+            }
+            // An interrupted batch stays in flight for the checkpoint
+            w.inflight.set(w.id, batch)
+            if w.guard.Fragile(batch[0].IP) {
+                for i, t := range batch {
+                    res, ok := w.probe(ctx, t)
+                    if !ok {
+                        return
+                    }
+                    res.Time = time.Now()
+                    Enrich(&res, w.enrichers, w.guard)
+                    w.writer.Submit(res)
+                    w.inflight.set(w.id, batch[i+1:])
+                }
+                continue
+            }
+            results := make([]scanner.Result, len(batch))
+            todo := make([]int, len(batch)) // indices still to (re)probe
+            for i := range todo {
+                todo[i] = i
+            }
+            for attempt := 1; len(todo) > 0 && attempt <= w.cfg.Retries+1; attempt++ {
+                ports := make([]int, len(todo))
+                for j, i := range todo {
+                    if err := w.limiter.Wait(ctx); err != nil { return }
+                    ports[j] = batch[i].Port
+                }
+                out := bs.ScanBatch(ctx, batch[0].IP, ports)
+                if ctx.Err() != nil {
+                    return
+                }
+                next := todo[:0]
+                down := false
+                for j, i := range todo {
+                    results[i] = out[j]
+                    results[i].Attempts = attempt
+                    down = w.markDown(out[j]) || down
+                    if retryable(out[j].Status) {
+                        next = append(next, i)
+                    }
+                }
+                todo = next
+                if down {
+                    break
+                }
+            }
+            done := time.Now()
+            for _, res := range results {
+                res.Time = done
+                Enrich(&res, w.enrichers, w.guard)
+                w.writer.Submit(res)
+            }
+            w.inflight.set(w.id, nil)
+            w.log.Debugf("[WRK-%d] scanned %s batch of %d ports", w.id, batch[0].IP, len(batch))
             time.Sleep(w.cfg.Delay)
         }
     }
-}
\ No newline at end of file
+}
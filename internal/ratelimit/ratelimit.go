@@ -0,0 +1,126 @@
+// File: internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// Limiter spaces probes to at most rate per second across all callers.
+// A nil Limiter or a rate of zero means unlimited.
+type Limiter struct {
+    mu       sync.Mutex
+    interval time.Duration
+    next     time.Time
+    resume   chan struct{} // non-nil while paused
+    backoff  time.Duration // extra delay per Wait, see SetBackoff
+}
+
+func New(rate int) *Limiter {
+    l := &Limiter{}
+    l.SetRate(rate)
+    return l
+}
+
+// SetRate changes the limit; it is safe to call while probes are running.
+func (l *Limiter) SetRate(rate int) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if rate <= 0 {
+        l.interval = 0
+        return
+    }
+    l.interval = time.Second / time.Duration(rate)
+}
+
+// Rate returns the current limit in probes per second (0 = unlimited).
+func (l *Limiter) Rate() int {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.interval == 0 {
+        return 0
+    }
+    return int(time.Second / l.interval)
+}
+
+// Pause holds every Wait caller until Resume.
+func (l *Limiter) Pause() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.resume == nil {
+        l.resume = make(chan struct{})
+    }
+}
+
+// Resume releases callers held by Pause.
+func (l *Limiter) Resume() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.resume != nil {
+        close(l.resume)
+        l.resume = nil
+    }
+}
+
+// Paused reports whether probing is currently paused.
+func (l *Limiter) Paused() bool {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.resume != nil
+}
+
+// SetBackoff adds d to every Wait on top of the rate limit, slowing each
+// worker down without changing the configured rate. Zero removes it.
+func (l *Limiter) SetBackoff(d time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.backoff = d
+}
+
+// Backoff returns the delay set by SetBackoff.
+func (l *Limiter) Backoff() time.Duration {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.backoff
+}
+
+// Wait blocks until the caller may send its next probe or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+    if l == nil {
+        return nil
+    }
+    l.mu.Lock()
+    for l.resume != nil {
+        ch := l.resume
+        l.mu.Unlock()
+        select {
+        case <-ch:
+        case <-ctx.Done():
+            return ctx.Err()
+        }
+        l.mu.Lock()
+    }
+    wait := l.backoff
+    if l.interval > 0 {
+        now := time.Now()
+        if l.next.Before(now) {
+            l.next = now
+        }
+        wait += l.next.Sub(now)
+        l.next = l.next.Add(l.interval)
+    }
+    l.mu.Unlock()
+
+    if wait <= 0 {
+        return nil
+    }
+    t := time.NewTimer(wait)
+    defer t.Stop()
+    select {
+    case <-t.C:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
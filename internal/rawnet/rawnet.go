@@ -0,0 +1,100 @@
+// File: internal/rawnet/rawnet.go
+
+// Package rawnet is the only place that opens raw sockets or packet
+// captures. Per-OS files supply listen and openHandle; engines see one API.
+package rawnet
+
+import (
+    "errors"
+    "fmt"
+    "net"
+    "strings"
+    "time"
+
+    "github.com/google/gopacket"
+    "github.com/google/gopacket/layers"
+    "github.com/google/gopacket/pcap"
+)
+
+// Capture is a live, filtered packet capture on one interface.
+type Capture interface {
+    ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+    LinkType() layers.LinkType
+    Close()
+}
+
+// ErrTimeout is returned by Capture.ReadPacketData when no packet arrived
+// within the poll interval; receive loops should simply read again.
+var ErrTimeout = pcap.NextErrorTimeoutExpired
+
+// ErrUnsupported reports an operation this platform cannot perform.
+var ErrUnsupported = errors.New("rawnet: not supported on this platform")
+
+const (
+    snapLen      = 128                    // headers only
+    pollInterval = 100 * time.Millisecond // lets receive loops notice Close
+)
+
+// Listen opens a raw IP socket. network is as for net.ListenPacket, e.g.
+// "ip4:tcp" or "ip6:132"; the wildcard address of the family is bound.
+func Listen(network string) (net.PacketConn, error) {
+    laddr := "0.0.0.0"
+    if strings.HasPrefix(network, "ip6:") {
+        laddr = "::"
+    }
+    conn, err := listen(network, laddr)
+    if err != nil {
+        return nil, fmt.Errorf("raw %s socket: %w", network, err)
+    }
+    if tracing() {
+        return tracedConn{conn, network}, nil
+    }
+    return conn, nil
+}
+
+// Capable reports whether this process may send raw TCP probes.
+func Capable() bool {
+    conn, err := Listen("ip4:tcp")
+    if err != nil {
+        return false
+    }
+    conn.Close()
+    return true
+}
+
+// OpenCapture captures packets matching the BPF filter on the interface
+// that owns address src.
+func OpenCapture(src net.IP, filter string) (Capture, error) {
+    dev, err := deviceFor(src)
+    if err != nil {
+        return nil, err
+    }
+    h, err := openHandle(dev)
+    if err != nil {
+        return nil, err
+    }
+    if err := h.SetBPFFilter(filter); err != nil {
+        h.Close()
+        return nil, err
+    }
+    if tracing() {
+        return tracedCapture{h}, nil
+    }
+    return h, nil
+}
+
+// deviceFor returns the capture device carrying address src.
+func deviceFor(src net.IP) (string, error) {
+    devs, err := pcap.FindAllDevs()
+    if err != nil {
+        return "", err
+    }
+    for _, d := range devs {
+        for _, a := range d.Addresses {
+            if a.IP.Equal(src) {
+                return d.Name, nil
+            }
+        }
+    }
+    return "", errors.New("no capture device has address " + src.String())
+}
@@ -0,0 +1,36 @@
+// File: internal/rawnet/rawnet_bsd.go
+//go:build darwin || freebsd || netbsd || openbsd || dragonfly
+
+package rawnet
+
+import (
+    "net"
+
+    "github.com/google/gopacket/pcap"
+)
+
+// BSD raw sockets send fine, but the kernel never delivers TCP or UDP to
+// them, which is why TCP engines read replies from a capture instead.
+func listen(network, laddr string) (net.PacketConn, error) {
+    return net.ListenPacket(network, laddr)
+}
+
+// BPF holds packets until its buffer fills unless immediate mode is set,
+// which would add up to a poll interval to every measured latency.
+func openHandle(dev string) (*pcap.Handle, error) {
+    in, err := pcap.NewInactiveHandle(dev)
+    if err != nil {
+        return nil, err
+    }
+    defer in.CleanUp()
+    if err := in.SetSnapLen(snapLen); err != nil {
+        return nil, err
+    }
+    if err := in.SetTimeout(pollInterval); err != nil {
+        return nil, err
+    }
+    if err := in.SetImmediateMode(true); err != nil {
+        return nil, err
+    }
+    return in.Activate()
+}
@@ -0,0 +1,20 @@
+// File: internal/rawnet/rawnet_linux.go
+//go:build linux
+
+package rawnet
+
+import (
+    "net"
+
+    "github.com/google/gopacket/pcap"
+)
+
+// Linux raw IP sockets accept any protocol; the kernel builds the IP header.
+func listen(network, laddr string) (net.PacketConn, error) {
+    return net.ListenPacket(network, laddr)
+}
+
+// libpcap captures through AF_PACKET here and honours the read timeout.
+func openHandle(dev string) (*pcap.Handle, error) {
+    return pcap.OpenLive(dev, snapLen, false, pollInterval)
+}
@@ -0,0 +1,14 @@
+// File: internal/rawnet/rawnet_other.go
+//go:build !linux && !windows && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package rawnet
+
+import (
+    "net"
+
+    "github.com/google/gopacket/pcap"
+)
+
+func listen(network, laddr string) (net.PacketConn, error) { return nil, ErrUnsupported }
+
+func openHandle(dev string) (*pcap.Handle, error) { return nil, ErrUnsupported }
@@ -0,0 +1,31 @@
+// File: internal/rawnet/rawnet_windows.go
+//go:build windows
+
+package rawnet
+
+import (
+    "errors"
+    "net"
+    "strings"
+
+    "github.com/google/gopacket/pcap"
+)
+
+// Windows silently drops TCP and UDP written to raw sockets, so refuse
+// them up front; engines then fall back to connect scans.
+func listen(network, laddr string) (net.PacketConn, error) {
+    if strings.HasSuffix(network, ":tcp") || strings.HasSuffix(network, ":udp") ||
+        strings.HasSuffix(network, ":6") || strings.HasSuffix(network, ":17") {
+        return nil, ErrUnsupported
+    }
+    return net.ListenPacket(network, laddr)
+}
+
+// Captures need the Npcap driver; WinPcap is unmaintained and misses
+// loopback and IPv6 traffic.
+func openHandle(dev string) (*pcap.Handle, error) {
+    if !strings.Contains(pcap.Version(), "Npcap") {
+        return nil, errors.New("rawnet: Npcap is required for packet capture")
+    }
+    return pcap.OpenLive(dev, snapLen, false, pollInterval)
+}
@@ -0,0 +1,95 @@
+// File: internal/rawnet/trace.go
+package rawnet
+
+import (
+    "encoding/hex"
+    "net"
+
+    "github.com/google/gopacket"
+    "github.com/google/gopacket/layers"
+)
+
+// tracer dumps raw packets exchanged with a few selected targets. It is
+// set once by Trace, before any socket or capture is opened.
+var tracer struct {
+    targets []*net.IPNet
+    logf    func(format string, v ...interface{})
+}
+
+// Trace makes sockets and captures opened afterwards hex-dump every packet
+// to or from an address in targets through logf.
+func Trace(targets []*net.IPNet, logf func(format string, v ...interface{})) {
+    tracer.targets, tracer.logf = targets, logf
+}
+
+func tracing() bool { return len(tracer.targets) > 0 }
+
+func traced(ip net.IP) bool {
+    for _, n := range tracer.targets {
+        if ip != nil && n.Contains(ip) {
+            return true
+        }
+    }
+    return false
+}
+
+func dump(what string, peer net.IP, b []byte) {
+    tracer.logf("%s %s, %d bytes\n%s", what, peer, len(b), hex.Dump(b))
+}
+
+// tracedConn dumps what a raw socket sends to and receives from traced
+// addresses.
+type tracedConn struct {
+    net.PacketConn
+    network string
+}
+
+func peerIP(a net.Addr) net.IP {
+    if a, ok := a.(*net.IPAddr); ok {
+        return a.IP
+    }
+    return nil
+}
+
+func (c tracedConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+    if ip := peerIP(addr); traced(ip) {
+        dump("send "+c.network+" to", ip, b)
+    }
+    return c.PacketConn.WriteTo(b, addr)
+}
+
+func (c tracedConn) ReadFrom(b []byte) (int, net.Addr, error) {
+    n, addr, err := c.PacketConn.ReadFrom(b)
+    if ip := peerIP(addr); err == nil && traced(ip) {
+        dump("recv "+c.network+" from", ip, b[:n])
+    }
+    return n, addr, err
+}
+
+// tracedCapture dumps captured frames whose IP source or destination is
+// traced. Frames are shown whole, link header included, so offload and
+// checksum problems are visible as the NIC delivered them.
+type tracedCapture struct {
+    Capture
+}
+
+func (c tracedCapture) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+    data, ci, err := c.Capture.ReadPacketData()
+    if err != nil {
+        return data, ci, err
+    }
+    var src, dst net.IP
+    pkt := gopacket.NewPacket(data, c.LinkType(), gopacket.NoCopy)
+    if ip4, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4); ok {
+        src, dst = ip4.SrcIP, ip4.DstIP
+    } else if ip6, ok := pkt.Layer(layers.LayerTypeIPv6).(*layers.IPv6); ok {
+        src, dst = ip6.SrcIP, ip6.DstIP
+    }
+    switch {
+    case traced(src):
+        dump("capture from", src, data)
+    case traced(dst):
+        dump("capture to", dst, data)
+    }
+    return data, ci, err
+}
@@ -0,0 +1,94 @@
+// File: internal/rdns/rdns.go
+// Package rdns annotates results with the PTR name of their address.
+// Lookups go to the resolver, never to the target, so the enricher is
+// passive and safe for fragile hosts.
+package rdns
+
+import (
+    "context"
+    "net"
+    "strings"
+    "sync"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// Columns lists the result fields filled in by a Resolver.
+var Columns = []string{"hostname"}
+
+// lookupTimeout bounds one PTR query; a slow resolver leaves the name empty.
+const lookupTimeout = 2 * time.Second
+
+// Resolver looks up each address once and caches the answer. Addresses
+// without a PTR record, or whose lookup failed, get an empty hostname.
+type Resolver struct {
+    ctx context.Context
+
+    mu    sync.Mutex
+    names map[string]*entry
+}
+
+type entry struct {
+    done chan struct{}
+    name string
+}
+
+// New returns a Resolver whose lookups stop when ctx is done.
+func New(ctx context.Context) *Resolver {
+    return &Resolver{ctx: ctx, names: map[string]*entry{}}
+}
+
+// Prime starts resolving ips in the background with workers concurrent
+// lookups, so names are usually ready by the time results arrive.
+func (r *Resolver) Prime(ips []string, workers int) {
+    ch := make(chan string)
+    for i := 0; i < workers; i++ {
+        go func() {
+            for ip := range ch {
+                r.Name(ip)
+            }
+        }()
+    }
+    go func() {
+        defer close(ch)
+        for _, ip := range ips {
+            select {
+            case ch <- ip:
+            case <-r.ctx.Done():
+                return
+            }
+        }
+    }()
+}
+
+// Name returns the first PTR name of ip without its trailing dot, waiting
+// for a lookup already in flight rather than starting another.
+func (r *Resolver) Name(ip string) string {
+    r.mu.Lock()
+    e, ok := r.names[ip]
+    if !ok {
+        e = &entry{done: make(chan struct{})}
+        r.names[ip] = e
+    }
+    r.mu.Unlock()
+    if ok {
+        <-e.done
+        return e.name
+    }
+    ctx, cancel := context.WithTimeout(r.ctx, lookupTimeout)
+    defer cancel()
+    if names, err := net.DefaultResolver.LookupAddr(ctx, ip); err == nil && len(names) > 0 {
+        e.name = strings.TrimSuffix(names[0], ".")
+    }
+    close(e.done)
+    return e.name
+}
+
+// Enrich annotates r with the hostname of its address.
+func (r *Resolver) Enrich(res *scanner.Result) {
+    if res.Meta == nil {
+        res.Meta = map[string]string{}
+    }
+    res.Meta["hostname"] = r.Name(res.IP)
+}
@@ -0,0 +1,277 @@
+// File: internal/results/reader.go
+package results
+
+import (
+    "bufio"
+    "bytes"
+    "database/sql"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+    "goscant/internal/seal"
+    "goscant/internal/writer"
+
+    _ "modernc.org/sqlite"
+)
+
+// fixed are the columns always written by writer.CSVWriter; any other
+// column is loaded into Result.Meta.
+var fixed = map[string]bool{
+    "timestamp": true, "dst_ip": true, "addr_family": true, "dst_port": true,
+    "proto": true, "status": true, "latency_ms": true, "attempts": true, "confidence": true,
+    "engine": true,
+}
+
+// ReadCSV loads rows produced by an earlier run. Columns are located by
+// header name, so files from older versions or with extra columns load too.
+func ReadCSV(path string) ([]scanner.Result, error) {
+    f, err := seal.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    r := csv.NewReader(f)
+    r.FieldsPerRecord = -1
+
+    header, err := r.Read()
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    col := map[string]int{}
+    for i, h := range header {
+        col[h] = i
+    }
+    for _, need := range []string{"dst_ip", "dst_port", "status"} {
+        if _, ok := col[need]; !ok {
+            return nil, fmt.Errorf("%s: missing %q column", path, need)
+        }
+    }
+
+    out := []scanner.Result{}
+    for line := 2; ; line++ {
+        rec, err := r.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+        if len(rec) < len(header) {
+            return nil, fmt.Errorf("%s line %d: expected %d fields, got %d", path, line, len(header), len(rec))
+        }
+        port, err := strconv.Atoi(rec[col["dst_port"]])
+        if err != nil {
+            return nil, fmt.Errorf("%s line %d: bad port: %w", path, line, err)
+        }
+        st, ok := scanner.ParseStatus(rec[col["status"]])
+        if !ok {
+            return nil, fmt.Errorf("%s line %d: unknown status %q", path, line, rec[col["status"]])
+        }
+        res := scanner.Result{IP: writer.ParseAddr(rec[col["dst_ip"]]), Port: port, Proto: "tcp", Status: st}
+        if i, ok := col["proto"]; ok && rec[i] != "" {
+            res.Proto = rec[i]
+        }
+        if i, ok := col["latency_ms"]; ok {
+            res.LatencyMS, _ = strconv.ParseInt(rec[i], 10, 64)
+        }
+        if i, ok := col["attempts"]; ok {
+            res.Attempts, _ = strconv.Atoi(rec[i])
+        }
+        if i, ok := col["confidence"]; ok {
+            res.Confidence, _ = scanner.ParseConfidence(rec[i])
+        }
+        if i, ok := col["engine"]; ok {
+            res.Engine = rec[i]
+        }
+        if i, ok := col["timestamp"]; ok {
+            res.Time, _ = time.Parse(time.RFC3339, rec[i])
+        }
+        if i, ok := col["banner"]; ok {
+            res.Banner = rec[i]
+        }
+        if i, ok := col["service"]; ok {
+            res.Service = rec[i]
+        }
+        for i, h := range header {
+            if fixed[h] || h == "banner" || h == "service" {
+                continue
+            }
+            if res.Meta == nil {
+                res.Meta = map[string]string{}
+            }
+            res.Meta[h] = rec[i]
+        }
+        out = append(out, res)
+    }
+    return out, nil
+}
+
+// Read loads an earlier run's output, choosing the parser by extension:
+// ".jsonl" and ".ndjson" files are read with ReadJSONL, ".json" with
+// ReadJSON, ".sqlite" and ".db" with ReadSQLite, anything else as CSV. A
+// directory (partitioned output) is read file by file, and so are the
+// chunks of a rotated output named by its unrotated path.
+func Read(path string) ([]scanner.Result, error) {
+    st, err := os.Stat(path)
+    if err == nil && st.IsDir() {
+        return readDir(path)
+    }
+    if os.IsNotExist(err) {
+        if chunks := writer.RotatedChunks(path); len(chunks) > 0 {
+            return readChunks(chunks)
+        }
+    }
+    switch {
+    case strings.HasSuffix(path, ".jsonl"), strings.HasSuffix(path, ".ndjson"):
+        return ReadJSONL(path)
+    case strings.HasSuffix(path, ".json"):
+        return ReadJSON(path)
+    case strings.HasSuffix(path, ".sqlite"), strings.HasSuffix(path, ".db"):
+        return ReadSQLite(path)
+    case strings.HasSuffix(path, ".xml"):
+        return nil, fmt.Errorf("%s: xml output holds open ports only; scan them again with --ip %s --nmap-ports", path, path)
+    case strings.HasSuffix(path, ".gnmap"):
+        return nil, fmt.Errorf("%s: gnmap output is for grep and cannot be read back; use csv, jsonl or json", path)
+    }
+    return ReadCSV(path)
+}
+
+// readDir reads every results.csv and results.jsonl file below dir.
+func readChunks(chunks []string) ([]scanner.Result, error) {
+    out := []scanner.Result{}
+    for _, chunk := range chunks {
+        rows, err := Read(chunk)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, rows...)
+    }
+    return out, nil
+}
+
+func readDir(dir string) ([]scanner.Result, error) {
+    out := []scanner.Result{}
+    err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+        if err != nil || d.IsDir() {
+            return err
+        }
+        if name := d.Name(); name != "results.csv" && name != "results.jsonl" && name != "results.ndjson" && name != "results.sqlite" {
+            return nil
+        }
+        rows, err := Read(path)
+        out = append(out, rows...)
+        return err
+    })
+    return out, err
+}
+
+// ReadJSONL loads rows written by the jsonl output format.
+func ReadJSONL(path string) ([]scanner.Result, error) {
+    f, err := seal.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    out := []scanner.Result{}
+    in := bufio.NewScanner(f)
+    in.Buffer(make([]byte, 64<<10), 1<<20)
+    for line := 1; in.Scan(); line++ {
+        if len(bytes.TrimSpace(in.Bytes())) == 0 {
+            continue
+        }
+        var row writer.JSONRow
+        if err := json.Unmarshal(in.Bytes(), &row); err != nil {
+            return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+        }
+        res, err := fromJSONRow(row)
+        if err != nil {
+            return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+        }
+        out = append(out, res)
+    }
+    return out, in.Err()
+}
+
+// ReadJSON loads the results of a document written by the json output
+// format. A document cut short by a crashed run is an error.
+func ReadJSON(path string) ([]scanner.Result, error) {
+    f, err := seal.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    var doc writer.JSONDoc
+    if err := json.NewDecoder(f).Decode(&doc); err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    out := make([]scanner.Result, 0, len(doc.Results))
+    for i, row := range doc.Results {
+        res, err := fromJSONRow(row)
+        if err != nil {
+            return nil, fmt.Errorf("%s result %d: %w", path, i+1, err)
+        }
+        out = append(out, res)
+    }
+    return out, nil
+}
+
+// ReadSQLite loads the results of the latest scan stored in a database
+// written by the sqlite output format.
+func ReadSQLite(path string) ([]scanner.Result, error) {
+    if _, err := os.Stat(path); err != nil {
+        return nil, err // sql.Open would create it
+    }
+    db, err := sql.Open("sqlite", path)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+    rows, err := db.Query(`SELECT timestamp, dst_ip, dst_port, proto, status, latency_ms, attempts,
+        coalesce(confidence, ''), coalesce(engine, ''), coalesce(banner, ''), coalesce(service, ''), coalesce(meta, '')
+        FROM results WHERE scan_id = (SELECT max(id) FROM scans)`)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    defer rows.Close()
+    out := []scanner.Result{}
+    for rows.Next() {
+        var row writer.JSONRow
+        var ts, meta string
+        if err := rows.Scan(&ts, &row.DstIP, &row.DstPort, &row.Proto, &row.Status, &row.LatencyMS, &row.Attempts,
+            &row.Confidence, &row.Engine, &row.Banner, &row.Service, &meta); err != nil {
+            return nil, fmt.Errorf("%s: %w", path, err)
+        }
+        row.Timestamp, _ = time.Parse(time.RFC3339Nano, ts)
+        if meta != "" {
+            if err := json.Unmarshal([]byte(meta), &row.Meta); err != nil {
+                return nil, fmt.Errorf("%s: meta of %s:%d: %w", path, row.DstIP, row.DstPort, err)
+            }
+        }
+        res, err := fromJSONRow(row)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", path, err)
+        }
+        out = append(out, res)
+    }
+    return out, rows.Err()
+}
+
+// fromJSONRow converts a row of the json, jsonl and sqlite formats.
+func fromJSONRow(row writer.JSONRow) (scanner.Result, error) {
+    st, ok := scanner.ParseStatus(row.Status)
+    if !ok {
+        return scanner.Result{}, fmt.Errorf("unknown status %q", row.Status)
+    }
+    conf, _ := scanner.ParseConfidence(row.Confidence)
+    return scanner.Result{IP: writer.ParseAddr(row.DstIP), Port: row.DstPort, Proto: row.Proto, Status: st,
+        LatencyMS: row.LatencyMS, Attempts: row.Attempts, Confidence: conf, Engine: row.Engine, Banner: row.Banner, Service: row.Service, Meta: row.Meta, Time: row.Timestamp}, nil
+}
@@ -0,0 +1,49 @@
+// File: internal/scanner/banner.go
+package scanner
+
+import (
+    "context"
+    "net"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// GrabBanner connects to ip:port and returns up to max bytes the service
+// sends on its own within timeout (SSH/FTP/SMTP greetings and the like).
+func GrabBanner(ctx context.Context, ip string, port int, timeout time.Duration, max int) (string, error) {
+    d := net.Dialer{Timeout: timeout}
+    conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+    if err != nil {
+        return "", err
+    }
+    defer conn.Close()
+    return readBanner(conn, timeout, max)
+}
+
+// readBanner returns up to max bytes conn sends within timeout.
+func readBanner(conn net.Conn, timeout time.Duration, max int) (string, error) {
+    conn.SetReadDeadline(time.Now().Add(timeout))
+    buf := make([]byte, max)
+    n, err := conn.Read(buf)
+    if n == 0 && err != nil {
+        if ne, ok := err.(net.Error); ok && ne.Timeout() {
+            return "", nil // silent service, e.g. HTTP
+        }
+        return "", err
+    }
+    return SanitizeBanner(buf[:n]), nil
+}
+
+// SanitizeBanner makes raw service bytes safe for one-line output.
+func SanitizeBanner(b []byte) string {
+    return strings.TrimSpace(strings.Map(func(r rune) rune {
+        switch {
+        case r == '\r' || r == '\n' || r == '\t':
+            return ' '
+        case r < 0x20 || r == 0x7f || r == 0xfffd:
+            return '.'
+        }
+        return r
+    }, string(b)))
+}
@@ -0,0 +1,12 @@
+// File: internal/scanner/capture.go
+package scanner
+
+import (
+    "fmt"
+    "net"
+)
+
+// synReplyFilter matches SYN-ACK and RST segments addressed to src.
+func synReplyFilter(src net.IP) string {
+    return fmt.Sprintf("tcp and dst host %s and (tcp[tcpflags] & tcp-rst != 0 or tcp[tcpflags] & (tcp-syn|tcp-ack) == (tcp-syn|tcp-ack))", src)
+}
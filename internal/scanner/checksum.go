@@ -0,0 +1,34 @@
+// File: internal/scanner/checksum.go
+package scanner
+
+import (
+    "encoding/binary"
+    "net"
+)
+
+// inetChecksum computes the RFC 1071 ones' complement checksum.
+func inetChecksum(b []byte) uint16 {
+    var sum uint32
+    for i := 0; i+1 < len(b); i += 2 {
+        sum += uint32(binary.BigEndian.Uint16(b[i:]))
+    }
+    if len(b)%2 == 1 {
+        sum += uint32(b[len(b)-1]) << 8
+    }
+    for sum > 0xffff {
+        sum = (sum >> 16) + (sum & 0xffff)
+    }
+    return ^uint16(sum)
+}
+
+// pseudoChecksum computes a TCP/UDP checksum over the IPv4 pseudo-header
+// followed by seg.
+func pseudoChecksum(src, dst net.IP, proto byte, seg []byte) uint16 {
+    b := make([]byte, 12+len(seg))
+    copy(b[0:4], src.To4())
+    copy(b[4:8], dst.To4())
+    b[9] = proto
+    binary.BigEndian.PutUint16(b[10:12], uint16(len(seg)))
+    copy(b[12:], seg)
+    return inetChecksum(b)
+}
@@ -0,0 +1,62 @@
+// File: internal/scanner/confidence.go
+package scanner
+
+import "strings"
+
+// Confidence grades how much a result's status can be trusted.
+type Confidence int
+
+const (
+    Unrated Confidence = iota // not yet assessed; see Rate
+    Low
+    Medium
+    High
+)
+
+func (c Confidence) String() string {
+    switch c {
+    case Low:
+        return "low"
+    case Medium:
+        return "medium"
+    case High:
+        return "high"
+    }
+    return ""
+}
+
+// ParseConfidence is the inverse of Confidence.String (case-insensitive).
+func ParseConfidence(s string) (Confidence, bool) {
+    for _, c := range []Confidence{Low, Medium, High} {
+        if strings.EqualFold(strings.TrimSpace(s), c.String()) {
+            return c, true
+        }
+    }
+    return Unrated, false
+}
+
+// filteredHighAttempts is how many silent probes make FILTERED certain.
+const filteredHighAttempts = 4 // the first probe plus three retries
+
+// Rate grades r from its status and evidence: OPEN and CLOSED come from a
+// reply matched to our probe and are high; FILTERED is low after one probe,
+// medium after two or three and high after four; ERROR and HOST_DOWN are
+// low. Engines only
+// set Result.Confidence themselves when a reply is weaker than usual.
+func Rate(r Result) Confidence {
+    if r.Confidence != Unrated {
+        return r.Confidence
+    }
+    switch r.Status {
+    case Open, Closed:
+        return High
+    case Filtered:
+        switch {
+        case r.Attempts >= filteredHighAttempts:
+            return High
+        case r.Attempts > 1:
+            return Medium
+        }
+    }
+    return Low
+}
@@ -0,0 +1,181 @@
+// File: internal/scanner/ipproto.go
+package scanner
+
+import (
+    "context"
+    "encoding/binary"
+    "errors"
+    "math/rand"
+    "net"
+    "strconv"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/models"
+    "goscant/internal/rawnet"
+)
+
+// ----- IP protocol scanner -----
+
+// ipProtoScanner reports which IP protocols a host speaks (nmap -sO style).
+// The target's port is the IP protocol number: any reply in that protocol
+// means Open, ICMP protocol-unreachable means Closed, and other unreachables
+// or silence mean Filtered.
+type ipProtoScanner struct {
+    timeouts *timeouts
+}
+
+func NewIPProtoScanner(cfg *config.Config) Scanner {
+    return &ipProtoScanner{timeouts: newTimeouts(cfg)}
+}
+
+func (s *ipProtoScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    res := s.scan(ctx, t)
+    res.Engine = "ipproto"
+    return res
+}
+
+func (s *ipProtoScanner) scan(ctx context.Context, t models.ScanTarget) Result {
+    ip, proto := t.IP, t.Port
+    res := Result{IP: ip, Port: proto, Proto: "ip"}
+    dst := net.ParseIP(ip).To4()
+    if dst == nil {
+        res.Status, res.Err = Error, errors.New("protocol scan supports IPv4 targets only")
+        return res
+    }
+    if proto < 0 || proto > 255 {
+        res.Status, res.Err = Error, errors.New("protocol number out of range 0-255")
+        return res
+    }
+    src, err := sourceFor(dst)
+    if err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+
+    icmpConn, err := rawnet.Listen("ip4:icmp")
+    if err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+    defer icmpConn.Close()
+    protoConn := icmpConn
+    if proto != 1 {
+        if protoConn, err = rawnet.Listen("ip4:"+strconv.Itoa(proto)); err != nil {
+            res.Status, res.Err = Error, err
+            return res
+        }
+        defer protoConn.Close()
+    }
+
+    start := time.Now()
+    if _, err := protoConn.WriteTo(ipProtoPayload(proto, src, dst), &net.IPAddr{IP: dst}); err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+    timeout := s.timeouts.For(ip)
+    deadline := start.Add(timeout)
+    if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+        deadline = d
+    }
+
+    verdict := make(chan Status, 2)
+    read := func(c net.PacketConn, classify func([]byte) (Status, bool)) {
+        c.SetReadDeadline(deadline)
+        buf := make([]byte, 1500)
+        for {
+            n, from, err := c.ReadFrom(buf)
+            if err != nil {
+                return
+            }
+            if a, ok := from.(*net.IPAddr); !ok || !a.IP.Equal(dst) {
+                continue
+            }
+            if st, ok := classify(buf[:n]); ok {
+                verdict <- st
+                return
+            }
+        }
+    }
+    go read(icmpConn, func(b []byte) (Status, bool) { return classifyICMPForProto(b, dst, proto) })
+    if proto != 1 {
+        go read(protoConn, func([]byte) (Status, bool) { return Open, true })
+    }
+
+    select {
+    case st := <-verdict:
+        rtt := time.Since(start)
+        s.timeouts.Observe(ip, rtt)
+        res.Status, res.LatencyMS = st, rtt.Milliseconds()
+        if st == Open {
+            res.Confidence = Medium // any packet of the protocol counts, solicited or not
+        }
+    case <-time.After(time.Until(deadline)):
+        res.Status, res.LatencyMS = Filtered, timeout.Milliseconds()
+    case <-ctx.Done():
+        res.Status, res.Err = Error, ctx.Err()
+    }
+    return res
+}
+
+// classifyICMPForProto interprets an ICMP message received while probing proto.
+func classifyICMPForProto(b []byte, dst net.IP, proto int) (Status, bool) {
+    if len(b) < 8 {
+        return 0, false
+    }
+    if b[0] == 0 && proto == 1 { // echo reply
+        return Open, true
+    }
+    if b[0] != 3 { // only destination unreachable carries a verdict
+        return 0, false
+    }
+    orig := b[8:]
+    if len(orig) < 20 {
+        return 0, false
+    }
+    if int(orig[9]) != proto || !net.IP(orig[16:20]).Equal(dst) {
+        return 0, false
+    }
+    switch b[1] {
+    case 2: // protocol unreachable
+        return Closed, true
+    case 3: // port unreachable: the protocol itself is alive
+        return Open, true
+    case 0, 1, 9, 10, 13:
+        return Filtered, true
+    }
+    return 0, false
+}
+
+// ipProtoPayload returns a minimal well-formed header for protocols that need
+// one to elicit a reply, and an empty payload for everything else.
+func ipProtoPayload(proto int, src, dst net.IP) []byte {
+    switch proto {
+    case 1: // ICMP echo request
+        b := make([]byte, 8)
+        b[0] = 8
+        binary.BigEndian.PutUint16(b[4:6], uint16(rand.Intn(0xffff)))
+        binary.BigEndian.PutUint16(b[6:8], 1)
+        binary.BigEndian.PutUint16(b[2:4], inetChecksum(b))
+        return b
+    case 6: // TCP ACK to port 80, answered by RST
+        b := make([]byte, 20)
+        binary.BigEndian.PutUint16(b[0:2], uint16(32768+rand.Intn(28232)))
+        binary.BigEndian.PutUint16(b[2:4], 80)
+        binary.BigEndian.PutUint32(b[8:12], rand.Uint32())
+        b[12] = 5 << 4
+        b[13] = 0x10 // ACK
+        binary.BigEndian.PutUint16(b[14:16], 1024)
+        binary.BigEndian.PutUint16(b[16:18], pseudoChecksum(src, dst, 6, b))
+        return b
+    case 17: // UDP to an unlikely port, answered by port unreachable
+        b := make([]byte, 8)
+        binary.BigEndian.PutUint16(b[0:2], uint16(32768+rand.Intn(28232)))
+        binary.BigEndian.PutUint16(b[2:4], 40125)
+        binary.BigEndian.PutUint16(b[4:6], 8)
+        return b
+    case 132: // SCTP INIT, answered by INIT-ACK or ABORT
+        return sctpInitPacket(uint16(32768+rand.Intn(28232)), 80, rand.Uint32()|1)
+    }
+    return nil
+}
@@ -0,0 +1,157 @@
+// File: internal/scanner/quic.go
+package scanner
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "net"
+    "strconv"
+    "strings"
+    "syscall"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/egress"
+    "goscant/internal/models"
+)
+
+// QUICColumns are the Result.Meta keys set by the QUIC scanner.
+var QUICColumns = []string{"quic_versions"}
+
+const (
+    quicMinDatagram = 1200       // servers drop smaller client Initials
+    quicProbeVer    = 0x1a2a3a4a // reserved version that forces negotiation
+    quicCIDLen      = 8
+)
+
+// ----- QUIC scanner -----
+
+// quicScanner sends a QUIC Initial carrying a reserved version. Servers
+// answer with Version Negotiation listing what they support, which needs no
+// handshake crypto: VN -> Open, ICMP port unreachable -> Closed,
+// silence -> Filtered.
+type quicScanner struct {
+    timeouts *timeouts
+    egress   *egress.Set
+}
+
+func NewQUICScanner(cfg *config.Config) Scanner {
+    return &quicScanner{timeouts: newTimeouts(cfg), egress: cfg.EgressProfiles}
+}
+
+func (s *quicScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    res := s.scan(ctx, t)
+    res.Engine = "quic"
+    return res
+}
+
+func (s *quicScanner) scan(ctx context.Context, t models.ScanTarget) Result {
+    res := Result{IP: t.IP, Port: t.Port, Proto: "quic"}
+    conn, err := s.egress.For(t.IP).Dialer("udp", t.IP, 0).Dial("udp", net.JoinHostPort(t.IP, strconv.Itoa(t.Port)))
+    if err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+    defer conn.Close()
+    defer closeOnCancel(ctx, conn)()
+
+    dcid, scid := make([]byte, quicCIDLen), make([]byte, quicCIDLen)
+    rand.Read(dcid)
+    rand.Read(scid)
+    timeout := s.timeouts.For(t.IP)
+    start := time.Now()
+    if _, err := conn.Write(quicInitial(dcid, scid)); err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+    conn.SetReadDeadline(start.Add(timeout))
+    buf := make([]byte, 1500)
+    for {
+        n, err := conn.Read(buf)
+        switch {
+        case err != nil && ctx.Err() != nil:
+            res.Status, res.Err = Error, ctx.Err()
+            return res
+        case errors.Is(err, syscall.ECONNREFUSED):
+            res.Status, res.LatencyMS = Closed, time.Since(start).Milliseconds()
+            return res
+        case err != nil:
+            res.Status, res.LatencyMS = Filtered, timeout.Milliseconds()
+            return res
+        }
+        versions, ok := parseVersionNegotiation(buf[:n], scid, dcid)
+        if !ok {
+            continue
+        }
+        rtt := time.Since(start)
+        s.timeouts.Observe(t.IP, rtt)
+        res.Status, res.LatencyMS, res.Service = Open, rtt.Milliseconds(), "quic"
+        res.Meta = map[string]string{"quic_versions": strings.Join(versions, ";")}
+        return res
+    }
+}
+
+// quicInitial builds a padded long-header Initial packet. The payload is
+// never decrypted since the version is unknown to every server.
+func quicInitial(dcid, scid []byte) []byte {
+    b := make([]byte, 0, quicMinDatagram)
+    b = append(b, 0xc3) // long header, fixed bit, Initial, 4-byte packet number
+    b = binary.BigEndian.AppendUint32(b, quicProbeVer)
+    b = append(b, byte(len(dcid)))
+    b = append(b, dcid...)
+    b = append(b, byte(len(scid)))
+    b = append(b, scid...)
+    b = append(b, 0) // token length
+    rest := quicMinDatagram - len(b) - 2
+    b = binary.BigEndian.AppendUint16(b, 0x4000|uint16(rest)) // 2-byte varint length
+    b = append(b, make([]byte, rest)...)
+    return b
+}
+
+// parseVersionNegotiation validates a Version Negotiation reply (which must
+// echo our connection IDs swapped) and names the versions it lists.
+func parseVersionNegotiation(b, ourSCID, ourDCID []byte) ([]string, bool) {
+    if len(b) < 7 || b[0]&0x80 == 0 || binary.BigEndian.Uint32(b[1:5]) != 0 {
+        return nil, false
+    }
+    p := b[5:]
+    dcid, p, ok := quicCID(p)
+    if !ok || string(dcid) != string(ourSCID) {
+        return nil, false
+    }
+    scid, p, ok := quicCID(p)
+    if !ok || string(scid) != string(ourDCID) || len(p) == 0 || len(p)%4 != 0 {
+        return nil, false
+    }
+    versions := []string{}
+    for ; len(p) >= 4; p = p[4:] {
+        v := binary.BigEndian.Uint32(p)
+        if v&0x0f0f0f0f == 0x0a0a0a0a {
+            continue // greased entry
+        }
+        versions = append(versions, quicVersionName(v))
+    }
+    return versions, true
+}
+
+func quicCID(p []byte) (cid, rest []byte, ok bool) {
+    if len(p) < 1 || len(p) < 1+int(p[0]) {
+        return nil, nil, false
+    }
+    return p[1 : 1+p[0]], p[1+p[0]:], true
+}
+
+func quicVersionName(v uint32) string {
+    switch {
+    case v == 0x00000001:
+        return "v1"
+    case v == 0x6b3343cf:
+        return "v2"
+    case v>>8 == 0xff0000:
+        return fmt.Sprintf("draft-%d", v&0xff)
+    }
+    return fmt.Sprintf("0x%08x", v)
+}
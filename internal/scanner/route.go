@@ -0,0 +1,71 @@
+// File: internal/scanner/route.go
+package scanner
+
+import (
+    "net"
+    "sync"
+)
+
+// routeCache memoises source addresses per destination /24 (or /64).
+var routeCache sync.Map
+
+func routeKey(dst net.IP) string {
+    if v4 := dst.To4(); v4 != nil {
+        return v4.Mask(net.CIDRMask(24, 32)).String()
+    }
+    return dst.Mask(net.CIDRMask(64, 128)).String()
+}
+
+// sourceFor returns the cached source address for dst's network.
+func sourceFor(dst net.IP) (net.IP, error) {
+    key := routeKey(dst)
+    if v, ok := routeCache.Load(key); ok {
+        return v.(net.IP), nil
+    }
+    src, err := localIPFor(dst)
+    if err != nil {
+        return nil, err
+    }
+    routeCache.Store(key, src)
+    return src, nil
+}
+
+// isLocal reports whether dst is an address of this host. The kernel
+// delivers such packets over loopback, so a capture opened on the
+// interface owning the address never sees the replies.
+func isLocal(dst net.IP) bool {
+    if dst.IsLoopback() {
+        return true
+    }
+    src, err := sourceFor(dst)
+    return err == nil && src.Equal(dst)
+}
+
+// PrewarmRoutes computes source addressing for every destination network
+// in ips ahead of the scan and returns how many networks were cached.
+func PrewarmRoutes(ips []string) int {
+    n := 0
+    for _, ip := range ips {
+        dst := net.ParseIP(ip)
+        if dst == nil {
+            continue
+        }
+        if _, ok := routeCache.Load(routeKey(dst)); ok {
+            continue
+        }
+        if _, err := sourceFor(dst); err == nil {
+            n++
+        }
+    }
+    return n
+}
+
+// localIPFor returns the source address the kernel would route dst from.
+func localIPFor(dst net.IP) (net.IP, error) {
+    c, err := net.Dial("udp", net.JoinHostPort(dst.String(), "9"))
+    if err != nil {
+        return nil, err
+    }
+    defer c.Close()
+    return c.LocalAddr().(*net.UDPAddr).IP, nil
+}
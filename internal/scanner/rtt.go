@@ -0,0 +1,62 @@
+// File: internal/scanner/rtt.go
+package scanner
+
+import (
+    "sync"
+    "time"
+
+    "goscant/internal/config"
+)
+
+// rttTimeoutFactor scales a host's smoothed RTT into its probe timeout.
+const rttTimeoutFactor = 3
+
+// timeouts hands out per-host probe timeouts. With --adaptive-timeout each
+// host's timeout follows 3x its smoothed RTT (RFC 6298 style), clamped to
+// [--min-timeout, --timeout]; hosts with no reply yet get --timeout.
+type timeouts struct {
+    max, min time.Duration
+    adaptive bool
+
+    mu   sync.Mutex
+    srtt map[string]time.Duration
+}
+
+func newTimeouts(cfg *config.Config) *timeouts {
+    return &timeouts{max: cfg.Timeout, min: cfg.MinTimeout, adaptive: cfg.AdaptiveTimeout, srtt: map[string]time.Duration{}}
+}
+
+// For returns the timeout to use for the next probe of ip.
+func (t *timeouts) For(ip string) time.Duration {
+    if !t.adaptive {
+        return t.max
+    }
+    t.mu.Lock()
+    rtt, ok := t.srtt[ip]
+    t.mu.Unlock()
+    if !ok {
+        return t.max
+    }
+    d := rtt * rttTimeoutFactor
+    if d < t.min {
+        d = t.min
+    }
+    if d > t.max {
+        d = t.max
+    }
+    return d
+}
+
+// Observe feeds the round-trip time of a reply from ip into its estimate.
+func (t *timeouts) Observe(ip string, rtt time.Duration) {
+    if !t.adaptive {
+        return
+    }
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if prev, ok := t.srtt[ip]; ok {
+        t.srtt[ip] = prev - prev/8 + rtt/8
+    } else {
+        t.srtt[ip] = rtt
+    }
+}
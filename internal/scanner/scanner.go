@@ -3,10 +3,18 @@ package scanner
 
 import (
     "context"
+    "errors"
+    "io"
     "net"
+    "strconv"
+    "strings"
+    "syscall"
     "time"
 
     "goscant/internal/config"
+    "goscant/internal/egress"
+    "goscant/internal/models"
+    "goscant/internal/rawnet"
 )
 
 // Status indicates probe outcome.
@@ -17,77 +25,176 @@ const (
     Closed
     Filtered
     Error
+    HostDown // host dropped by discovery; no port was probed
 )
 
+func (s Status) String() string {
+    switch s {
+    case Open:
+        return "OPEN"
+    case Closed:
+        return "CLOSED"
+    case Filtered:
+        return "FILTERED"
+    case Error:
+        return "ERROR"
+    case HostDown:
+        return "HOST_DOWN"
+    }
+    return "UNKNOWN"
+}
+
+// ParseStatus is the inverse of Status.String (case-insensitive).
+func ParseStatus(s string) (Status, bool) {
+    for _, st := range []Status{Open, Closed, Filtered, Error, HostDown} {
+        if strings.EqualFold(strings.TrimSpace(s), st.String()) {
+            return st, true
+        }
+    }
+    return 0, false
+}
+
 // Result captures probe data.
 type Result struct {
-    IP        string
-    Port      int
-    Status    Status
-    LatencyMS int64
-    Err       error
+    IP         string
+    Port       int
+    Proto      string
+    Status     Status
+    LatencyMS  int64
+    Attempts   int        // probes sent before this status was recorded
+    Confidence Confidence // how certain Status is; see Rate
+    Engine     string     // scanner that produced the result, e.g. "syn" or "connect"
+    Err        error
+    Banner     string            // application data read from the service, if any
+    Service    string            // identified service name, if any
+    Meta       map[string]string // annotations added after the probe
+    Time       time.Time         // when the probe finished; keeps the monotonic reading
+}
+
+// Family returns "ipv4" or "ipv6" for the result's address.
+func (r Result) Family() string {
+    addr, _, _ := strings.Cut(r.IP, "%") // zoned link-local
+    if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+        return "ipv6"
+    }
+    return "ipv4"
 }
 
 // Scanner defines one probe operation.
 type Scanner interface {
-    Scan(ctx context.Context, ip string, port int) Result
+    // Scan probes t. Cancelling ctx aborts an in-flight probe promptly; the
+    // result then has Status Error and Err set to ctx.Err().
+    Scan(ctx context.Context, t models.ScanTarget) Result
+}
+
+// BatchScanner is implemented by engines that can probe several ports of
+// one host in a single send pass.
+type BatchScanner interface {
+    Scanner
+    ScanBatch(ctx context.Context, ip string, ports []int) []Result
+}
+
+// SelfTester is implemented by engines that can check their raw packet
+// path before a scan relies on it.
+type SelfTester interface {
+    SelfTest(ctx context.Context) error
 }
 
 // NewFactory returns concrete scanner.
 func NewFactory(cfg *config.Config, rawCapable bool) Scanner {
-    if rawCapable && !cfg.DryRun {
-        return NewRawScanner(cfg)
+    switch cfg.ScanType {
+    case "sctp":
+        return NewSCTPScanner(cfg)
+    case "ipproto":
+        return NewIPProtoScanner(cfg)
+    case "quic":
+        return NewQUICScanner(cfg)
+    case "tls":
+        return NewTLSScanner(cfg)
+    }
+    // banners need a full connection; egress profiles bind sockets
+    if rawCapable && !cfg.DryRun && !cfg.Banner && cfg.EgressProfiles == nil {
+        return NewSynScanner(cfg)
     }
     return NewSocketScanner(cfg)
 }
 
+// closeOnCancel closes c as soon as ctx is cancelled, unblocking any read
+// in progress. The returned stop function must be called once c is no
+// longer in use.
+func closeOnCancel(ctx context.Context, c io.Closer) (stop func()) {
+    done := make(chan struct{})
+    go func() {
+        select {
+        case <-ctx.Done():
+            c.Close()
+        case <-done:
+        }
+    }()
+    return func() { close(done) }
+}
+
 // CheckRawSocketCapability checks runtime privilege.
 func CheckRawSocketCapability() bool {
-    // Simple attempt to open raw socket
-    conn, err := net.ListenPacket("ip4:tcp", "0.0.0.0")
-    if err != nil { return false }
-    conn.Close()
-    return true
+    return rawnet.Capable()
 }
 
 // ------ socket scanner --------
 
 type socketScanner struct {
-    timeout time.Duration
-    delay   time.Duration
+    timeouts *timeouts
+    delay    time.Duration
+    egress   *egress.Set
+
+    bannerMax     int // bytes of greeting to keep; 0 disables banner grabbing
+    bannerTimeout time.Duration
 }
 
 func NewSocketScanner(cfg *config.Config) Scanner {
-    return &socketScanner{timeout: cfg.Timeout, delay: cfg.Delay}
+    s := &socketScanner{timeouts: newTimeouts(cfg), delay: cfg.Delay, egress: cfg.EgressProfiles}
+    if cfg.Banner {
+        s.bannerMax, s.bannerTimeout = cfg.BannerBytes, cfg.BannerTimeout
+    }
+    return s
 }
 
-func (s *socketScanner) Scan(ctx context.Context, ip string, port int) Result {
+func (s *socketScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    res := s.scan(ctx, t)
+    res.Engine = "connect"
+    return res
+}
+
+func (s *socketScanner) scan(ctx context.Context, t models.ScanTarget) Result {
+    ip, port := t.IP, t.Port
     addr := net.JoinHostPort(ip, strconv.Itoa(port))
+    timeout := s.timeouts.For(ip)
     start := time.Now()
-    d := net.Dialer{Timeout: s.timeout}
+    d := s.egress.For(ip).Dialer("tcp", ip, timeout)
     conn, err := d.DialContext(ctx, "tcp", addr)
     if err != nil {
+        if ctx.Err() != nil {
+            return Result{IP: ip, Port: port, Proto: "tcp", Status: Error, Err: ctx.Err()}
+        }
         if errors.Is(err, context.DeadlineExceeded) {
-            return Result{IP: ip, Port: port, Status: Filtered, LatencyMS: s.timeout.Milliseconds(), Err: err}
+            return Result{IP: ip, Port: port, Proto: "tcp", Status: Filtered, LatencyMS: timeout.Milliseconds(), Err: err}
+        }
+        if !errors.Is(err, syscall.ECONNREFUSED) {
+            // e.g. EHOSTUNREACH: nothing about the port is known
+            return Result{IP: ip, Port: port, Proto: "tcp", Status: Error, LatencyMS: time.Since(start).Milliseconds(), Err: err}
         }
-        return Result{IP: ip, Port: port, Status: Closed, LatencyMS: time.Since(start).Milliseconds(), Err: err}
+        s.timeouts.Observe(ip, time.Since(start))
+        return Result{IP: ip, Port: port, Proto: "tcp", Status: Closed, LatencyMS: time.Since(start).Milliseconds(), Err: err}
+    }
+    rtt := time.Since(start)
+    s.timeouts.Observe(ip, rtt)
+    res := Result{IP: ip, Port: port, Proto: "tcp", Status: Open, LatencyMS: rtt.Milliseconds()}
+    if s.bannerMax > 0 {
+        res.Banner, res.Err = readBanner(conn, s.bannerTimeout, s.bannerMax)
     }
     conn.Close()
-    time.Sleep(s.delay)
-    return Result{IP: ip, Port: port, Status: Open, LatencyMS: time.Since(start).Milliseconds()}
-}
-
-// ----- raw scanner skeleton -----
-
-func NewRawScanner(cfg *config.Config) Scanner {
-    return &rawScanner{cfg: cfg}
-}
-
-type rawScanner struct {
-    cfg *config.Config
+    select {
+    case <-time.After(s.delay):
+    case <-ctx.Done():
+    }
+    return res
 }
-
-func (r *rawScanner) Scan(ctx context.Context, ip string, port int) Result {
-    // TODO: implement full SYN, ACK, FIN handshake with gopacket.
-    return Result{IP: ip, Port: port, Status: Error, Err: errors.New("raw scanner not implemented")}
-}
\ No newline at end of file
@@ -0,0 +1,132 @@
+// File: internal/scanner/sctp.go
+package scanner
+
+import (
+    "context"
+    "encoding/binary"
+    "errors"
+    "hash/crc32"
+    "math/rand"
+    "net"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/models"
+    "goscant/internal/rawnet"
+)
+
+const (
+    sctpChunkInit    = 1
+    sctpChunkInitAck = 2
+    sctpChunkAbort   = 6
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ----- SCTP INIT scanner -----
+
+// sctpScanner sends an SCTP INIT per probe and classifies the reply:
+// INIT-ACK -> Open, ABORT -> Closed, silence -> Filtered.
+type sctpScanner struct {
+    timeouts *timeouts
+}
+
+func NewSCTPScanner(cfg *config.Config) Scanner {
+    return &sctpScanner{timeouts: newTimeouts(cfg)}
+}
+
+func (s *sctpScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    res := s.scan(ctx, t)
+    res.Engine = "sctp"
+    return res
+}
+
+func (s *sctpScanner) scan(ctx context.Context, t models.ScanTarget) Result {
+    ip, port := t.IP, t.Port
+    res := Result{IP: ip, Port: port, Proto: "sctp"}
+    dst := net.ParseIP(ip)
+    if dst == nil {
+        res.Status, res.Err = Error, errors.New("invalid IP address")
+        return res
+    }
+    network := "ip4:132"
+    if dst.To4() == nil {
+        network = "ip6:132"
+    }
+    conn, err := rawnet.Listen(network)
+    if err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+    defer conn.Close()
+    defer closeOnCancel(ctx, conn)()
+
+    srcPort := uint16(32768 + rand.Intn(28232))
+    tag := rand.Uint32() | 1 // initiate tag must be non-zero
+    start := time.Now()
+    if _, err := conn.WriteTo(sctpInitPacket(srcPort, uint16(port), tag), &net.IPAddr{IP: dst}); err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+
+    timeout := s.timeouts.For(ip)
+    deadline := start.Add(timeout)
+    if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+        deadline = d
+    }
+    conn.SetReadDeadline(deadline)
+    buf := make([]byte, 1500)
+    for {
+        n, from, err := conn.ReadFrom(buf)
+        if err != nil && ctx.Err() != nil {
+            res.Status, res.Err = Error, ctx.Err()
+            return res
+        }
+        if err != nil {
+            res.Status, res.LatencyMS = Filtered, timeout.Milliseconds()
+            return res
+        }
+        if a, ok := from.(*net.IPAddr); !ok || !a.IP.Equal(dst) {
+            continue
+        }
+        pkt := buf[:n]
+        if len(pkt) < 16 ||
+            binary.BigEndian.Uint16(pkt[0:2]) != uint16(port) ||
+            binary.BigEndian.Uint16(pkt[2:4]) != srcPort ||
+            binary.BigEndian.Uint32(pkt[4:8]) != tag {
+            continue
+        }
+        switch pkt[12] {
+        case sctpChunkInitAck:
+            res.Status = Open
+        case sctpChunkAbort:
+            res.Status = Closed
+        default:
+            continue
+        }
+        rtt := time.Since(start)
+        s.timeouts.Observe(ip, rtt)
+        res.LatencyMS = rtt.Milliseconds()
+        return res
+    }
+}
+
+// sctpInitPacket builds a common header plus a minimal INIT chunk.
+func sctpInitPacket(srcPort, dstPort uint16, tag uint32) []byte {
+    b := make([]byte, 12+20)
+    binary.BigEndian.PutUint16(b[0:2], srcPort)
+    binary.BigEndian.PutUint16(b[2:4], dstPort)
+    // verification tag (b[4:8]) stays zero for INIT
+
+    c := b[12:]
+    c[0] = sctpChunkInit
+    binary.BigEndian.PutUint16(c[2:4], 20)
+    binary.BigEndian.PutUint32(c[4:8], tag)    // initiate tag
+    binary.BigEndian.PutUint32(c[8:12], 65535) // advertised receiver window
+    binary.BigEndian.PutUint16(c[12:14], 10)   // outbound streams
+    binary.BigEndian.PutUint16(c[14:16], 2048) // max inbound streams
+    binary.BigEndian.PutUint32(c[16:20], tag)  // initial TSN
+
+    binary.LittleEndian.PutUint32(b[8:12], crc32.Checksum(b, castagnoli))
+    return b
+}
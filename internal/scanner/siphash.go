@@ -0,0 +1,54 @@
+// File: internal/scanner/siphash.go
+package scanner
+
+import (
+    "encoding/binary"
+    "math/bits"
+)
+
+// siphash24 is SipHash-2-4 keyed with (k0, k1), used for SYN cookies.
+func siphash24(k0, k1 uint64, m []byte) uint64 {
+    v0 := k0 ^ 0x736f6d6570736575
+    v1 := k1 ^ 0x646f72616e646f6d
+    v2 := k0 ^ 0x6c7967656e657261
+    v3 := k1 ^ 0x7465646279746573
+    round := func() {
+        v0 += v1
+        v1 = bits.RotateLeft64(v1, 13)
+        v1 ^= v0
+        v0 = bits.RotateLeft64(v0, 32)
+        v2 += v3
+        v3 = bits.RotateLeft64(v3, 16)
+        v3 ^= v2
+        v0 += v3
+        v3 = bits.RotateLeft64(v3, 21)
+        v3 ^= v0
+        v2 += v1
+        v1 = bits.RotateLeft64(v1, 17)
+        v1 ^= v2
+        v2 = bits.RotateLeft64(v2, 32)
+    }
+    n := len(m)
+    for len(m) >= 8 {
+        mi := binary.LittleEndian.Uint64(m)
+        v3 ^= mi
+        round()
+        round()
+        v0 ^= mi
+        m = m[8:]
+    }
+    var last [8]byte
+    copy(last[:], m)
+    last[7] = byte(n)
+    mi := binary.LittleEndian.Uint64(last[:])
+    v3 ^= mi
+    round()
+    round()
+    v0 ^= mi
+    v2 ^= 0xff
+    round()
+    round()
+    round()
+    round()
+    return v0 ^ v1 ^ v2 ^ v3
+}
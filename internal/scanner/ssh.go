@@ -0,0 +1,175 @@
+// File: internal/scanner/ssh.go
+package scanner
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "golang.org/x/crypto/ssh"
+    "golang.org/x/crypto/ssh/agent"
+    "golang.org/x/crypto/ssh/knownhosts"
+
+    "goscant/internal/config"
+    "goscant/internal/models"
+)
+
+// sshScanner connect-scans through direct-tcpip channels of one SSH
+// connection to a jump host, reaching segments only the bastion can see.
+// The bastion makes the connection, so OPEN and CLOSED describe what it
+// sees; its own firewall rules apply.
+type sshScanner struct {
+    client  *ssh.Client
+    timeout time.Duration
+    delay   time.Duration
+
+    bannerMax     int
+    bannerTimeout time.Duration
+}
+
+// NewSSHScanner connects to the jump host named by cfg.Via, of the form
+// ssh://user@host[:port]. Keys come from the SSH agent and the default
+// files in ~/.ssh; the host key must already be in ~/.ssh/known_hosts.
+func NewSSHScanner(cfg *config.Config) (Scanner, error) {
+    u, err := url.Parse(cfg.Via)
+    if err != nil || u.Scheme != "ssh" || u.Hostname() == "" {
+        return nil, fmt.Errorf("--via %q: want ssh://user@host[:port]", cfg.Via)
+    }
+    user := u.User.Username()
+    if user == "" {
+        user = os.Getenv("USER")
+    }
+    port := u.Port()
+    if port == "" {
+        port = "22"
+    }
+    home, _ := os.UserHomeDir()
+    hostKeys, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+    if err != nil {
+        return nil, fmt.Errorf("--via: %w", err)
+    }
+    auth := sshAuth(home)
+    if len(auth) == 0 {
+        return nil, errors.New("--via: no SSH agent and no usable key in ~/.ssh")
+    }
+    client, err := ssh.Dial("tcp", net.JoinHostPort(u.Hostname(), port), &ssh.ClientConfig{
+        User: user, Auth: auth, HostKeyCallback: hostKeys, Timeout: 10 * time.Second,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("--via %s: %w", u.Host, err)
+    }
+    s := &sshScanner{client: client, timeout: cfg.Timeout, delay: cfg.Delay}
+    if cfg.Banner {
+        s.bannerMax, s.bannerTimeout = cfg.BannerBytes, cfg.BannerTimeout
+    }
+    return s, nil
+}
+
+// sshAuth offers the agent's keys, then unencrypted default key files.
+func sshAuth(home string) []ssh.AuthMethod {
+    var auth []ssh.AuthMethod
+    if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+        if conn, err := net.Dial("unix", sock); err == nil {
+            auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+        }
+    }
+    var signers []ssh.Signer
+    for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+        b, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+        if err != nil {
+            continue
+        }
+        if signer, err := ssh.ParsePrivateKey(b); err == nil {
+            signers = append(signers, signer) // passphrase-protected keys need the agent
+        }
+    }
+    if len(signers) > 0 {
+        auth = append(auth, ssh.PublicKeys(signers...))
+    }
+    return auth
+}
+
+func (s *sshScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    res := s.scan(ctx, t)
+    res.Engine = "ssh"
+    return res
+}
+
+func (s *sshScanner) scan(ctx context.Context, t models.ScanTarget) Result {
+    res := Result{IP: t.IP, Port: t.Port, Proto: "tcp"}
+    start := time.Now()
+    conn, err := s.dial(ctx, net.JoinHostPort(t.IP, strconv.Itoa(t.Port)))
+    res.LatencyMS = time.Since(start).Milliseconds()
+    var oce *ssh.OpenChannelError
+    switch {
+    case ctx.Err() != nil:
+        res.Status, res.Err = Error, ctx.Err()
+        return res
+    case err == nil:
+        res.Status = Open
+    case errors.As(err, &oce) && oce.Reason == ssh.ConnectionFailed && strings.Contains(strings.ToLower(oce.Message), "refused"):
+        res.Status, res.Err = Closed, err
+        return res
+    case errors.Is(err, context.DeadlineExceeded), errors.As(err, &oce) && strings.Contains(strings.ToLower(oce.Message), "timed out"):
+        res.Status, res.Err = Filtered, err
+        return res
+    default:
+        res.Status, res.Err = Error, err
+        return res
+    }
+    if s.bannerMax > 0 {
+        // Channels ignore read deadlines, so a timer ends the read instead
+        timer := time.AfterFunc(s.bannerTimeout, func() { conn.Close() })
+        res.Banner, res.Err = readBanner(conn, s.bannerTimeout, s.bannerMax)
+        if !timer.Stop() {
+            res.Err = nil // the timer fired: silent service
+        }
+    }
+    conn.Close()
+    select {
+    case <-time.After(s.delay):
+    case <-ctx.Done():
+    }
+    return res
+}
+
+// dial opens a direct-tcpip channel to addr, giving up after the probe
+// timeout; a channel that opens late is closed.
+func (s *sshScanner) dial(ctx context.Context, addr string) (net.Conn, error) {
+    type dialed struct {
+        conn net.Conn
+        err  error
+    }
+    ch := make(chan dialed, 1)
+    go func() {
+        conn, err := s.client.Dial("tcp", addr)
+        ch <- dialed{conn, err}
+    }()
+    t := time.NewTimer(s.timeout)
+    defer t.Stop()
+    var err error
+    select {
+    case d := <-ch:
+        return d.conn, d.err
+    case <-t.C:
+        err = context.DeadlineExceeded
+    case <-ctx.Done():
+        err = ctx.Err()
+    }
+    go func() {
+        if d := <-ch; d.conn != nil {
+            d.conn.Close()
+        }
+    }()
+    return nil, err
+}
+
+// Close ends the SSH connection.
+func (s *sshScanner) Close() error { return s.client.Close() }
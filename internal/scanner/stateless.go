@@ -0,0 +1,179 @@
+// File: internal/scanner/stateless.go
+package scanner
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/binary"
+    "errors"
+    "math/big"
+    "net"
+    "strconv"
+    "sync"
+
+    "github.com/google/gopacket"
+    "github.com/google/gopacket/layers"
+
+    "goscant/internal/config"
+    "goscant/internal/models"
+    "goscant/internal/rawnet"
+)
+
+// ----- stateless (masscan-style) SYN sweeper -----
+
+// Stateless fires SYNs whose sequence number is a SipHash cookie of the
+// destination and validates replies against it, so no per-probe state is
+// kept. Only responders are reported (OPEN or CLOSED); silence is not.
+type Stateless struct {
+    k0, k1  uint64
+    srcPort uint16
+    emit    func(Result)
+    sendRST bool
+    local   Scanner // connect scan for this host's own addresses
+
+    send net.PacketConn
+
+    mu      sync.Mutex
+    handles map[string]rawnet.Capture
+    seen    map[string]bool // responders already reported
+    loops   sync.WaitGroup
+}
+
+// NewStateless opens the raw send socket; emit receives every validated reply
+// and is called from the receive goroutines.
+func NewStateless(cfg *config.Config, emit func(Result)) (*Stateless, error) {
+    var key [16]byte
+    if _, err := rand.Read(key[:]); err != nil {
+        return nil, err
+    }
+    p, err := rand.Int(rand.Reader, big.NewInt(28232))
+    if err != nil {
+        return nil, err
+    }
+    conn, err := rawnet.Listen("ip4:tcp")
+    if err != nil {
+        return nil, err
+    }
+    return &Stateless{
+        k0:      binary.LittleEndian.Uint64(key[:8]),
+        k1:      binary.LittleEndian.Uint64(key[8:]),
+        srcPort: uint16(32768 + p.Int64()),
+        emit:    emit,
+        sendRST: !cfg.NoRST,
+        local:   NewSocketScanner(cfg),
+        send:    conn,
+        handles: map[string]rawnet.Capture{},
+        seen:    map[string]bool{},
+    }, nil
+}
+
+// cookie derives the SYN sequence number for dst:port.
+func (s *Stateless) cookie(dst net.IP, port uint16) uint32 {
+    var m [6]byte
+    copy(m[:4], dst.To4())
+    binary.BigEndian.PutUint16(m[4:], port)
+    return uint32(siphash24(s.k0, s.k1, m[:]))
+}
+
+// Send transmits one SYN to ip:port. This host's own addresses, whose
+// replies never reach the capture, are connect-scanned in the background
+// and reported like any other responder.
+func (s *Stateless) Send(ip string, port int) error {
+    dst := net.ParseIP(ip).To4()
+    if dst == nil {
+        return errors.New("stateless mode supports IPv4 targets only")
+    }
+    if isLocal(dst) {
+        s.loops.Add(1)
+        go func() {
+            defer s.loops.Done()
+            if r := s.local.Scan(context.Background(), models.ScanTarget{IP: ip, Port: port}); r.Status == Open || r.Status == Closed {
+                s.emit(r)
+            }
+        }()
+        return nil
+    }
+    src, err := sourceFor(dst)
+    if err != nil {
+        return err
+    }
+    if err := s.listen(src); err != nil {
+        return err
+    }
+    seg := synSegment(src, dst, s.srcPort, uint16(port), s.cookie(dst, uint16(port)))
+    _, err = s.send.WriteTo(seg, &net.IPAddr{IP: dst})
+    return err
+}
+
+func (s *Stateless) listen(src net.IP) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if _, ok := s.handles[src.String()]; ok {
+        return nil
+    }
+    h, err := rawnet.OpenCapture(src, synReplyFilter(src))
+    if err != nil {
+        return err
+    }
+    s.handles[src.String()] = h
+    s.loops.Add(1)
+    go s.receive(h)
+    return nil
+}
+
+func (s *Stateless) receive(h rawnet.Capture) {
+    defer s.loops.Done()
+    for {
+        data, _, err := h.ReadPacketData()
+        if err == rawnet.ErrTimeout {
+            continue
+        }
+        if err != nil {
+            return
+        }
+        pkt := gopacket.NewPacket(data, h.LinkType(), gopacket.NoCopy)
+        ip4, _ := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+        tcp, _ := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP)
+        if ip4 == nil || tcp == nil || uint16(tcp.DstPort) != s.srcPort {
+            continue
+        }
+        if !tcp.ACK || tcp.Ack-1 != s.cookie(ip4.SrcIP, uint16(tcp.SrcPort)) {
+            continue
+        }
+        r := Result{IP: ip4.SrcIP.String(), Port: int(tcp.SrcPort), Proto: "tcp", Engine: "stateless"}
+        switch {
+        case tcp.SYN && tcp.ACK:
+            r.Status = Open
+            if s.sendRST {
+                resetAfterSynAck(s.send, ip4, tcp)
+            }
+        case tcp.RST:
+            r.Status = Closed
+        default:
+            continue
+        }
+        key := net.JoinHostPort(r.IP, strconv.Itoa(r.Port))
+        s.mu.Lock()
+        dup := s.seen[key]
+        s.seen[key] = true
+        s.mu.Unlock()
+        if dup {
+            continue
+        }
+        s.emit(r) // no send timestamps are kept, so LatencyMS stays zero
+    }
+}
+
+// Close stops the receive loops, waiting until no further emit can happen,
+// and releases the raw socket.
+func (s *Stateless) Close() error {
+    s.mu.Lock()
+    handles := s.handles
+    s.handles = map[string]rawnet.Capture{}
+    s.mu.Unlock()
+    for _, h := range handles {
+        h.Close()
+    }
+    s.loops.Wait()
+    return s.send.Close()
+}
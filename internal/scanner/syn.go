@@ -0,0 +1,327 @@
+// File: internal/scanner/syn.go
+package scanner
+
+import (
+    "context"
+    "encoding/binary"
+    "fmt"
+    "math/rand"
+    "net"
+    "sync"
+    "time"
+
+    "github.com/google/gopacket"
+    "github.com/google/gopacket/layers"
+
+    "goscant/internal/config"
+    "goscant/internal/models"
+    "goscant/internal/rawnet"
+)
+
+// ----- SYN scanner -----
+
+// probeKey identifies a pending probe by the reply's destination port (our
+// source port) and the sequence number the reply acknowledges.
+type probeKey struct {
+    port uint16
+    seq  uint32
+}
+
+type pendingProbe struct {
+    dst     net.IP
+    dstPort uint16
+    seq     uint32
+    reply   chan synReply
+}
+
+type synReply struct {
+    status Status
+    at     time.Time
+}
+
+// matches reports whether a reply really answers probe p: it must come from
+// the probed address and port and acknowledge exactly our SYN (Ack == Seq+1).
+func (p pendingProbe) matches(ip4 *layers.IPv4, tcp *layers.TCP) bool {
+    return tcp.ACK && tcp.Ack == p.seq+1 &&
+        uint16(tcp.SrcPort) == p.dstPort && p.dst.Equal(ip4.SrcIP)
+}
+
+// synScanner sends bare SYNs over one raw socket and classifies replies
+// gathered by a shared capture receive loop per source interface:
+// SYN-ACK -> Open, RST -> Closed, silence -> Filtered.
+type synScanner struct {
+    timeouts *timeouts
+    fallback Scanner // connect scan for targets the raw path cannot handle
+    sendRST  bool    // reset half-open connections after a SYN-ACK
+
+    sendOnce sync.Once
+    send     net.PacketConn
+    sendErr  error
+
+    mu      sync.Mutex
+    pending map[probeKey]pendingProbe
+    handles map[string]rawnet.Capture // keyed by source IP
+    strays  uint64                  // replies that matched no probe
+}
+
+func NewSynScanner(cfg *config.Config) Scanner {
+    return &synScanner{
+        timeouts: newTimeouts(cfg),
+        fallback: NewSocketScanner(cfg),
+        sendRST:  !cfg.NoRST,
+        pending:  map[probeKey]pendingProbe{},
+        handles:  map[string]rawnet.Capture{},
+    }
+}
+
+func (s *synScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    return s.ScanBatch(ctx, t.IP, []int{t.Port})[0]
+}
+
+// ScanBatch sends SYNs for all ports of one host back-to-back and collects
+// the replies from the shared receive loop under a single deadline. IPv6
+// and this host's own addresses are connect-scanned instead.
+func (s *synScanner) ScanBatch(ctx context.Context, ip string, ports []int) []Result {
+    dst := net.ParseIP(ip).To4()
+    if dst == nil || isLocal(dst) {
+        results := make([]Result, len(ports))
+        for i, port := range ports {
+            results[i] = s.fallback.Scan(ctx, models.ScanTarget{IP: ip, Port: port})
+        }
+        return results
+    }
+    results := s.scanRaw(ctx, ip, dst, ports)
+    for i := range results {
+        results[i].Engine = "syn"
+    }
+    return results
+}
+
+// SelfTest SYN-scans a listener of our own over loopback. The local kernel
+// drops segments with a bad checksum and the capture must deliver its
+// SYN-ACK, so passing shows that crafted packets and capture both work.
+func (s *synScanner) SelfTest(ctx context.Context) error {
+    l, err := net.Listen("tcp4", "127.0.0.1:0")
+    if err != nil {
+        return err
+    }
+    defer l.Close()
+    port := l.Addr().(*net.TCPAddr).Port
+    res := s.scanRaw(ctx, "127.0.0.1", net.IPv4(127, 0, 0, 1).To4(), []int{port})[0]
+    if res.Status != Open {
+        if res.Err != nil {
+            return fmt.Errorf("SYN to a local listener: %w", res.Err)
+        }
+        return fmt.Errorf("SYN to a local listener came back %v", res.Status)
+    }
+    return nil
+}
+
+// scanRaw is ScanBatch over the raw socket and capture.
+func (s *synScanner) scanRaw(ctx context.Context, ip string, dst net.IP, ports []int) []Result {
+    results := make([]Result, len(ports))
+    for i, port := range ports {
+        results[i] = Result{IP: ip, Port: port, Proto: "tcp"}
+    }
+    src, err := s.prepare(dst)
+    if err != nil {
+        for i := range results {
+            results[i].Status, results[i].Err = Error, err
+        }
+        return results
+    }
+
+    keys := make([]probeKey, len(ports))
+    probes := make([]pendingProbe, len(ports))
+    s.mu.Lock()
+    for i, port := range ports {
+        keys[i] = probeKey{port: uint16(32768 + rand.Intn(28232)), seq: rand.Uint32()}
+        probes[i] = pendingProbe{dst: dst, dstPort: uint16(port), seq: keys[i].seq, reply: make(chan synReply, 1)}
+        s.pending[keys[i]] = probes[i]
+    }
+    s.mu.Unlock()
+    defer func() {
+        s.mu.Lock()
+        for _, k := range keys {
+            delete(s.pending, k)
+        }
+        s.mu.Unlock()
+    }()
+
+    start := time.Now()
+    sent := make([]bool, len(ports))
+    for i, port := range ports {
+        seg := synSegment(src, dst, keys[i].port, uint16(port), keys[i].seq)
+        if _, err := s.send.WriteTo(seg, &net.IPAddr{IP: dst}); err != nil {
+            results[i].Status, results[i].Err = Error, err
+            continue
+        }
+        sent[i] = true
+    }
+
+    timeout := s.timeouts.For(ip)
+    t := time.NewTimer(timeout)
+    defer t.Stop()
+    expired := false
+    for i, p := range probes {
+        if !sent[i] {
+            continue
+        }
+        var r synReply
+        got := false
+        if !expired {
+            select {
+            case r = <-p.reply:
+                got = true
+            case <-t.C:
+                expired = true
+            case <-ctx.Done():
+                results[i].Status, results[i].Err = Error, ctx.Err()
+                continue
+            }
+        }
+        if !got {
+            select {
+            case r = <-p.reply:
+                got = true
+            default:
+            }
+        }
+        if got {
+            rtt := r.at.Sub(start)
+            s.timeouts.Observe(ip, rtt)
+            results[i].Status, results[i].LatencyMS = r.status, rtt.Milliseconds()
+        } else {
+            results[i].Status, results[i].LatencyMS = Filtered, timeout.Milliseconds()
+        }
+    }
+    return results
+}
+
+// prepare makes sure the capture loop for dst's source address and the
+// shared send socket exist, returning the source address.
+func (s *synScanner) prepare(dst net.IP) (net.IP, error) {
+    src, err := sourceFor(dst)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.listen(src); err != nil {
+        return nil, err
+    }
+    s.sendOnce.Do(func() { s.send, s.sendErr = rawnet.Listen("ip4:tcp") })
+    return src, s.sendErr
+}
+
+// listen opens the capture handle for src and starts its receive loop once.
+func (s *synScanner) listen(src net.IP) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if _, ok := s.handles[src.String()]; ok {
+        return nil
+    }
+    h, err := rawnet.OpenCapture(src, synReplyFilter(src))
+    if err != nil {
+        return err
+    }
+    s.handles[src.String()] = h
+    go s.receive(h)
+    return nil
+}
+
+// receive demultiplexes captured replies to their pending probes until the
+// handle is closed.
+func (s *synScanner) receive(h rawnet.Capture) {
+    for {
+        data, _, err := h.ReadPacketData()
+        if err == rawnet.ErrTimeout {
+            continue
+        }
+        if err != nil {
+            return
+        }
+        pkt := gopacket.NewPacket(data, h.LinkType(), gopacket.NoCopy)
+        ip4, _ := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+        tcp, _ := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP)
+        if ip4 == nil || tcp == nil {
+            continue
+        }
+        var st Status
+        switch {
+        case tcp.SYN && tcp.ACK:
+            st = Open
+        case tcp.RST:
+            st = Closed
+        default:
+            continue
+        }
+        key := probeKey{port: uint16(tcp.DstPort), seq: tcp.Ack - 1}
+        s.mu.Lock()
+        p, ok := s.pending[key]
+        if ok && p.matches(ip4, tcp) {
+            delete(s.pending, key)
+        } else {
+            ok = false
+            s.strays++
+        }
+        s.mu.Unlock()
+        if !ok {
+            continue
+        }
+        p.reply <- synReply{status: st, at: time.Now()}
+        if st == Open && s.sendRST {
+            resetAfterSynAck(s.send, ip4, tcp)
+        }
+    }
+}
+
+// Strays returns how many captured replies failed validation.
+func (s *synScanner) Strays() uint64 {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.strays
+}
+
+// Close stops the receive loops and releases the raw socket.
+func (s *synScanner) Close() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for k, h := range s.handles {
+        h.Close()
+        delete(s.handles, k)
+    }
+    if s.send != nil {
+        return s.send.Close()
+    }
+    return nil
+}
+
+const (
+    tcpFlagSYN = 0x02
+    tcpFlagRST = 0x04
+)
+
+// synSegment builds a 20-byte TCP SYN header with a valid checksum.
+func synSegment(src, dst net.IP, srcPort, dstPort uint16, seq uint32) []byte {
+    return tcpSegment(src, dst, srcPort, dstPort, seq, tcpFlagSYN)
+}
+
+// resetAfterSynAck tears down the half-open connection a SYN-ACK created, so
+// the target's backlog entry is freed instead of waiting to time out.
+func resetAfterSynAck(conn net.PacketConn, ip4 *layers.IPv4, tcp *layers.TCP) error {
+    seg := tcpSegment(ip4.DstIP, ip4.SrcIP, uint16(tcp.DstPort), uint16(tcp.SrcPort), tcp.Ack, tcpFlagRST)
+    _, err := conn.WriteTo(seg, &net.IPAddr{IP: ip4.SrcIP})
+    return err
+}
+
+// tcpSegment builds a 20-byte TCP header with the given flags and a valid checksum.
+func tcpSegment(src, dst net.IP, srcPort, dstPort uint16, seq uint32, flags byte) []byte {
+    b := make([]byte, 20)
+    binary.BigEndian.PutUint16(b[0:2], srcPort)
+    binary.BigEndian.PutUint16(b[2:4], dstPort)
+    binary.BigEndian.PutUint32(b[4:8], seq)
+    b[12] = 5 << 4
+    b[13] = flags
+    binary.BigEndian.PutUint16(b[14:16], 1024)
+    binary.BigEndian.PutUint16(b[16:18], pseudoChecksum(src, dst, 6, b))
+    return b
+}
@@ -0,0 +1,100 @@
+// File: internal/scanner/tls.go
+package scanner
+
+import (
+    "bytes"
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "net"
+    "strconv"
+    "strings"
+    "syscall"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/egress"
+    "goscant/internal/models"
+)
+
+// TLSColumns are the Result.Meta keys set by the TLS scanner.
+var TLSColumns = []string{"tls_version", "tls_cipher"}
+
+// TLSCertColumns are the extra keys set when certificates are collected.
+var TLSCertColumns = []string{"cert_subject", "cert_sans", "cert_issuer", "cert_not_after", "cert_self_signed"}
+
+// ----- TLS handshake scanner -----
+
+// tlsScanner connects like the socket scanner and then attempts a TLS
+// handshake, so open ports are split into TLS and plain services. The
+// certificate is not verified: the aim is identification, not trust.
+type tlsScanner struct {
+    timeouts *timeouts
+    certs    bool // record the leaf certificate
+    egress   *egress.Set
+}
+
+func NewTLSScanner(cfg *config.Config) Scanner {
+    return &tlsScanner{timeouts: newTimeouts(cfg), certs: cfg.TLSCerts, egress: cfg.EgressProfiles}
+}
+
+func (s *tlsScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    res := s.scan(ctx, t)
+    res.Engine = "tls"
+    return res
+}
+
+func (s *tlsScanner) scan(ctx context.Context, t models.ScanTarget) Result {
+    res := Result{IP: t.IP, Port: t.Port, Proto: "tcp"}
+    timeout := s.timeouts.For(t.IP)
+    start := time.Now()
+    d := s.egress.For(t.IP).Dialer("tcp", t.IP, timeout)
+    conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(t.IP, strconv.Itoa(t.Port)))
+    switch {
+    case err != nil && ctx.Err() != nil:
+        res.Status, res.Err = Error, ctx.Err()
+        return res
+    case errors.Is(err, syscall.ECONNREFUSED):
+        res.Status, res.LatencyMS, res.Err = Closed, time.Since(start).Milliseconds(), err
+        return res
+    case err != nil:
+        res.Status, res.LatencyMS, res.Err = Filtered, timeout.Milliseconds(), err
+        return res
+    }
+    defer conn.Close()
+    rtt := time.Since(start)
+    s.timeouts.Observe(t.IP, rtt)
+    res.Status, res.LatencyMS = Open, rtt.Milliseconds()
+
+    hsCtx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    tc := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS10})
+    res.Meta = map[string]string{"tls_version": "none"}
+    if err := tc.HandshakeContext(hsCtx); err != nil {
+        res.Err = err // open, but no TLS handshake completed
+        return res
+    }
+    st := tc.ConnectionState()
+    res.Service = "tls"
+    res.Meta["tls_version"] = tls.VersionName(st.Version)
+    res.Meta["tls_cipher"] = tls.CipherSuiteName(st.CipherSuite)
+    if s.certs && len(st.PeerCertificates) > 0 {
+        certMeta(res.Meta, st.PeerCertificates[0])
+    }
+    return res
+}
+
+// certMeta records the leaf certificate's identity and expiry.
+func certMeta(m map[string]string, c *x509.Certificate) {
+    sans := append([]string{}, c.DNSNames...)
+    for _, ip := range c.IPAddresses {
+        sans = append(sans, ip.String())
+    }
+    selfSigned := bytes.Equal(c.RawIssuer, c.RawSubject) && c.CheckSignatureFrom(c) == nil
+    m["cert_subject"] = c.Subject.String()
+    m["cert_sans"] = strings.Join(sans, ";")
+    m["cert_issuer"] = c.Issuer.String()
+    m["cert_not_after"] = c.NotAfter.UTC().Format(time.RFC3339)
+    m["cert_self_signed"] = strconv.FormatBool(selfSigned)
+}
@@ -0,0 +1,96 @@
+// File: internal/scanner/udp.go
+package scanner
+
+import (
+    "context"
+    "errors"
+    "net"
+    "strconv"
+    "syscall"
+    "time"
+
+    "goscant/internal/config"
+    "goscant/internal/egress"
+    "goscant/internal/models"
+)
+
+// ----- UDP scanner -----
+
+// udpPayloads are datagrams that draw an answer from common services; other
+// ports get an empty datagram.
+var udpPayloads = map[int][]byte{
+    // DNS: standard query for the root NS records
+    53: {0x13, 0x37, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01},
+    // NTP: version 3 client request
+    123: append([]byte{0x1b}, make([]byte, 47)...),
+}
+
+// udpScanner probes UDP ports over connected sockets: any reply -> Open,
+// ICMP port unreachable (ECONNREFUSED) -> Closed, silence -> Filtered,
+// which for UDP also covers open services that ignore the probe.
+type udpScanner struct {
+    timeouts *timeouts
+    egress   *egress.Set
+}
+
+func NewUDPScanner(cfg *config.Config) Scanner {
+    return &udpScanner{timeouts: newTimeouts(cfg), egress: cfg.EgressProfiles}
+}
+
+func (s *udpScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    res := s.scan(ctx, t)
+    res.Engine = "udp"
+    return res
+}
+
+func (s *udpScanner) scan(ctx context.Context, t models.ScanTarget) Result {
+    res := Result{IP: t.IP, Port: t.Port, Proto: "udp"}
+    conn, err := s.egress.For(t.IP).Dialer("udp", t.IP, 0).Dial("udp", net.JoinHostPort(t.IP, strconv.Itoa(t.Port)))
+    if err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+    defer conn.Close()
+    defer closeOnCancel(ctx, conn)()
+
+    timeout := s.timeouts.For(t.IP)
+    start := time.Now()
+    if _, err := conn.Write(udpPayloads[t.Port]); err != nil {
+        res.Status, res.Err = Error, err
+        return res
+    }
+    conn.SetReadDeadline(start.Add(timeout))
+    _, err = conn.Read(make([]byte, 1500))
+    switch {
+    case err != nil && ctx.Err() != nil:
+        res.Status, res.Err = Error, ctx.Err()
+    case errors.Is(err, syscall.ECONNREFUSED):
+        res.Status, res.LatencyMS = Closed, time.Since(start).Milliseconds()
+    case err != nil:
+        res.Status, res.LatencyMS = Filtered, timeout.Milliseconds()
+    default:
+        rtt := time.Since(start)
+        s.timeouts.Observe(t.IP, rtt)
+        res.Status, res.LatencyMS = Open, rtt.Milliseconds()
+    }
+    return res
+}
+
+// mixedScanner sends targets whose port spec named UDP (U:) to a UDP
+// engine and everything else to the scan type's engine.
+type mixedScanner struct {
+    other, udp Scanner
+}
+
+// WithUDP wraps s so that targets with Proto "udp" are UDP-scanned. The
+// wrapper does not batch; closing s stays with the caller.
+func WithUDP(s Scanner, cfg *config.Config) Scanner {
+    return &mixedScanner{other: s, udp: NewUDPScanner(cfg)}
+}
+
+func (m *mixedScanner) Scan(ctx context.Context, t models.ScanTarget) Result {
+    if t.Proto == "udp" {
+        return m.udp.Scan(ctx, t)
+    }
+    return m.other.Scan(ctx, t)
+}
@@ -0,0 +1,320 @@
+// File: internal/seal/seal.go
+
+// Package seal encrypts scan artifacts at rest. A sealed file starts with
+// a magic line and a random file ID, followed by AES-GCM records (one per
+// write), so rows can be appended as they are produced. Each record is
+// bound to its position, and Close adds an empty final record: a reader
+// rejects records that were reordered, dropped or cut off at the end.
+//
+// The secret comes from KeyEnv only; there is no KMS or age support. Each
+// file's key is derived from the secret with scrypt, salted by the file ID.
+package seal
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+
+    "golang.org/x/crypto/scrypt"
+)
+
+// KeyEnv names the environment variable holding the encryption secret.
+const KeyEnv = "GOSCANT_SEAL_KEY"
+
+var (
+    magic       = []byte("GOSCANT-SEALED-2\n")
+    magicPrefix = []byte("GOSCANT-SEALED-")
+)
+
+const (
+    idLen     = 16
+    maxRecord = 16 << 20
+)
+
+// Record kinds, authenticated with each record.
+const (
+    kindData  byte = 0
+    kindFinal byte = 1
+)
+
+var errTorn = errors.New("sealed file: truncated record")
+
+// KeyFromEnv returns the secret in KeyEnv.
+func KeyFromEnv() ([]byte, error) {
+    secret := os.Getenv(KeyEnv)
+    if len(secret) < 16 {
+        return nil, errors.New("encryption requires " + KeyEnv + " to hold a secret of at least 16 bytes")
+    }
+    return []byte(secret), nil
+}
+
+// newGCM derives the key of the file with header from secret.
+func newGCM(secret, header []byte) (cipher.AEAD, error) {
+    key, err := scrypt.Key(secret, header[len(magic):], 1<<15, 8, 1, 32)
+    if err != nil {
+        return nil, err
+    }
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
+
+// aad binds a record to its file, position and kind.
+func aad(header []byte, seq uint64, kind byte) []byte {
+    b := binary.BigEndian.AppendUint64(append([]byte(nil), header...), seq)
+    return append(b, kind)
+}
+
+// Writer seals each Write as one record.
+type Writer struct {
+    f      *os.File
+    gcm    cipher.AEAD
+    header []byte // authenticated with every record
+    seq    uint64 // of the next record
+}
+
+// Create opens path for writing, sealed with the secret key unless key is
+// nil. With appendTo set an existing file is continued rather than
+// truncated: its final record, or a record torn by a crash, is dropped and
+// the sequence resumes after the last complete record.
+func Create(path string, key []byte, appendTo bool) (io.WriteCloser, error) {
+    if key == nil {
+        if appendTo {
+            return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+        }
+        return os.Create(path)
+    }
+    if appendTo {
+        if st, err := os.Stat(path); err == nil && st.Size() > 0 {
+            return reopen(path, key)
+        }
+    }
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+    if err != nil {
+        return nil, err
+    }
+    header := make([]byte, len(magic)+idLen)
+    copy(header, magic)
+    if _, err := rand.Read(header[len(magic):]); err != nil {
+        f.Close()
+        return nil, err
+    }
+    gcm, err := newGCM(key, header)
+    if err != nil {
+        f.Close()
+        return nil, err
+    }
+    if _, err := f.Write(header); err != nil {
+        f.Close()
+        return nil, err
+    }
+    return &Writer{f: f, gcm: gcm, header: header}, nil
+}
+
+// reopen continues the sealed file at path after its last data record.
+func reopen(path string, key []byte) (*Writer, error) {
+    f, err := os.OpenFile(path, os.O_RDWR, 0600)
+    if err != nil {
+        return nil, err
+    }
+    r := bufio.NewReader(f)
+    header := make([]byte, len(magic)+idLen)
+    if _, err := io.ReadFull(r, header); err != nil || !bytes.Equal(header[:len(magic)], magic) {
+        f.Close()
+        return nil, fmt.Errorf("%s: not a sealed file, cannot append encrypted rows", path)
+    }
+    gcm, err := newGCM(key, header)
+    if err != nil {
+        f.Close()
+        return nil, err
+    }
+    rr := &reader{r: r, gcm: gcm, header: header}
+    end := int64(len(header))
+    for {
+        kind, size, err := rr.next()
+        if err == io.EOF || err == errTorn {
+            break // a crash may have cut the last record short
+        }
+        if err != nil {
+            f.Close()
+            return nil, fmt.Errorf("%s: %w", path, err)
+        }
+        if kind == kindFinal {
+            rr.seq--
+            break
+        }
+        end += size
+    }
+    if err := f.Truncate(end); err != nil {
+        f.Close()
+        return nil, err
+    }
+    if _, err := f.Seek(end, io.SeekStart); err != nil {
+        f.Close()
+        return nil, err
+    }
+    return &Writer{f: f, gcm: gcm, header: header, seq: rr.seq}, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+    if err := w.record(kindData, p); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+// record seals p as the next record.
+func (w *Writer) record(kind byte, p []byte) error {
+    nonce := make([]byte, w.gcm.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return err
+    }
+    rec := w.gcm.Seal(append([]byte{kind}, nonce...), nonce, p, aad(w.header, w.seq, kind))
+    buf := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(rec)), uint32(len(rec)))
+    if _, err := w.f.Write(append(buf, rec...)); err != nil {
+        return err
+    }
+    w.seq++
+    return nil
+}
+
+// Close writes the final record, without which readers treat the file as
+// truncated.
+func (w *Writer) Close() error {
+    if err := w.record(kindFinal, nil); err != nil {
+        w.f.Close()
+        return err
+    }
+    return w.f.Close()
+}
+
+// WriteFile is os.WriteFile, sealed with key unless key is nil.
+func WriteFile(path string, data []byte, key []byte) error {
+    w, err := Create(path, key, false)
+    if err != nil {
+        return err
+    }
+    if _, err := w.Write(data); err != nil {
+        w.Close()
+        return err
+    }
+    return w.Close()
+}
+
+// reader decrypts the records of a sealed file.
+type reader struct {
+    f      *os.File
+    r      *bufio.Reader
+    gcm    cipher.AEAD
+    header []byte
+    seq    uint64 // of the next record
+    final  bool   // the final record was read
+    buf    []byte // plaintext not yet returned
+}
+
+// Open opens path for reading. Sealed files are decrypted with the secret
+// from KeyEnv; anything else is returned as is.
+func Open(path string) (io.ReadCloser, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    r := bufio.NewReader(f)
+    if head, _ := r.Peek(len(magic)); !bytes.HasPrefix(head, magicPrefix) {
+        return struct {
+            io.Reader
+            io.Closer
+        }{r, f}, nil
+    } else if !bytes.Equal(head, magic) {
+        f.Close()
+        return nil, fmt.Errorf("%s: sealed by an incompatible goscant version", path)
+    }
+    key, err := KeyFromEnv()
+    if err != nil {
+        f.Close()
+        return nil, fmt.Errorf("%s is encrypted: %w", path, err)
+    }
+    header := make([]byte, len(magic)+idLen)
+    if _, err := io.ReadFull(r, header); err != nil {
+        f.Close()
+        return nil, fmt.Errorf("%s: truncated header", path)
+    }
+    gcm, err := newGCM(key, header)
+    if err != nil {
+        f.Close()
+        return nil, err
+    }
+    return &reader{f: f, r: r, gcm: gcm, header: header}, nil
+}
+
+// next reads and decrypts the following record into buf, returning its
+// kind and its size in the file. io.EOF means there are no more records.
+func (r *reader) next() (byte, int64, error) {
+    var size [4]byte
+    if _, err := io.ReadFull(r.r, size[:]); err != nil {
+        if err == io.ErrUnexpectedEOF {
+            return 0, 0, errTorn
+        }
+        return 0, 0, err // io.EOF at a record boundary
+    }
+    n := binary.BigEndian.Uint32(size[:])
+    ns := r.gcm.NonceSize()
+    if n > maxRecord || int(n) < 1+ns {
+        return 0, 0, errors.New("sealed file: corrupt record length")
+    }
+    rec := make([]byte, n)
+    if _, err := io.ReadFull(r.r, rec); err != nil {
+        return 0, 0, errTorn
+    }
+    kind := rec[0]
+    plain, err := r.gcm.Open(nil, rec[1:1+ns], rec[1+ns:], aad(r.header, r.seq, kind))
+    if err != nil {
+        return 0, 0, errors.New("sealed file: cannot decrypt (wrong key, or tampered or reordered data)")
+    }
+    r.buf = plain
+    r.seq++
+    return kind, int64(4 + n), nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+    for len(r.buf) == 0 {
+        if r.final {
+            if _, err := r.r.ReadByte(); err != io.EOF {
+                return 0, errors.New("sealed file: data after the final record")
+            }
+            return 0, io.EOF
+        }
+        kind, _, err := r.next()
+        if err == io.EOF {
+            return 0, errors.New("sealed file: truncated (no final record; the run that wrote it may have been killed)")
+        }
+        if err != nil {
+            return 0, err
+        }
+        r.final = kind == kindFinal
+    }
+    n := copy(p, r.buf)
+    r.buf = r.buf[n:]
+    return n, nil
+}
+
+func (r *reader) Close() error { return r.f.Close() }
+
+// ReadFile is os.ReadFile for files that may be sealed.
+func ReadFile(path string) ([]byte, error) {
+    r, err := Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer r.Close()
+    return io.ReadAll(r)
+}
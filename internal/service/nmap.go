@@ -0,0 +1,231 @@
+// File: internal/service/nmap.go
+package service
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// loadNmap parses an nmap-service-probes file. Only TCP probes are kept.
+// Patterns RE2 cannot compile (backreferences, lookaround) are skipped, as
+// are the version fields nmap fills with helpers other than $P().
+func loadNmap(path string) ([]probeDef, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var out []probeDef
+    var cur *probeDef // nil while inside a UDP probe
+    skipped := 0
+    in := bufio.NewScanner(f)
+    in.Buffer(make([]byte, 64<<10), 1<<20)
+    for line := 1; in.Scan(); line++ {
+        text := strings.TrimSpace(in.Text())
+        if text == "" || strings.HasPrefix(text, "#") {
+            continue
+        }
+        directive, rest, _ := strings.Cut(text, " ")
+        rest = strings.TrimSpace(rest)
+        fail := func(err error) error { return fmt.Errorf("%s line %d: %w", path, line, err) }
+        switch directive {
+        case "Probe":
+            proto, spec, _ := strings.Cut(rest, " ")
+            name, q, _ := strings.Cut(spec, " ")
+            if proto != "TCP" {
+                cur = nil
+                continue
+            }
+            payload, _, err := delimited(strings.TrimPrefix(q, "q"))
+            if err != nil {
+                return nil, fail(err)
+            }
+            out = append(out, probeDef{name: name, payload: unescape(payload), rarity: 1})
+            cur = &out[len(out)-1]
+        case "ports", "sslports":
+            if cur == nil || directive == "sslports" {
+                continue // TLS-wrapped probing is not supported
+            }
+            if cur.ports, err = portSet(rest); err != nil {
+                return nil, fail(err)
+            }
+        case "rarity":
+            if cur != nil {
+                cur.rarity, _ = strconv.Atoi(rest)
+            }
+        case "fallback":
+            if cur != nil {
+                cur.fallback = strings.Split(rest, ",")
+            }
+        case "match", "softmatch":
+            if cur == nil {
+                continue
+            }
+            c, err := parseMatch(rest)
+            if err != nil {
+                skipped++
+                continue
+            }
+            c.soft = directive == "softmatch"
+            cur.matches = append(cur.matches, c)
+        }
+    }
+    if err := in.Err(); err != nil {
+        return nil, err
+    }
+    if len(out) == 0 {
+        return nil, fmt.Errorf("%s: no TCP probes found", path)
+    }
+    if skipped > 0 {
+        fmt.Fprintf(os.Stderr, "service-db: skipped %d patterns RE2 cannot compile\n", skipped)
+    }
+    return out, nil
+}
+
+// parseMatch parses `service m/pattern/opts p/product/ v/version/ ...`.
+func parseMatch(s string) (compiled, error) {
+    svc, rest, _ := strings.Cut(s, " ")
+    if !strings.HasPrefix(rest, "m") {
+        return compiled{}, errors.New("match without m// pattern")
+    }
+    pat, rest, err := delimited(rest[1:])
+    if err != nil {
+        return compiled{}, err
+    }
+    flags := ""
+    for len(rest) > 0 && (rest[0] == 'i' || rest[0] == 's') {
+        flags += rest[:1]
+        rest = rest[1:]
+    }
+    if flags != "" {
+        pat = "(?" + flags + ")" + pat
+    }
+    re, err := regexp.Compile(pcreToRE2(pat))
+    if err != nil {
+        return compiled{}, err
+    }
+    fields := map[byte]string{}
+    for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+        if strings.HasPrefix(rest, "cpe:") {
+            _, rest, err = delimited(rest[4:])
+        } else {
+            key := rest[0]
+            var val string
+            val, rest, err = delimited(rest[1:])
+            fields[key] = val
+        }
+        if err != nil {
+            return compiled{}, err
+        }
+        rest = strings.TrimLeft(rest, "a") // cpe "a" flag
+    }
+    version := strings.TrimSpace(fields['p'] + " " + fields['v'])
+    return compiled{re: re, service: svc, version: nmapTemplate(version)}, nil
+}
+
+// delimited splits "/body/rest" on the delimiter given by its first byte.
+func delimited(s string) (body, rest string, err error) {
+    if len(s) < 2 {
+        return "", "", errors.New("missing delimited field")
+    }
+    end := strings.IndexByte(s[1:], s[0])
+    if end < 0 {
+        return "", "", fmt.Errorf("unterminated %c-delimited field", s[0])
+    }
+    return s[1 : 1+end], s[2+end:], nil
+}
+
+var (
+    nmapHelper = regexp.MustCompile(`\$P\((\d)\)`)
+    nmapGroup  = regexp.MustCompile(`\$(\d)`)
+    nmapOther  = regexp.MustCompile(`\$[A-Z]+\([^)]*\)`)
+)
+
+// nmapTemplate converts nmap's $1 and $P(1) to regexp.Expand's ${1} and
+// drops helpers it has no equivalent for.
+func nmapTemplate(t string) string {
+    t = nmapHelper.ReplaceAllString(t, "$$$1")
+    t = nmapOther.ReplaceAllString(t, "")
+    return strings.TrimSpace(nmapGroup.ReplaceAllString(t, "$${$1}"))
+}
+
+// pcreToRE2 rewrites the PCRE escapes nmap uses that RE2 spells differently.
+func pcreToRE2(p string) string {
+    b := &strings.Builder{}
+    for i := 0; i < len(p); i++ {
+        if p[i] == '\\' && i+1 < len(p) && p[i+1] == '0' && (i+2 == len(p) || p[i+2] < '0' || p[i+2] > '7') {
+            b.WriteString(`\x00`)
+            i++
+            continue
+        }
+        if p[i] == '\\' && i+1 < len(p) {
+            b.WriteString(p[i : i+2])
+            i++
+            continue
+        }
+        b.WriteByte(p[i])
+    }
+    return b.String()
+}
+
+// unescape decodes the C-style escapes of a probe string.
+func unescape(s string) string {
+    b := &strings.Builder{}
+    for i := 0; i < len(s); i++ {
+        if s[i] != '\\' || i+1 == len(s) {
+            b.WriteByte(s[i])
+            continue
+        }
+        i++
+        switch s[i] {
+        case 'r':
+            b.WriteByte('\r')
+        case 'n':
+            b.WriteByte('\n')
+        case 't':
+            b.WriteByte('\t')
+        case '0':
+            b.WriteByte(0)
+        case 'x':
+            if i+2 < len(s) {
+                if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+                    b.WriteByte(byte(v))
+                    i += 2
+                    continue
+                }
+            }
+            b.WriteByte('x')
+        default:
+            b.WriteByte(s[i])
+        }
+    }
+    return b.String()
+}
+
+// portSet parses nmap's "21,80,8000-8010" port lists.
+func portSet(s string) (map[int]bool, error) {
+    out := map[int]bool{}
+    for _, part := range strings.Split(s, ",") {
+        lo, hi, isRange := strings.Cut(strings.TrimSpace(part), "-")
+        a, err := strconv.Atoi(lo)
+        if err != nil {
+            return nil, fmt.Errorf("bad port %q", part)
+        }
+        b := a
+        if isRange {
+            if b, err = strconv.Atoi(hi); err != nil {
+                return nil, fmt.Errorf("bad port range %q", part)
+            }
+        }
+        for p := a; p <= b; p++ {
+            out[p] = true
+        }
+    }
+    return out, nil
+}
@@ -0,0 +1,211 @@
+// File: internal/service/service.go
+package service
+
+import (
+    "context"
+    "net"
+    "regexp"
+    "strconv"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// Columns lists the result fields filled in by a Detector.
+var Columns = []string{"service", "version"}
+
+const (
+    maxResponse = 4096
+    maxRarity   = 3 // probes rarer than this run only on their listed ports
+)
+
+type compiled struct {
+    re      *regexp.Regexp
+    service string
+    version string // template; $1.. refer to re's groups
+    soft    bool   // names the service only; keep looking for a version
+}
+
+// probeDef is a payload together with the matches that interpret replies.
+type probeDef struct {
+    name     string
+    payload  string
+    ports    map[int]bool
+    rarity   int
+    matches  []compiled
+    fallback []string // probes whose matches also apply
+}
+
+// Detector identifies services on open TCP ports by sending probes and
+// matching replies against their signatures. The first probe is always
+// the null probe, which only listens for a greeting.
+type Detector struct {
+    timeout time.Duration
+    probes  []probeDef
+    byName  map[string]*probeDef
+}
+
+// New returns a Detector using the built-in signature table, or the
+// nmap-service-probes file at db if it is not empty.
+func New(timeout time.Duration, db string) (*Detector, error) {
+    d := &Detector{timeout: timeout}
+    if db != "" {
+        var err error
+        if d.probes, err = loadNmap(db); err != nil {
+            return nil, err
+        }
+    } else {
+        d.probes = builtinProbes()
+    }
+    d.byName = map[string]*probeDef{}
+    for i := range d.probes {
+        d.byName[d.probes[i].name] = &d.probes[i]
+    }
+    return d, nil
+}
+
+// builtinProbes compiles the tables in signatures.go; every probe shares
+// the full signature list.
+func builtinProbes() []probeDef {
+    sigs := make([]compiled, 0, len(signatures))
+    for _, s := range signatures {
+        sigs = append(sigs, compiled{re: regexp.MustCompile(s.match), service: s.service, version: s.version})
+    }
+    out := []probeDef{{name: "NULL", matches: sigs}}
+    for _, p := range probes {
+        def := probeDef{name: p.name, payload: p.payload, rarity: 1, matches: sigs}
+        if len(p.ports) > 0 {
+            def.ports = map[int]bool{}
+            for _, port := range p.ports {
+                def.ports[port] = true
+            }
+        }
+        out = append(out, def)
+    }
+    return out
+}
+
+// Enrich fills Service, the version annotation and (if empty) Banner for
+// open TCP results. It opens new connections, so it is not cheap.
+func (d *Detector) Enrich(r *scanner.Result) {
+    if r.Status != scanner.Open || r.Proto != "tcp" {
+        return
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), d.timeout*time.Duration(len(d.ordered(r.Port))))
+    defer cancel()
+    svc, version, resp := d.Detect(ctx, r.IP, r.Port)
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    if svc != "" {
+        r.Service = svc
+        r.Meta["version"] = version
+    }
+    if r.Banner == "" && len(resp) > 0 {
+        r.Banner = scanner.SanitizeBanner(resp)
+    }
+}
+
+// Active marks the detector as sending its own traffic to targets.
+func (d *Detector) Active() bool { return true }
+
+// Detect returns the service name, version and the response that matched
+// (or the first response seen if none did).
+func (d *Detector) Detect(ctx context.Context, ip string, port int) (svc, version string, resp []byte) {
+    var first []byte
+    softSvc := ""
+    for _, p := range d.ordered(port) {
+        if ctx.Err() != nil {
+            break
+        }
+        b := d.exchange(ctx, ip, port, p.payload)
+        if len(b) == 0 {
+            continue
+        }
+        if first == nil {
+            first = b
+        }
+        s, v, soft := d.match(p, b)
+        if s != "" && !soft {
+            return s, v, b
+        }
+        if s != "" && softSvc == "" {
+            softSvc = s
+        }
+    }
+    return softSvc, "", first
+}
+
+// ordered lists the probes to try on port: the null probe, probes meant
+// for port, then common probes.
+func (d *Detector) ordered(port int) []*probeDef {
+    var null, mine, rest []*probeDef
+    for i := range d.probes {
+        p := &d.probes[i]
+        switch {
+        case p.payload == "":
+            null = append(null, p)
+        case p.ports[port]:
+            mine = append(mine, p)
+        case p.rarity <= maxRarity:
+            rest = append(rest, p)
+        }
+    }
+    return append(append(null, mine...), rest...)
+}
+
+// exchange connects, sends payload (if any) and returns what comes back
+// within the timeout.
+func (d *Detector) exchange(ctx context.Context, ip string, port int, payload string) []byte {
+    dialer := net.Dialer{Timeout: d.timeout}
+    conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+    if err != nil {
+        return nil
+    }
+    defer conn.Close()
+    conn.SetDeadline(time.Now().Add(d.timeout))
+    if payload != "" {
+        if _, err := conn.Write([]byte(payload)); err != nil {
+            return nil
+        }
+    }
+    buf := make([]byte, maxResponse)
+    n := 0
+    for n < len(buf) {
+        m, err := conn.Read(buf[n:])
+        n += m
+        if err != nil {
+            break
+        }
+        // The rest of a reply follows closely; don't wait out the timeout.
+        conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+    }
+    return buf[:n]
+}
+
+// match checks b against p's signatures and those of its fallbacks; a hard
+// match wins over a soft one.
+func (d *Detector) match(p *probeDef, b []byte) (svc, version string, soft bool) {
+    sets := [][]compiled{p.matches}
+    for _, name := range p.fallback {
+        if fp, ok := d.byName[name]; ok {
+            sets = append(sets, fp.matches)
+        }
+    }
+    for _, set := range sets {
+        for _, s := range set {
+            m := s.re.FindSubmatchIndex(b)
+            if m == nil {
+                continue
+            }
+            if s.soft {
+                if svc == "" {
+                    svc, soft = s.service, true
+                }
+                continue
+            }
+            return s.service, string(s.re.Expand(nil, []byte(s.version), b, m)), false
+        }
+    }
+    return svc, "", soft
+}
@@ -0,0 +1,55 @@
+// File: internal/service/signatures.go
+package service
+
+// probe is a payload sent to provoke a service into identifying itself.
+type probe struct {
+    name    string
+    payload string
+    ports   []int // tried first on these ports; empty means every port
+}
+
+// probes are tried in order after the null probe (which just listens).
+var probes = []probe{
+    {name: "http", payload: "GET / HTTP/1.0\r\n\r\n", ports: []int{80, 81, 591, 8000, 8008, 8080, 8081, 8888}},
+    {name: "redis", payload: "PING\r\n", ports: []int{6379}},
+    {name: "generic", payload: "\r\n\r\n"},
+}
+
+// signature maps a response pattern to a service and version template;
+// $1.. in version refer to the pattern's groups.
+type signature struct {
+    match   string
+    service string
+    version string
+}
+
+// signatures are checked in order; put specific patterns before generic ones.
+var signatures = []signature{
+    {`^SSH-[\d.]+-OpenSSH_([\w.]+)`, "ssh", "OpenSSH $1"},
+    {`^SSH-[\d.]+-dropbear_([\w.]+)`, "ssh", "Dropbear $1"},
+    {`^SSH-[\d.]+-(\S+)`, "ssh", "$1"},
+    {`^220[ -][^\r\n]*ProFTPD ([\w.]+)`, "ftp", "ProFTPD $1"},
+    {`^220[ -][^\r\n]*\(vsFTPd ([\w.]+)\)`, "ftp", "vsftpd $1"},
+    {`^220[ -][^\r\n]*Pure-FTPd`, "ftp", "Pure-FTPd"},
+    {`^220[ -][^\r\n]*FileZilla Server ([\w.]+)`, "ftp", "FileZilla $1"},
+    {`^220[ -][^\r\n]*ESMTP Postfix`, "smtp", "Postfix"},
+    {`^220[ -][^\r\n]*ESMTP Exim ([\w.]+)`, "smtp", "Exim $1"},
+    {`^220[ -][^\r\n]*Microsoft ESMTP MAIL Service`, "smtp", "Microsoft Exchange"},
+    {`^220[ -][^\r\n]*SMTP`, "smtp", ""},
+    {`^220[ -][^\r\n]*FTP`, "ftp", ""},
+    {`^\+OK[^\r\n]*Dovecot`, "pop3", "Dovecot"},
+    {`^\+OK`, "pop3", ""},
+    {`^\* OK[^\r\n]*Dovecot`, "imap", "Dovecot"},
+    {`^\* OK[^\r\n]*IMAP`, "imap", ""},
+    {`(?s)^HTTP/1\.[01] \d{3}.*?\r\nServer: nginx/([\w.]+)`, "http", "nginx $1"},
+    {`(?s)^HTTP/1\.[01] \d{3}.*?\r\nServer: Apache/([\w.]+)`, "http", "Apache httpd $1"},
+    {`(?s)^HTTP/1\.[01] \d{3}.*?\r\nServer: Microsoft-IIS/([\w.]+)`, "http", "Microsoft IIS $1"},
+    {`(?s)^HTTP/1\.[01] \d{3}.*?\r\nServer: ([^\r\n]+)`, "http", "$1"},
+    {`^HTTP/1\.[01] \d{3}`, "http", ""},
+    {`^\+PONG`, "redis", ""},
+    {`^-NOAUTH`, "redis", ""},
+    {`(?s)^.\x00\x00\x00\x0a[\d.]+-([\d.]+)-MariaDB`, "mysql", "MariaDB $1"},
+    {`(?s)^.\x00\x00\x00\x0a([\d.]+[\w.-]*)\x00`, "mysql", "MySQL $1"},
+    {`^RFB (\d{3}\.\d{3})`, "vnc", "RFB $1"},
+    {`^AMQP`, "amqp", ""},
+}
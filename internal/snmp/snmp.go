@@ -0,0 +1,232 @@
+// File: internal/snmp/snmp.go
+package snmp
+
+import (
+    "bytes"
+    "context"
+    "crypto/rand"
+    "encoding/binary"
+    "errors"
+    "net"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// Columns lists the Result.Meta keys set by a Checker.
+var Columns = []string{"snmp_community", "snmp_sysdescr"}
+
+// Port is the SNMP agent port checked.
+const Port = 161
+
+// sysDescr.0 (1.3.6.1.2.1.1.1.0), BER-encoded.
+var sysDescrOID = []byte{0x2b, 6, 1, 2, 1, 1, 1, 0}
+
+// Checker tries community strings against SNMP agents with a v2c GET of
+// sysDescr.0. Any valid reply proves 161/udp open and the community
+// accepted.
+type Checker struct {
+    communities []string
+    timeout     time.Duration
+}
+
+// New returns a Checker trying communities in order, waiting timeout for
+// each reply.
+func New(communities []string, timeout time.Duration) *Checker {
+    return &Checker{communities: communities, timeout: timeout}
+}
+
+// Parse splits a comma-separated community list, dropping empty entries.
+func Parse(list string) []string {
+    out := []string{}
+    for _, c := range strings.Split(list, ",") {
+        if c = strings.TrimSpace(c); c != "" {
+            out = append(out, c)
+        }
+    }
+    return out
+}
+
+// Active marks the checker as sending its own traffic to targets.
+func (c *Checker) Active() bool { return true }
+
+// Enrich runs the check for results on port 161. The scan itself says
+// nothing about UDP, so the row's status is not consulted.
+func (c *Checker) Enrich(r *scanner.Result) {
+    if r.Port != Port {
+        return
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), c.timeout*time.Duration(len(c.communities)))
+    defer cancel()
+    community, descr, ok := c.Check(ctx, r.IP)
+    if !ok {
+        return
+    }
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    r.Meta["snmp_community"] = community
+    r.Meta["snmp_sysdescr"] = scanner.SanitizeBanner([]byte(descr))
+}
+
+// Check returns the first community ip answers to, with its sysDescr.
+func (c *Checker) Check(ctx context.Context, ip string) (community, descr string, ok bool) {
+    for _, try := range c.communities {
+        if ctx.Err() != nil {
+            return "", "", false
+        }
+        if descr, err := c.get(ctx, ip, try); err == nil {
+            return try, descr, true
+        }
+    }
+    return "", "", false
+}
+
+// get sends one GetRequest for sysDescr.0 and decodes the reply.
+func (c *Checker) get(ctx context.Context, ip, community string) (string, error) {
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "udp", net.JoinHostPort(ip, "161"))
+    if err != nil {
+        return "", err
+    }
+    defer conn.Close()
+    deadline := time.Now().Add(c.timeout)
+    if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+        deadline = dl
+    }
+    conn.SetDeadline(deadline)
+
+    var id [4]byte
+    rand.Read(id[:])
+    reqID := int32(binary.BigEndian.Uint32(id[:]) & 0x7fffffff)
+    if _, err := conn.Write(getRequest(community, reqID)); err != nil {
+        return "", err
+    }
+    buf := make([]byte, 65535)
+    for {
+        n, err := conn.Read(buf)
+        if err != nil {
+            return "", err
+        }
+        if descr, err := parseResponse(buf[:n], community, reqID); err == nil {
+            return descr, nil
+        }
+    }
+}
+
+// ----- BER encoding -----
+
+func tlv(tag byte, content ...[]byte) []byte {
+    body := bytes.Join(content, nil)
+    out := []byte{tag}
+    switch n := len(body); {
+    case n < 0x80:
+        out = append(out, byte(n))
+    case n < 0x100:
+        out = append(out, 0x81, byte(n))
+    default:
+        out = append(out, 0x82, byte(n>>8), byte(n))
+    }
+    return append(out, body...)
+}
+
+func berInt(v int32) []byte {
+    b := binary.BigEndian.AppendUint32(nil, uint32(v))
+    for len(b) > 1 && (b[0] == 0 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0) {
+        b = b[1:]
+    }
+    return tlv(0x02, b)
+}
+
+// getRequest builds an SNMPv2c GetRequest for sysDescr.0.
+func getRequest(community string, reqID int32) []byte {
+    varbind := tlv(0x30, tlv(0x06, sysDescrOID), []byte{0x05, 0x00})
+    pdu := tlv(0xa0, berInt(reqID), berInt(0), berInt(0), tlv(0x30, varbind))
+    return tlv(0x30, berInt(1), tlv(0x04, []byte(community)), pdu)
+}
+
+// ----- BER decoding -----
+
+var errMalformed = errors.New("snmp: malformed response")
+
+// next splits the first TLV off b, checking its tag.
+func next(b []byte, tag byte) (content, rest []byte, err error) {
+    if len(b) < 2 || b[0] != tag {
+        return nil, nil, errMalformed
+    }
+    n, hdr := int(b[1]), 2
+    if n&0x80 != 0 {
+        k := n & 0x7f
+        if k == 0 || k > 2 || len(b) < 2+k {
+            return nil, nil, errMalformed
+        }
+        n = 0
+        for _, x := range b[2 : 2+k] {
+            n = n<<8 | int(x)
+        }
+        hdr += k
+    }
+    if len(b) < hdr+n {
+        return nil, nil, errMalformed
+    }
+    return b[hdr : hdr+n], b[hdr+n:], nil
+}
+
+func decodeInt(b []byte) int64 {
+    var v int64
+    for i, x := range b {
+        if i == 0 && x&0x80 != 0 {
+            v = -1
+        }
+        v = v<<8 | int64(x)
+    }
+    return v
+}
+
+// parseResponse extracts sysDescr from a GetResponse matching community
+// and reqID.
+func parseResponse(b []byte, community string, reqID int32) (string, error) {
+    msg, _, err := next(b, 0x30)
+    if err != nil {
+        return "", err
+    }
+    _, msg, err = next(msg, 0x02) // version
+    if err != nil {
+        return "", err
+    }
+    comm, msg, err := next(msg, 0x04)
+    if err != nil || string(comm) != community {
+        return "", errMalformed
+    }
+    pdu, _, err := next(msg, 0xa2)
+    if err != nil {
+        return "", err
+    }
+    fields := make([][]byte, 3) // request-id, error-status, error-index
+    for i := range fields {
+        if fields[i], pdu, err = next(pdu, 0x02); err != nil {
+            return "", err
+        }
+    }
+    if decodeInt(fields[0]) != int64(reqID) || decodeInt(fields[1]) != 0 {
+        return "", errMalformed
+    }
+    list, _, err := next(pdu, 0x30)
+    if err != nil {
+        return "", err
+    }
+    vb, _, err := next(list, 0x30)
+    if err != nil {
+        return "", err
+    }
+    oid, vb, err := next(vb, 0x06)
+    if err != nil || !bytes.Equal(oid, sysDescrOID) {
+        return "", errMalformed
+    }
+    val, _, err := next(vb, 0x04) // noSuchObject etc. are not octet strings
+    if err != nil {
+        return "", err
+    }
+    return string(val), nil
+}
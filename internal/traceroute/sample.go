@@ -0,0 +1,111 @@
+// File: internal/traceroute/sample.go
+package traceroute
+
+import (
+    "bytes"
+    "context"
+    "encoding/csv"
+    "strconv"
+    "sync"
+
+    "goscant/internal/scanner"
+    "goscant/internal/seal"
+)
+
+// traceWorkers bounds how many paths are traced at once.
+const traceWorkers = 8
+
+// Sampler is a writer sink choosing the targets to trace: the first
+// FILTERED port of each host, then the first port of hosts with none, up
+// to n targets in all.
+type Sampler struct {
+    n        int
+    hosts    []string                  // in order of first result
+    filtered map[string]scanner.Result // first FILTERED result per host
+    first    map[string]scanner.Result // first result per host
+}
+
+// NewSampler returns a Sampler choosing up to n targets.
+func NewSampler(n int) *Sampler {
+    return &Sampler{n: n, filtered: map[string]scanner.Result{}, first: map[string]scanner.Result{}}
+}
+
+func (s *Sampler) Write(r scanner.Result) error {
+    if _, ok := s.first[r.IP]; !ok {
+        s.hosts = append(s.hosts, r.IP)
+        s.first[r.IP] = r
+    }
+    if _, ok := s.filtered[r.IP]; !ok && r.Status == scanner.Filtered {
+        s.filtered[r.IP] = r
+    }
+    return nil
+}
+
+func (s *Sampler) Close() error { return nil }
+
+// Sample returns the chosen targets. Call it once the writer is closed.
+func (s *Sampler) Sample() []scanner.Result {
+    out := []scanner.Result{}
+    for _, ip := range s.hosts {
+        if r, ok := s.filtered[ip]; ok && len(out) < s.n {
+            out = append(out, r)
+        }
+    }
+    for _, ip := range s.hosts {
+        if _, ok := s.filtered[ip]; !ok && len(out) < s.n {
+            out = append(out, s.first[ip])
+        }
+    }
+    return out
+}
+
+// Run traces the path toward every target, a few at a time.
+func (t *Tracer) Run(ctx context.Context, targets []scanner.Result) []Path {
+    paths := make([]Path, len(targets))
+    sem := make(chan struct{}, traceWorkers)
+    wg := &sync.WaitGroup{}
+    for i, r := range targets {
+        wg.Add(1)
+        sem <- struct{}{}
+        go func() {
+            defer wg.Done()
+            defer func() { <-sem }()
+            paths[i] = t.Trace(ctx, r.IP, r.Port)
+            paths[i].Status = r.Status.String()
+        }()
+    }
+    wg.Wait()
+    return paths
+}
+
+// WriteReport saves paths as CSV, one row per hop; a silent hop has an
+// empty hop_ip. The file is sealed if key is not nil.
+func WriteReport(path string, paths []Path, key []byte) error {
+    var buf bytes.Buffer
+    w := csv.NewWriter(&buf)
+    w.Write([]string{"dst_ip", "dst_port", "status", "method", "ttl", "hop_ip", "rtt_ms", "note"})
+    for _, p := range paths {
+        if p.Err != nil {
+            w.Write([]string{p.IP, strconv.Itoa(p.Port), p.Status, p.Method, "", "", "", "error: " + p.Err.Error()})
+            continue
+        }
+        for _, h := range p.Hops {
+            note, rtt := "", ""
+            switch {
+            case h.Reached:
+                note = "destination"
+            case h.Blocked:
+                note = "unreachable"
+            }
+            if h.IP != "" {
+                rtt = strconv.FormatInt(h.RTT.Milliseconds(), 10)
+            }
+            w.Write([]string{p.IP, strconv.Itoa(p.Port), p.Status, p.Method, strconv.Itoa(h.TTL), h.IP, rtt, note})
+        }
+    }
+    w.Flush()
+    if err := w.Error(); err != nil {
+        return err
+    }
+    return seal.WriteFile(path, buf.Bytes(), key)
+}
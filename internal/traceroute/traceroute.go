@@ -0,0 +1,295 @@
+// File: internal/traceroute/traceroute.go
+// Package traceroute records the hop path toward a target with TTL-limited
+// ICMP, UDP or TCP probes. Tracing toward a FILTERED port shows how far
+// probes get, and so roughly where they are being dropped.
+package traceroute
+
+import (
+    "context"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "math/rand"
+    "net"
+    "syscall"
+    "time"
+
+    "golang.org/x/net/icmp"
+    "golang.org/x/net/ipv4"
+    "golang.org/x/net/ipv6"
+)
+
+// Methods lists the accepted probe kinds.
+var Methods = []string{"icmp", "udp", "tcp"}
+
+// ErrUnsupported is returned where TTLs cannot be set on sockets.
+var ErrUnsupported = errors.New("traceroute: not supported on this platform")
+
+const (
+    protoICMP   = 1
+    protoICMPv6 = 58
+
+    basePort   = 33434       // classic traceroute UDP destination base
+    maxSilent  = 5           // consecutive silent hops that end a trace
+    hopTimeout = time.Second // wait for an answer per hop
+    pollSlice  = 50 * time.Millisecond
+)
+
+// Hop is one TTL step of a path. IP is empty when nothing answered.
+type Hop struct {
+    TTL     int
+    IP      string
+    RTT     time.Duration
+    Reached bool // IP is the destination itself
+    Blocked bool // a router answered with ICMP unreachable
+}
+
+// Path is the outcome of tracing toward one target.
+type Path struct {
+    IP     string
+    Port   int
+    Status string // what the scan saw on the port
+    Method string
+    Hops   []Hop
+    Err    error
+}
+
+// Summary explains where the path ended.
+func (p Path) Summary() string {
+    dst := net.JoinHostPort(p.IP, fmt.Sprint(p.Port))
+    if p.Err != nil {
+        return fmt.Sprintf("%s: trace failed: %v", dst, p.Err)
+    }
+    var last *Hop
+    for i := range p.Hops {
+        if p.Hops[i].IP != "" {
+            last = &p.Hops[i]
+        }
+    }
+    switch {
+    case last == nil:
+        return fmt.Sprintf("%s (%s): no hop answered", dst, p.Status)
+    case last.Reached:
+        return fmt.Sprintf("%s (%s): destination reached in %d hops", dst, p.Status, last.TTL)
+    case last.Blocked:
+        return fmt.Sprintf("%s (%s): rejected by %s at hop %d", dst, p.Status, last.IP, last.TTL)
+    }
+    return fmt.Sprintf("%s (%s): silent beyond hop %d (%s), filtering likely past it", dst, p.Status, last.TTL, last.IP)
+}
+
+// Tracer traces paths with one probe method.
+type Tracer struct {
+    method  string
+    maxHops int
+}
+
+// New returns a Tracer probing with method (one of Methods) up to maxHops.
+func New(method string, maxHops int) (*Tracer, error) {
+    for _, m := range Methods {
+        if m == method {
+            if maxHops < 1 || maxHops > 255 {
+                return nil, fmt.Errorf("traceroute: max hops %d out of range 1-255", maxHops)
+            }
+            return &Tracer{method: method, maxHops: maxHops}, nil
+        }
+    }
+    return nil, fmt.Errorf("traceroute: unknown method %q", method)
+}
+
+// Trace probes toward ip (and port, for tcp) with increasing TTLs until the
+// destination answers, a router rejects the probe, maxSilent hops in a row
+// stay silent or maxHops is reached. It needs raw ICMP sockets.
+func (t *Tracer) Trace(ctx context.Context, ip string, port int) Path {
+    p := Path{IP: ip, Port: port, Method: t.method}
+    dst := net.ParseIP(ip)
+    if dst == nil {
+        p.Err = fmt.Errorf("invalid address %q", ip)
+        return p
+    }
+    network := "ip4:icmp"
+    if dst.To4() == nil {
+        network = "ip6:ipv6-icmp"
+    }
+    c, err := net.ListenPacket(network, "")
+    if err != nil {
+        p.Err = err
+        return p
+    }
+    defer c.Close()
+    stop := context.AfterFunc(ctx, func() { c.Close() })
+    defer stop()
+
+    id, silent := rand.Intn(0xffff), 0
+    for ttl := 1; ttl <= t.maxHops && ctx.Err() == nil; ttl++ {
+        h, err := t.hop(ctx, c.(*net.IPConn), dst, port, id, ttl)
+        if err != nil {
+            if ctx.Err() == nil {
+                p.Err = err
+            }
+            break
+        }
+        p.Hops = append(p.Hops, h)
+        if h.Reached || h.Blocked {
+            break
+        }
+        if h.IP != "" {
+            silent = 0
+        } else if silent++; silent >= maxSilent {
+            break
+        }
+    }
+    return p
+}
+
+// probe describes what an ICMP error must quote to answer one hop.
+type probe struct {
+    dst          net.IP
+    v6           bool
+    sport, dport int // 0 matches any
+    id, seq      int // icmp method
+}
+
+// hop sends one probe with ttl and waits up to hopTimeout for its answer.
+func (t *Tracer) hop(ctx context.Context, c *net.IPConn, dst net.IP, port, id, ttl int) (Hop, error) {
+    pr := probe{dst: dst, v6: dst.To4() == nil, id: id, seq: ttl}
+    control := func(network, address string, rc syscall.RawConn) error { return setTTL(rc, pr.v6, ttl) }
+    start := time.Now()
+    reached := make(chan struct{}, 1)
+
+    switch t.method {
+    case "icmp":
+        if err := t.sendEcho(c, pr, ttl); err != nil {
+            return Hop{}, err
+        }
+    case "udp":
+        pr.dport = basePort + ttl - 1
+        d := net.Dialer{Control: control}
+        uc, err := d.DialContext(ctx, "udp", net.JoinHostPort(dst.String(), fmt.Sprint(pr.dport)))
+        if err != nil {
+            return Hop{}, err
+        }
+        defer uc.Close()
+        pr.sport = uc.LocalAddr().(*net.UDPAddr).Port
+        if _, err := uc.Write([]byte("goscant")); err != nil && !errors.Is(err, syscall.ECONNREFUSED) {
+            return Hop{}, err
+        }
+    case "tcp":
+        pr.dport = port
+        d := net.Dialer{Control: control, Timeout: hopTimeout}
+        go func() {
+            tc, err := d.DialContext(ctx, "tcp", net.JoinHostPort(dst.String(), fmt.Sprint(port)))
+            if err == nil {
+                tc.Close()
+            }
+            if err == nil || errors.Is(err, syscall.ECONNREFUSED) {
+                reached <- struct{}{}
+            }
+        }()
+    }
+
+    proto := protoICMP
+    if pr.v6 {
+        proto = protoICMPv6
+    }
+    deadline := start.Add(hopTimeout)
+    buf := make([]byte, 1500)
+    for time.Now().Before(deadline) {
+        select {
+        case <-reached:
+            return Hop{TTL: ttl, IP: dst.String(), RTT: time.Since(start), Reached: true}, nil
+        default:
+        }
+        slice := time.Now().Add(pollSlice)
+        if slice.After(deadline) {
+            slice = deadline
+        }
+        c.SetReadDeadline(slice)
+        n, from, err := c.ReadFrom(buf)
+        if err != nil {
+            var ne net.Error
+            if errors.As(err, &ne) && ne.Timeout() {
+                continue
+            }
+            return Hop{}, err
+        }
+        m, err := icmp.ParseMessage(proto, buf[:n])
+        if err != nil {
+            continue
+        }
+        src := from.(*net.IPAddr).IP
+        h := Hop{TTL: ttl, IP: src.String(), RTT: time.Since(start)}
+        switch body := m.Body.(type) {
+        case *icmp.TimeExceeded:
+            if pr.matches(body.Data) {
+                return h, nil
+            }
+        case *icmp.DstUnreach:
+            if pr.matches(body.Data) {
+                h.Reached = src.Equal(dst)
+                h.Blocked = !h.Reached
+                return h, nil
+            }
+        case *icmp.Echo:
+            isReply := m.Type == ipv4.ICMPTypeEchoReply || m.Type == ipv6.ICMPTypeEchoReply
+            if t.method == "icmp" && isReply && src.Equal(dst) && body.ID == pr.id && body.Seq == pr.seq {
+                h.Reached = true
+                return h, nil
+            }
+        }
+    }
+    return Hop{TTL: ttl}, nil
+}
+
+// sendEcho sends an ICMP Echo Request carrying pr's id and sequence.
+func (t *Tracer) sendEcho(c *net.IPConn, pr probe, ttl int) error {
+    rc, err := c.SyscallConn()
+    if err != nil {
+        return err
+    }
+    if err := setTTL(rc, pr.v6, ttl); err != nil {
+        return err
+    }
+    var typ icmp.Type = ipv4.ICMPTypeEcho
+    if pr.v6 {
+        typ = ipv6.ICMPTypeEchoRequest
+    }
+    msg := icmp.Message{Type: typ, Body: &icmp.Echo{ID: pr.id, Seq: pr.seq, Data: []byte("goscant")}}
+    b, err := msg.Marshal(nil) // the kernel fills in the ICMPv6 checksum
+    if err != nil {
+        return err
+    }
+    _, err = c.WriteTo(b, &net.IPAddr{IP: pr.dst})
+    return err
+}
+
+// matches reports whether the datagram quoted by an ICMP error (IP header
+// plus the first 8 bytes of its payload) is this probe.
+func (pr probe) matches(q []byte) bool {
+    var dst net.IP
+    var tp []byte
+    if pr.v6 {
+        if len(q) < 48 {
+            return false
+        }
+        dst, tp = q[24:40], q[40:48]
+    } else {
+        if len(q) < 20 {
+            return false
+        }
+        ihl := int(q[0]&0x0f) * 4
+        if len(q) < ihl+8 {
+            return false
+        }
+        dst, tp = q[16:20], q[ihl:ihl+8]
+    }
+    if !dst.Equal(pr.dst) {
+        return false
+    }
+    if pr.dport == 0 {
+        return int(binary.BigEndian.Uint16(tp[4:6])) == pr.id && int(binary.BigEndian.Uint16(tp[6:8])) == pr.seq
+    }
+    if pr.sport != 0 && int(binary.BigEndian.Uint16(tp[0:2])) != pr.sport {
+        return false
+    }
+    return int(binary.BigEndian.Uint16(tp[2:4])) == pr.dport
+}
@@ -0,0 +1,8 @@
+// File: internal/traceroute/ttl_other.go
+//go:build !unix
+
+package traceroute
+
+import "syscall"
+
+func setTTL(rc syscall.RawConn, v6 bool, ttl int) error { return ErrUnsupported }
@@ -0,0 +1,22 @@
+// File: internal/traceroute/ttl_unix.go
+//go:build unix
+
+package traceroute
+
+import "syscall"
+
+// setTTL sets the unicast TTL (hop limit for IPv6) on the socket behind rc.
+func setTTL(rc syscall.RawConn, v6 bool, ttl int) error {
+    var serr error
+    err := rc.Control(func(fd uintptr) {
+        if v6 {
+            serr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, ttl)
+            return
+        }
+        serr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+    })
+    if err != nil {
+        return err
+    }
+    return serr
+}
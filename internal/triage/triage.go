@@ -0,0 +1,107 @@
+// File: internal/triage/triage.go
+package triage
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "regexp"
+    "strings"
+
+    "goscant/internal/scanner"
+)
+
+// Columns lists the result fields filled in by a RuleSet.
+var Columns = []string{"label", "severity"}
+
+// Severities in ascending order; the highest matching one wins.
+var Severities = []string{"info", "low", "medium", "high", "critical"}
+
+// Rule flags results whose field matches a regular expression.
+type Rule struct {
+    Field    string `json:"field"` // banner, service or any annotation key
+    Match    string `json:"match"`
+    Label    string `json:"label"`
+    Severity string `json:"severity"`
+
+    re *regexp.Regexp
+}
+
+// RuleSet is an ordered list of triage rules.
+type RuleSet struct {
+    rules []Rule
+}
+
+// Load reads a JSON array of rules, e.g.
+//
+//	[{"field": "banner", "match": "(?i)default password", "label": "default-creds", "severity": "high"}]
+func Load(path string) (*RuleSet, error) {
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var rules []Rule
+    if err := json.Unmarshal(b, &rules); err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    for i := range rules {
+        r := &rules[i]
+        if r.Field == "" || r.Label == "" {
+            return nil, fmt.Errorf("%s rule %d: field and label are required", path, i+1)
+        }
+        if r.re, err = regexp.Compile(r.Match); err != nil {
+            return nil, fmt.Errorf("%s rule %d: %w", path, i+1, err)
+        }
+        r.Severity = strings.ToLower(r.Severity)
+        if r.Severity == "" {
+            r.Severity = "info"
+        }
+        if Rank(r.Severity) < 0 {
+            return nil, fmt.Errorf("%s rule %d: unknown severity %q", path, i+1, r.Severity)
+        }
+    }
+    return &RuleSet{rules: rules}, nil
+}
+
+// Rank returns the position of severity in Severities, or -1.
+func Rank(severity string) int {
+    for i, s := range Severities {
+        if s == severity {
+            return i
+        }
+    }
+    return -1
+}
+
+func field(r *scanner.Result, name string) string {
+    switch name {
+    case "banner":
+        return r.Banner
+    case "service":
+        return r.Service
+    }
+    return r.Meta[name]
+}
+
+// Enrich labels r with every matching rule and the highest severity among them.
+func (s *RuleSet) Enrich(r *scanner.Result) {
+    labels, best := []string{}, -1
+    for _, rule := range s.rules {
+        v := field(r, rule.Field)
+        if v == "" || !rule.re.MatchString(v) {
+            continue
+        }
+        labels = append(labels, rule.Label)
+        if n := Rank(rule.Severity); n > best {
+            best = n
+        }
+    }
+    if len(labels) == 0 {
+        return
+    }
+    if r.Meta == nil {
+        r.Meta = map[string]string{}
+    }
+    r.Meta["label"] = strings.Join(labels, ";")
+    r.Meta["severity"] = Severities[best]
+}
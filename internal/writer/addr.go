@@ -0,0 +1,49 @@
+// File: internal/writer/addr.go
+package writer
+
+import (
+    "encoding/binary"
+    "fmt"
+    "net"
+    "strconv"
+)
+
+// AddrFormats lists the accepted --addr-format values.
+var AddrFormats = []string{"canonical", "int"}
+
+// formatAddr renders ip consistently: "canonical" is dotted-quad IPv4 and
+// zero-compressed lower-case IPv6 (RFC 5952); "int" writes IPv4 as its
+// 32-bit integer and IPv6 canonically. Non-addresses (e.g. pseudonyms)
+// pass through unchanged.
+func formatAddr(ip, format string) string {
+    addr := net.ParseIP(ip)
+    if addr == nil {
+        return ip
+    }
+    if v4 := addr.To4(); v4 != nil && format == "int" {
+        return strconv.FormatUint(uint64(binary.BigEndian.Uint32(v4)), 10)
+    }
+    return addr.String()
+}
+
+// SetAddrFormat selects how addresses are written. Call before Run.
+func (c *CSVWriter) SetAddrFormat(format string) error {
+    for _, f := range AddrFormats {
+        if f == format {
+            c.addrFmt = format
+            return nil
+        }
+    }
+    return fmt.Errorf("unknown address format %q", format)
+}
+
+// ParseAddr is the inverse of the "int" format: it turns a decimal IPv4
+// integer back into dotted form and leaves anything else unchanged.
+func ParseAddr(s string) string {
+    if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+        b := make([]byte, 4)
+        binary.BigEndian.PutUint32(b, uint32(n))
+        return net.IP(b).String()
+    }
+    return s
+}
@@ -0,0 +1,118 @@
+// File: internal/writer/aggregate.go
+package writer
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "math"
+    "math/rand/v2"
+    "net"
+    "sort"
+    "strconv"
+
+    "goscant/internal/scanner"
+)
+
+type aggregateKey struct {
+    network, proto, service string
+    port                    int
+}
+
+// aggregateSink counts open hosts per (network, port, service) and writes
+// only those counts when the scan ends. Cells below min are suppressed;
+// with epsilon > 0 each count first gets Laplace noise of scale 1/epsilon
+// (one host changes a cell by at most 1), which makes the release
+// epsilon-differentially private per cell.
+type aggregateSink struct {
+    f       io.WriteCloser
+    min     int
+    epsilon float64
+    hosts   map[aggregateKey]map[string]bool
+}
+
+// AddAggregate writes aggregate counts to path instead of host rows. Call
+// before Run; it should be the only sink.
+func (c *CSVWriter) AddAggregate(path string, min int, epsilon float64) error {
+    if epsilon < 0 {
+        return fmt.Errorf("aggregate epsilon must not be negative, got %v", epsilon)
+    }
+    f, err := openFile(path, false, c.key)
+    if err != nil {
+        return err
+    }
+    c.AddSink(path, &aggregateSink{f: f, min: min, epsilon: epsilon, hosts: map[aggregateKey]map[string]bool{}})
+    return nil
+}
+
+// networkOf returns the /24 (IPv4) or /64 (IPv6) holding ip.
+func networkOf(ip net.IP) *net.IPNet {
+    bits, size := 24, 32
+    if ip.To4() == nil {
+        bits, size = 64, 128
+    }
+    return &net.IPNet{IP: ip.Mask(net.CIDRMask(bits, size)), Mask: net.CIDRMask(bits, size)}
+}
+
+func (s *aggregateSink) Write(r scanner.Result) error {
+    if r.Status != scanner.Open {
+        return nil
+    }
+    k := aggregateKey{network: "unknown", proto: r.Proto, service: r.Service, port: r.Port}
+    if ip := net.ParseIP(ParseAddr(r.IP)); ip != nil {
+        k.network = networkOf(ip).String()
+    }
+    if k.service == "" {
+        k.service = "unknown"
+    }
+    if s.hosts[k] == nil {
+        s.hosts[k] = map[string]bool{}
+    }
+    s.hosts[k][r.IP] = true
+    return nil
+}
+
+// laplace samples zero-mean Laplace noise with the given scale.
+func laplace(scale float64) float64 {
+    u := rand.Float64() - 0.5
+    return -scale * math.Copysign(1, u) * math.Log(1-2*math.Abs(u))
+}
+
+// Close writes one row per cell that survives suppression.
+func (s *aggregateSink) Close() error {
+    keys := make([]aggregateKey, 0, len(s.hosts))
+    for k := range s.hosts {
+        keys = append(keys, k)
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        a, b := keys[i], keys[j]
+        if a.network != b.network {
+            return a.network < b.network
+        }
+        if a.port != b.port {
+            return a.port < b.port
+        }
+        if a.proto != b.proto {
+            return a.proto < b.proto
+        }
+        return a.service < b.service
+    })
+    w := csv.NewWriter(s.f)
+    w.Write([]string{"network", "port", "proto", "service", "open_hosts"})
+    for _, k := range keys {
+        n := len(s.hosts[k])
+        if s.epsilon > 0 {
+            n = int(math.Max(0, math.Round(float64(n)+laplace(1/s.epsilon))))
+        }
+        if n < s.min || n == 0 {
+            continue
+        }
+        w.Write([]string{k.network, strconv.Itoa(k.port), k.proto, k.service, strconv.Itoa(n)})
+    }
+    w.Flush()
+    if err := w.Error(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
@@ -2,38 +2,192 @@
 package writer
 
 import (
-    "encoding/csv"
+    "fmt"
+    "io"
     "os"
     "sync"
-    "time"
+    "sync/atomic"
 
+    "goscant/filter"
     "goscant/internal/scanner"
+    "goscant/internal/seal"
 )
 
+// CSVWriter fans results out to the primary output file and any mirrors. A
+// failing sink is quarantined rather than stopping the scan; see sinkState.
 type CSVWriter struct {
-    mu     sync.Mutex
-    f      *os.File
-    w      *csv.Writer
-    ch     chan scanner.Result
+    ch      chan scanner.Result
+    done    chan struct{}
+    meta    []string
+    fmt     string
+    addrFmt string
+    keep    []filter.ResultFilter
+    sinks   []*sinkState
+    key     []byte   // seals output files when set
+    info    ScanInfo // heads json, gnmap, sqlite and xml output
+    mu      sync.RWMutex
+    closed  bool // set by Close; later submissions are dropped
+    stats   runStats
+
+    written int64
+}
+
+// Formats lists the accepted output formats.
+var Formats = []string{"csv", "jsonl", "json", "ndjson", "gnmap", "sqlite", "xml"}
+
+// AllFormats are the formats written side by side by --output-all, each to
+// the base name plus the format as extension.
+var AllFormats = []string{"csv", "json", "xml"}
+
+// Stdout is the output path that streams to standard output.
+const Stdout = "-"
+
+// New creates the CSV output. meta names extra columns taken from Result.Meta;
+// results rejected by any of filters are not written.
+func New(path string, meta []string, filters ...filter.ResultFilter) (*CSVWriter, error) {
+    return NewFormat(path, "csv", meta, filters...)
+}
+
+// NewFormat is New for any of Formats. An empty path opens no file.
+func NewFormat(path, format string, meta []string, filters ...filter.ResultFilter) (*CSVWriter, error) {
+    c := &CSVWriter{ch: make(chan scanner.Result, 1024), done: make(chan struct{}), meta: meta, fmt: format, keep: filters}
+    if path == "" {
+        return c, nil // destinations are added separately, e.g. AddPartitioned
+    }
+    if err := c.AddMirror(path); err != nil { return nil, err }
+    return c, nil
+}
+
+// Encrypt seals files opened after it with key (see package seal).
+func (c *CSVWriter) Encrypt(key []byte) { c.key = key }
+
+// SetScanInfo sets the scan description written by the json, gnmap,
+// sqlite and xml sinks opened after it.
+func (c *CSVWriter) SetScanInfo(info ScanInfo) { c.info = info }
+
+// AddMirror writes another copy of the output to path. Call before Run.
+func (c *CSVWriter) AddMirror(path string) error {
+    return c.AddMirrorFormat(path, c.fmt)
+}
+
+// AddMirrorFormat is AddMirror writing format rather than the writer's own.
+func (c *CSVWriter) AddMirrorFormat(path, format string) error {
+    s, err := openSink(path, format, c.meta, c.info, false, c.key)
+    if err != nil { return err }
+    c.AddSink(path, s)
+    return nil
+}
+
+// openSink opens path in format, truncating it unless appendTo is set.
+// info heads json, gnmap, sqlite and xml output.
+func openSink(path, format string, meta []string, info ScanInfo, appendTo bool, key []byte) (Sink, error) {
+    switch format {
+    case "csv":
+        return openCSVSink(path, meta, appendTo, key)
+    case "jsonl", "ndjson":
+        return openJSONLSink(path, meta, appendTo, key)
+    case "json":
+        return openJSONSink(path, meta, info, appendTo, key)
+    case "gnmap":
+        return openGnmapSink(path, info, appendTo, key)
+    case "xml":
+        return openXMLSink(path, info, appendTo, key)
+    case "sqlite":
+        return openSQLiteSink(path, meta, info, appendTo, key)
+    }
+    return nil, fmt.Errorf("unknown output format %q", format)
 }
 
-func New(path string) (*CSVWriter, error) {
-    f, err := os.Create(path)
-    if err != nil { return nil, err }
-    w := csv.NewWriter(f)
-    w.Write([]string{"timestamp", "dst_ip", "dst_port", "status", "latency_ms"})
-    return &CSVWriter{f: f, w: w, ch: make(chan scanner.Result, 1024)}, nil
+// openFile creates path, or opens it for append if appendTo is set. The
+// file is sealed if key is not nil. Stdout is never sealed or closed.
+// Sinks call it when they are added, even those written only when the
+// scan ends, so a bad path fails before any probing rather than after.
+func openFile(path string, appendTo bool, key []byte) (io.WriteCloser, error) {
+    if path == Stdout {
+        return nopCloser{os.Stdout}, nil
+    }
+    return seal.Create(path, key, appendTo)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// AddSink registers another destination under name. Call before Run.
+func (c *CSVWriter) AddSink(name string, s Sink) {
+    c.sinks = append(c.sinks, &sinkState{name: name, sink: s})
 }
 
 func (c *CSVWriter) Run() {
+    defer close(c.done)
     for r := range c.ch {
-        c.mu.Lock()
-        c.w.Write([]string{time.Now().Format(time.RFC3339), r.IP, strconv.Itoa(r.Port), r.Status.String(), strconv.FormatInt(r.LatencyMS, 10)})
-        c.w.Flush()
-        c.mu.Unlock()
+        r.Confidence = scanner.Rate(r)
+        c.stats.add(r)
+        if !filter.Chain(r, c.keep) {
+            continue
+        }
+        r.IP = formatAddr(r.IP, c.addrFmt)
+        for _, s := range c.sinks {
+            s.write(r)
+        }
+        atomic.AddInt64(&c.written, 1)
+    }
+}
+
+// Extra columns carrying Result fields rather than Result.Meta entries.
+const (
+    BannerColumn  = "banner"
+    ServiceColumn = "service"
+)
+
+// column returns the value of extra column k: a Result field for the
+// names it reserves, Result.Meta[k] otherwise.
+func column(r scanner.Result, k string) string {
+    switch k {
+    case BannerColumn:
+        return r.Banner
+    case ServiceColumn:
+        return r.Service
     }
+    return r.Meta[k]
 }
 
-func (c *CSVWriter) Submit(r scanner.Result) { c.ch <- r }
+// Written returns how many rows have been written so far.
+func (c *CSVWriter) Written() int64 { return atomic.LoadInt64(&c.written) }
 
-func (c *CSVWriter) Close() { close(c.ch); c.f.Close() }
\ No newline at end of file
+// Submit queues r for writing. Results submitted after Close, e.g. by a
+// worker abandoned during shutdown, are dropped.
+func (c *CSVWriter) Submit(r scanner.Result) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    if !c.closed {
+        c.ch <- r
+    }
+}
+
+// Close drains pending results, makes a last attempt to flush quarantined
+// sinks and closes them all.
+func (c *CSVWriter) Close() {
+    c.mu.Lock()
+    c.closed = true
+    close(c.ch)
+    c.mu.Unlock()
+    <-c.done
+    for _, s := range c.sinks {
+        if s.err != nil {
+            s.retry(true)
+        }
+        s.closeErr = s.sink.Close()
+    }
+}
+
+// Summary reports sinks that failed during the run and any rows at risk.
+func (c *CSVWriter) Summary() []string {
+    lines := []string{}
+    for _, s := range c.sinks {
+        if line := s.summary(); line != "" {
+            lines = append(lines, line)
+        }
+    }
+    return lines
+}
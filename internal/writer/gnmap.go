@@ -0,0 +1,108 @@
+// File: internal/writer/gnmap.go
+package writer
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// gnmapHost collects one host's results for the gnmap format.
+type gnmapHost struct {
+    ip, name string
+    down     bool
+    ports    []scanner.Result
+}
+
+// gnmapSink writes nmap's grepable layout, one line per host:
+//
+//	Host: 10.0.0.5 (web1)	Ports: 22/open/tcp//ssh//OpenSSH 8.9/, 443/filtered/tcp/////
+//
+// Hosts interleave during a scan, so lines are written when it ends, in
+// the order their first result arrived.
+type gnmapSink struct {
+    f     io.WriteCloser
+    info  ScanInfo
+    hosts map[string]*gnmapHost
+    order []*gnmapHost
+}
+
+func openGnmapSink(path string, info ScanInfo, appendTo bool, key []byte) (*gnmapSink, error) {
+    if appendTo {
+        return nil, errors.New("gnmap output is written whole when the scan ends and cannot be appended to; use jsonl")
+    }
+    f, err := openFile(path, false, key)
+    if err != nil { return nil, err }
+    return &gnmapSink{f: f, info: info, hosts: map[string]*gnmapHost{}}, nil
+}
+
+func (s *gnmapSink) Write(r scanner.Result) error {
+    h := s.hosts[r.IP]
+    if h == nil {
+        h = &gnmapHost{ip: r.IP}
+        s.hosts[r.IP] = h
+        s.order = append(s.order, h)
+    }
+    if h.name == "" {
+        h.name = r.Meta["hostname"]
+    }
+    if r.Status == scanner.HostDown {
+        h.down = true
+        return nil
+    }
+    h.ports = append(h.ports, r)
+    return nil
+}
+
+// Close writes the header, one line per host and the trailer.
+func (s *gnmapSink) Close() error {
+    w := bufio.NewWriter(s.f)
+    fmt.Fprintf(w, "# goscant %s scan initiated %s as: goscant %s\n",
+        s.info.ScanType, s.info.Started.Format(time.ANSIC), strings.Join(s.info.Command, " "))
+    for _, h := range s.order {
+        if len(h.ports) == 0 {
+            status := "Up"
+            if h.down {
+                status = "Down"
+            }
+            fmt.Fprintf(w, "Host: %s (%s)\tStatus: %s\n", h.ip, gnmapField(h.name), status)
+            continue
+        }
+        sort.SliceStable(h.ports, func(i, j int) bool {
+            if h.ports[i].Port != h.ports[j].Port {
+                return h.ports[i].Port < h.ports[j].Port
+            }
+            return h.ports[i].Proto < h.ports[j].Proto
+        })
+        ports := make([]string, len(h.ports))
+        for i, r := range h.ports {
+            ports[i] = fmt.Sprintf("%d/%s/%s//%s//%s/", r.Port, strings.ToLower(r.Status.String()), r.Proto, gnmapField(r.Service), gnmapField(r.Meta["version"]))
+        }
+        fmt.Fprintf(w, "Host: %s (%s)\tPorts: %s\n", h.ip, gnmapField(h.name), strings.Join(ports, ", "))
+    }
+    fmt.Fprintf(w, "# goscant done at %s -- %d hosts\n", time.Now().Format(time.ANSIC), len(s.order))
+    if err := w.Flush(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
+
+// gnmapField keeps a value from breaking the line or its port entry, the
+// way nmap does: "/" becomes "|" and separators become spaces.
+func gnmapField(v string) string {
+    v = strings.ReplaceAll(v, "/", "|")
+    return strings.Map(func(c rune) rune {
+        switch c {
+        case ',', '\t', '\n', '\r':
+            return ' '
+        }
+        return c
+    }, v)
+}
@@ -0,0 +1,104 @@
+// File: internal/writer/inventory.go
+package writer
+
+import (
+    "encoding/csv"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+
+    "goscant/internal/scanner"
+)
+
+const (
+    inventoryExamples   = 5  // example hosts listed per row
+    inventoryVersionMax = 80 // banner characters used when no version is known
+)
+
+type inventoryKey struct {
+    service, version, proto string
+    port                    int
+}
+
+// inventorySink aggregates open results into unique (service, version,
+// port) rows with host counts, written when the scan ends.
+type inventorySink struct {
+    f     io.WriteCloser
+    hosts map[inventoryKey]map[string]bool
+}
+
+func openInventorySink(path string, key []byte) (*inventorySink, error) {
+    f, err := openFile(path, false, key)
+    if err != nil { return nil, err }
+    return &inventorySink{f: f, hosts: map[inventoryKey]map[string]bool{}}, nil
+}
+
+// AddInventory also writes a service inventory to path. Call before Run.
+func (c *CSVWriter) AddInventory(path string) error {
+    s, err := openInventorySink(path, c.key)
+    if err != nil { return err }
+    c.AddSink(path, s)
+    return nil
+}
+
+func (s *inventorySink) Write(r scanner.Result) error {
+    if r.Status != scanner.Open {
+        return nil
+    }
+    k := inventoryKey{service: r.Service, version: r.Meta["version"], proto: r.Proto, port: r.Port}
+    if k.service == "" {
+        k.service = "unknown"
+    }
+    if k.version == "" && r.Banner != "" {
+        k.version = r.Banner
+        if len(k.version) > inventoryVersionMax {
+            k.version = k.version[:inventoryVersionMax]
+        }
+    }
+    if s.hosts[k] == nil {
+        s.hosts[k] = map[string]bool{}
+    }
+    s.hosts[k][r.IP] = true
+    return nil
+}
+
+// Close writes one row per tuple, most widespread first.
+func (s *inventorySink) Close() error {
+    keys := make([]inventoryKey, 0, len(s.hosts))
+    for k := range s.hosts {
+        keys = append(keys, k)
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        a, b := keys[i], keys[j]
+        if len(s.hosts[a]) != len(s.hosts[b]) {
+            return len(s.hosts[a]) > len(s.hosts[b])
+        }
+        if a.service != b.service {
+            return a.service < b.service
+        }
+        if a.port != b.port {
+            return a.port < b.port
+        }
+        return a.version < b.version
+    })
+    w := csv.NewWriter(s.f)
+    w.Write([]string{"service", "version", "port", "proto", "hosts", "example_hosts"})
+    for _, k := range keys {
+        ips := make([]string, 0, len(s.hosts[k]))
+        for ip := range s.hosts[k] {
+            ips = append(ips, ip)
+        }
+        sort.Strings(ips)
+        if len(ips) > inventoryExamples {
+            ips = ips[:inventoryExamples]
+        }
+        w.Write([]string{k.service, k.version, strconv.Itoa(k.port), k.proto, strconv.Itoa(len(s.hosts[k])), strings.Join(ips, " ")})
+    }
+    w.Flush()
+    if err := w.Error(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
@@ -0,0 +1,95 @@
+// File: internal/writer/jsondoc.go
+package writer
+
+import (
+    "bufio"
+    "encoding/json"
+    "errors"
+    "io"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// ScanInfo describes the scan at the head of a json document or gnmap
+// file, and in the scans table of a sqlite database.
+type ScanInfo struct {
+    Started  time.Time `json:"started"`
+    Command  []string  `json:"command"`
+    ScanType string    `json:"scan_type"`
+    Targets  int       `json:"targets"`
+}
+
+// DocSummary closes a json document.
+type DocSummary struct {
+    Finished time.Time `json:"finished"`
+    Results  int       `json:"results"`
+}
+
+// JSONDoc is the layout of the json output format:
+//
+//	{"scan": {...}, "results": [{...}, ...], "summary": {...}}
+//
+// Rows are JSONRow, as in jsonl. The document is streamed, so a run that
+// dies before Close leaves it without its closing summary.
+type JSONDoc struct {
+    Scan    ScanInfo    `json:"scan"`
+    Results []JSONRow   `json:"results"`
+    Summary *DocSummary `json:"summary,omitempty"`
+}
+
+// jsonSink writes a JSONDoc.
+type jsonSink struct {
+    f    io.WriteCloser
+    w    *bufio.Writer
+    meta []string
+    n    int
+}
+
+func openJSONSink(path string, meta []string, info ScanInfo, appendTo bool, key []byte) (*jsonSink, error) {
+    if appendTo {
+        return nil, errors.New("json output is one document and cannot be appended to; use jsonl")
+    }
+    f, err := openFile(path, false, key)
+    if err != nil { return nil, err }
+    head, err := json.Marshal(info)
+    if err != nil {
+        f.Close()
+        return nil, err
+    }
+    s := &jsonSink{f: f, w: bufio.NewWriter(f), meta: meta}
+    s.w.WriteString(`{"scan":`)
+    s.w.Write(head)
+    s.w.WriteString(`,"results":[`)
+    if err := s.w.Flush(); err != nil {
+        f.Close()
+        return nil, err
+    }
+    return s, nil
+}
+
+func (s *jsonSink) Write(r scanner.Result) error {
+    b, err := json.Marshal(jsonRow(r, s.meta))
+    if err != nil {
+        return err
+    }
+    if s.n > 0 {
+        s.w.WriteByte(',')
+    }
+    s.w.WriteString("\n")
+    s.w.Write(b)
+    s.n++
+    return s.w.Flush()
+}
+
+func (s *jsonSink) Close() error {
+    tail, _ := json.Marshal(DocSummary{Finished: time.Now(), Results: s.n})
+    s.w.WriteString("\n],\"summary\":")
+    s.w.Write(tail)
+    s.w.WriteString("}\n")
+    if err := s.w.Flush(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
@@ -0,0 +1,79 @@
+// File: internal/writer/jsonl.go
+package writer
+
+import (
+    "bufio"
+    "encoding/json"
+    "io"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// JSONRow is one result as written by the jsonl sink.
+type JSONRow struct {
+    Timestamp  time.Time         `json:"timestamp"`
+    DstIP      string            `json:"dst_ip"`
+    AddrFamily string            `json:"addr_family"`
+    DstPort    int               `json:"dst_port"`
+    Proto      string            `json:"proto"`
+    Status     string            `json:"status"`
+    LatencyMS  int64             `json:"latency_ms"`
+    Attempts   int               `json:"attempts"`
+    Confidence string            `json:"confidence,omitempty"`
+    Engine     string            `json:"engine,omitempty"`
+    Banner     string            `json:"banner,omitempty"`
+    Service    string            `json:"service,omitempty"`
+    Meta       map[string]string `json:"meta,omitempty"`
+}
+
+// jsonlSink writes one JSON object per line, flushed as each result
+// arrives, for both the jsonl and ndjson formats.
+type jsonlSink struct {
+    f    io.WriteCloser
+    w    *bufio.Writer
+    meta []string
+}
+
+func openJSONLSink(path string, meta []string, appendTo bool, key []byte) (*jsonlSink, error) {
+    f, err := openFile(path, appendTo, key)
+    if err != nil { return nil, err }
+    return &jsonlSink{f: f, w: bufio.NewWriter(f), meta: meta}, nil
+}
+
+func (s *jsonlSink) Write(r scanner.Result) error {
+    b, err := json.Marshal(jsonRow(r, s.meta))
+    if err != nil {
+        return err
+    }
+    s.w.Write(append(b, '\n'))
+    return s.w.Flush()
+}
+
+// jsonRow converts r, keeping the meta entries named by meta.
+func jsonRow(r scanner.Result, meta []string) JSONRow {
+    ts := r.Time
+    if ts.IsZero() {
+        ts = time.Now()
+    }
+    row := JSONRow{Timestamp: ts, DstIP: r.IP, AddrFamily: r.Family(), DstPort: r.Port, Proto: r.Proto,
+        Status: r.Status.String(), LatencyMS: r.LatencyMS, Attempts: r.Attempts, Confidence: r.Confidence.String(), Engine: r.Engine, Banner: r.Banner, Service: r.Service}
+    for _, k := range meta {
+        if k == BannerColumn || k == ServiceColumn {
+            continue
+        }
+        if row.Meta == nil {
+            row.Meta = map[string]string{}
+        }
+        row.Meta[k] = r.Meta[k]
+    }
+    return row
+}
+
+func (s *jsonlSink) Close() error {
+    if err := s.w.Flush(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
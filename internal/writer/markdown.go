@@ -0,0 +1,197 @@
+// File: internal/writer/markdown.go
+package writer
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+const (
+    markdownHosts    = 500 // host rows before the table is cut short
+    markdownServices = 20  // rows in the top services table
+    markdownErrors   = 10  // rows in the errors table
+)
+
+// markdownHost collects one host's open ports for the report.
+type markdownHost struct {
+    ip, name string
+    open     []scanner.Result
+}
+
+// markdownSink summarizes the scan as Markdown for tickets and wikis:
+// status counts, hosts with open ports, top services and errors. It is
+// written when the scan ends.
+type markdownSink struct {
+    f        io.WriteCloser
+    info     ScanInfo
+    statuses map[scanner.Status]int
+    hosts    map[string]*markdownHost
+    order    []*markdownHost
+    services map[inventoryKey]map[string]bool
+    errors   map[string]int
+}
+
+func openMarkdownSink(path string, info ScanInfo, key []byte) (*markdownSink, error) {
+    f, err := openFile(path, false, key)
+    if err != nil { return nil, err }
+    return &markdownSink{f: f, info: info, statuses: map[scanner.Status]int{}, hosts: map[string]*markdownHost{},
+        services: map[inventoryKey]map[string]bool{}, errors: map[string]int{}}, nil
+}
+
+// AddMarkdown also writes a Markdown summary of the scan to path. Call
+// before Run.
+func (c *CSVWriter) AddMarkdown(path string) error {
+    s, err := openMarkdownSink(path, c.info, c.key)
+    if err != nil { return err }
+    c.AddSink(path, s)
+    return nil
+}
+
+func (s *markdownSink) Write(r scanner.Result) error {
+    s.statuses[r.Status]++
+    switch r.Status {
+    case scanner.Error:
+        s.errors[errorKind(r.Err)]++
+    case scanner.Open:
+        h := s.hosts[r.IP]
+        if h == nil {
+            h = &markdownHost{ip: r.IP}
+            s.hosts[r.IP] = h
+            s.order = append(s.order, h)
+        }
+        if h.name == "" {
+            h.name = r.Meta["hostname"]
+        }
+        h.open = append(h.open, r)
+        k := inventoryKey{service: r.Service, proto: r.Proto, port: r.Port}
+        if k.service == "" {
+            k.service = "unknown"
+        }
+        if s.services[k] == nil {
+            s.services[k] = map[string]bool{}
+        }
+        s.services[k][r.IP] = true
+    }
+    return nil
+}
+
+// errorKind reduces an error to the part shared across targets, e.g.
+// "connection refused" from "dial tcp 10.0.0.1:80: connect: connection refused".
+func errorKind(err error) string {
+    if err == nil {
+        return "unknown"
+    }
+    msg := err.Error()
+    if i := strings.LastIndex(msg, ": "); i >= 0 {
+        msg = msg[i+2:]
+    }
+    return msg
+}
+
+// mdCell keeps a value from breaking a table row.
+func mdCell(v string) string {
+    v = strings.ReplaceAll(v, "|", `\|`)
+    return strings.NewReplacer("\n", " ", "\r", " ").Replace(v)
+}
+
+// Close writes the report.
+func (s *markdownSink) Close() error {
+    w := bufio.NewWriter(s.f)
+    fmt.Fprintf(w, "# goscant scan report\n\n")
+    if !s.info.Started.IsZero() {
+        fmt.Fprintf(w, "- Command: `goscant %s`\n", strings.Join(s.info.Command, " "))
+        fmt.Fprintf(w, "- Scan type: %s, %d targets\n", s.info.ScanType, s.info.Targets)
+        fmt.Fprintf(w, "- Started: %s\n", s.info.Started.Format(time.RFC3339))
+    }
+    fmt.Fprintf(w, "- Finished: %s\n", time.Now().Format(time.RFC3339))
+    fmt.Fprintf(w, "- Hosts with open ports: %d\n", len(s.order))
+
+    fmt.Fprintf(w, "\n## Results\n\n| Status | Count |\n|---|---:|\n")
+    for _, st := range []scanner.Status{scanner.Open, scanner.Closed, scanner.Filtered, scanner.Error, scanner.HostDown} {
+        if n := s.statuses[st]; n > 0 {
+            fmt.Fprintf(w, "| %s | %d |\n", st, n)
+        }
+    }
+
+    fmt.Fprintf(w, "\n## Open ports\n\n")
+    if len(s.order) == 0 {
+        fmt.Fprintf(w, "No open ports found.\n")
+    } else {
+        fmt.Fprintf(w, "| Host | Open ports |\n|---|---|\n")
+        for i, h := range s.order {
+            if i == markdownHosts {
+                fmt.Fprintf(w, "\n…and %d more hosts.\n", len(s.order)-markdownHosts)
+                break
+            }
+            sort.SliceStable(h.open, func(a, b int) bool { return h.open[a].Port < h.open[b].Port })
+            ports := make([]string, len(h.open))
+            for k, r := range h.open {
+                ports[k] = strconv.Itoa(r.Port) + "/" + r.Proto
+                if r.Service != "" {
+                    ports[k] += " " + r.Service
+                }
+            }
+            host := h.ip
+            if h.name != "" {
+                host += " (" + h.name + ")"
+            }
+            fmt.Fprintf(w, "| %s | %s |\n", mdCell(host), mdCell(strings.Join(ports, ", ")))
+        }
+    }
+
+    if len(s.services) > 0 {
+        keys := make([]inventoryKey, 0, len(s.services))
+        for k := range s.services {
+            keys = append(keys, k)
+        }
+        sort.Slice(keys, func(i, j int) bool {
+            a, b := keys[i], keys[j]
+            if len(s.services[a]) != len(s.services[b]) {
+                return len(s.services[a]) > len(s.services[b])
+            }
+            if a.port != b.port {
+                return a.port < b.port
+            }
+            return a.service < b.service
+        })
+        fmt.Fprintf(w, "\n## Top services\n\n| Service | Port | Hosts |\n|---|---|---:|\n")
+        for i, k := range keys {
+            if i == markdownServices {
+                break
+            }
+            fmt.Fprintf(w, "| %s | %d/%s | %d |\n", mdCell(k.service), k.port, k.proto, len(s.services[k]))
+        }
+    }
+
+    if len(s.errors) > 0 {
+        kinds := make([]string, 0, len(s.errors))
+        for k := range s.errors {
+            kinds = append(kinds, k)
+        }
+        sort.Slice(kinds, func(i, j int) bool {
+            if s.errors[kinds[i]] != s.errors[kinds[j]] {
+                return s.errors[kinds[i]] > s.errors[kinds[j]]
+            }
+            return kinds[i] < kinds[j]
+        })
+        fmt.Fprintf(w, "\n## Errors\n\n| Error | Count |\n|---|---:|\n")
+        for i, k := range kinds {
+            if i == markdownErrors {
+                break
+            }
+            fmt.Fprintf(w, "| %s | %d |\n", mdCell(k), s.errors[k])
+        }
+    }
+    if err := w.Flush(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
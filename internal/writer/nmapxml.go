@@ -0,0 +1,152 @@
+// File: internal/writer/nmapxml.go
+package writer
+
+import (
+    "encoding/xml"
+    "errors"
+    "io"
+    "sort"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// The subset of nmap's XML report (-oX) written by the xml format: enough
+// for ndiff, report converters and goscant's own --ip reader.
+type (
+    xmlRun struct {
+        XMLName  xml.Name    `xml:"nmaprun"`
+        Scanner  string      `xml:"scanner,attr"`
+        Args     string      `xml:"args,attr"`
+        Start    int64       `xml:"start,attr"`
+        StartStr string      `xml:"startstr,attr"`
+        Info     xmlScanInfo `xml:"scaninfo"`
+        Hosts    []*xmlHost  `xml:"host"`
+        Stats    xmlStats    `xml:"runstats"`
+    }
+    xmlScanInfo struct {
+        Type     string `xml:"type,attr"`
+        Protocol string `xml:"protocol,attr"`
+    }
+    xmlHost struct {
+        Status    xmlState   `xml:"status"`
+        Address   xmlAddress `xml:"address"`
+        Hostnames []xmlName  `xml:"hostnames>hostname,omitempty"`
+        Ports     []xmlPort  `xml:"ports>port,omitempty"`
+    }
+    xmlState struct {
+        State string `xml:"state,attr"`
+    }
+    xmlAddress struct {
+        Addr string `xml:"addr,attr"`
+        Type string `xml:"addrtype,attr"`
+    }
+    xmlName struct {
+        Name string `xml:"name,attr"`
+        Type string `xml:"type,attr"`
+    }
+    xmlPort struct {
+        Proto   string      `xml:"protocol,attr"`
+        Num     int         `xml:"portid,attr"`
+        State   xmlState    `xml:"state"`
+        Service *xmlService `xml:"service,omitempty"`
+    }
+    xmlService struct {
+        Name    string `xml:"name,attr"`
+        Product string `xml:"product,attr,omitempty"`
+    }
+    xmlStats struct {
+        Finished struct {
+            Time    int64  `xml:"time,attr"`
+            TimeStr string `xml:"timestr,attr"`
+        } `xml:"finished"`
+        Hosts struct {
+            Up    int `xml:"up,attr"`
+            Down  int `xml:"down,attr"`
+            Total int `xml:"total,attr"`
+        } `xml:"hosts"`
+    }
+)
+
+// xmlSink writes an nmap XML report. Like gnmap it groups results per
+// host, so the report is written when the scan ends. ERROR rows have no
+// nmap port state and are left out.
+type xmlSink struct {
+    f     io.WriteCloser
+    run   xmlRun
+    hosts map[string]*xmlHost
+}
+
+func openXMLSink(path string, info ScanInfo, appendTo bool, key []byte) (*xmlSink, error) {
+    if appendTo {
+        return nil, errors.New("xml output is one document and cannot be appended to; use jsonl")
+    }
+    f, err := openFile(path, false, key)
+    if err != nil { return nil, err }
+    run := xmlRun{Scanner: "goscant", Args: strings.Join(append([]string{"goscant"}, info.Command...), " "),
+        Start: info.Started.Unix(), StartStr: info.Started.Format(time.ANSIC), Info: xmlScanInfo{Type: info.ScanType, Protocol: "tcp"}}
+    if info.ScanType == "sctp" {
+        run.Info.Protocol = "sctp"
+    }
+    return &xmlSink{f: f, run: run, hosts: map[string]*xmlHost{}}, nil
+}
+
+func (s *xmlSink) Write(r scanner.Result) error {
+    h := s.hosts[r.IP]
+    if h == nil {
+        h = &xmlHost{Status: xmlState{"up"}, Address: xmlAddress{Addr: r.IP, Type: r.Family()}}
+        s.hosts[r.IP] = h
+        s.run.Hosts = append(s.run.Hosts, h)
+    }
+    if name := r.Meta["hostname"]; name != "" && len(h.Hostnames) == 0 {
+        h.Hostnames = []xmlName{{Name: name, Type: "PTR"}}
+    }
+    switch r.Status {
+    case scanner.HostDown:
+        h.Status.State = "down"
+        return nil
+    case scanner.Error:
+        return nil
+    }
+    p := xmlPort{Proto: r.Proto, Num: r.Port, State: xmlState{strings.ToLower(r.Status.String())}}
+    if r.Service != "" {
+        p.Service = &xmlService{Name: r.Service, Product: r.Meta["version"]}
+    }
+    h.Ports = append(h.Ports, p)
+    return nil
+}
+
+// Close sorts each host's ports, fills in the run statistics and writes
+// the report.
+func (s *xmlSink) Close() error {
+    now := time.Now()
+    s.run.Stats.Finished.Time = now.Unix()
+    s.run.Stats.Finished.TimeStr = now.Format(time.ANSIC)
+    for _, h := range s.run.Hosts {
+        sort.SliceStable(h.Ports, func(i, j int) bool {
+            if h.Ports[i].Num != h.Ports[j].Num {
+                return h.Ports[i].Num < h.Ports[j].Num
+            }
+            return h.Ports[i].Proto < h.Ports[j].Proto
+        })
+        if h.Status.State == "up" {
+            s.run.Stats.Hosts.Up++
+        } else {
+            s.run.Stats.Hosts.Down++
+        }
+    }
+    s.run.Stats.Hosts.Total = len(s.run.Hosts)
+    io.WriteString(s.f, xml.Header)
+    enc := xml.NewEncoder(s.f)
+    enc.Indent("", " ")
+    err := enc.Encode(s.run)
+    if err == nil {
+        _, err = io.WriteString(s.f, "\n")
+    }
+    if err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
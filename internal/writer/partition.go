@@ -0,0 +1,131 @@
+// File: internal/writer/partition.go
+package writer
+
+import (
+    "fmt"
+    "net"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// Partitions lists the accepted --partition-by dimensions.
+var Partitions = []string{"host", "network", "status", "engine", "day"}
+
+// partitionOpenMax bounds the partition files held open at once; the least
+// recently written is closed (and later reopened for append) beyond it.
+const partitionOpenMax = 128
+
+// PartitionDir is the directory partitions of output are written under:
+// the output path without its extension.
+func PartitionDir(output string) string {
+    return strings.TrimSuffix(output, filepath.Ext(output))
+}
+
+// partitionSink routes each row to dir/<key>/results.<format>, where key
+// is the row's value of the partition dimension.
+type partitionSink struct {
+    dir, by, format string
+    meta            []string
+    key             []byte
+    open            map[string]*partition
+    seen            map[string]bool // partitions created during this run
+    tick            int64
+}
+
+type partition struct {
+    sink    Sink
+    lastUse int64
+}
+
+// AddPartitioned writes rows into per-partition files under dir instead of
+// a single file. Call before Run.
+func (c *CSVWriter) AddPartitioned(dir, by string) error {
+    if !slices.Contains(Partitions, by) {
+        return fmt.Errorf("unknown partition %q (want %s)", by, strings.Join(Partitions, ", "))
+    }
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return err
+    }
+    c.AddSink(dir, &partitionSink{dir: dir, by: by, format: c.fmt, meta: c.meta, key: c.key, open: map[string]*partition{}, seen: map[string]bool{}})
+    return nil
+}
+
+// partitionKey names r's partition; it is safe to use as a directory name.
+func partitionKey(r scanner.Result, by string) string {
+    ip := net.ParseIP(ParseAddr(r.IP))
+    switch by {
+    case "host":
+        if ip == nil {
+            return "unknown"
+        }
+        return strings.ReplaceAll(ip.String(), ":", "_")
+    case "network":
+        if ip == nil {
+            return "unknown"
+        }
+        return strings.NewReplacer(":", "_", "/", "_").Replace(networkOf(ip).String())
+    case "status":
+        return strings.ToLower(r.Status.String())
+    case "engine":
+        if r.Engine == "" {
+            return "none" // not probed, e.g. fast-failed or skipped
+        }
+        return r.Engine
+    }
+    ts := r.Time
+    if ts.IsZero() {
+        ts = time.Now()
+    }
+    return ts.UTC().Format("2006-01-02")
+}
+
+func (s *partitionSink) Write(r scanner.Result) error {
+    key := partitionKey(r, s.by)
+    p, ok := s.open[key]
+    if !ok {
+        if len(s.open) >= partitionOpenMax {
+            s.evict()
+        }
+        path := filepath.Join(s.dir, key, "results."+s.format)
+        if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+            return err
+        }
+        sink, err := openSink(path, s.format, s.meta, ScanInfo{}, s.seen[key], s.key)
+        if err != nil {
+            return err
+        }
+        p = &partition{sink: sink}
+        s.open[key], s.seen[key] = p, true
+    }
+    s.tick++
+    p.lastUse = s.tick
+    return p.sink.Write(r)
+}
+
+// evict closes the least recently written partition.
+func (s *partitionSink) evict() {
+    oldest := ""
+    for k, p := range s.open {
+        if oldest == "" || p.lastUse < s.open[oldest].lastUse {
+            oldest = k
+        }
+    }
+    s.open[oldest].sink.Close()
+    delete(s.open, oldest)
+}
+
+func (s *partitionSink) Close() error {
+    var first error
+    for k, p := range s.open {
+        if err := p.sink.Close(); err != nil && first == nil {
+            first = err
+        }
+        delete(s.open, k)
+    }
+    return first
+}
@@ -0,0 +1,120 @@
+// File: internal/writer/rotate.go
+package writer
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "goscant/internal/scanner"
+)
+
+// RotateFormats lists the formats that can be rotated: those written row by
+// row rather than when the scan ends.
+var RotateFormats = []string{"csv", "jsonl", "ndjson", "json"}
+
+// RotatedPath is chunk n of a rotated output at path: results.csv becomes
+// results-0001.csv, results-0002.csv, ...
+func RotatedPath(path string, n int) string {
+    ext := filepath.Ext(path)
+    return fmt.Sprintf("%s-%04d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// RotatedChunks lists the chunks of a rotated output at path, in order.
+func RotatedChunks(path string) []string {
+    ext := filepath.Ext(path)
+    pattern := strings.TrimSuffix(path, ext) + "-[0-9][0-9][0-9][0-9]*" + ext
+    chunks, _ := filepath.Glob(pattern)
+    sort.Slice(chunks, func(i, j int) bool {
+        if len(chunks[i]) != len(chunks[j]) {
+            return len(chunks[i]) < len(chunks[j]) // -10000 after -9999
+        }
+        return chunks[i] < chunks[j]
+    })
+    return chunks
+}
+
+// rotatingSink writes numbered chunks of path, starting the next once the
+// current one holds maxRows rows or maxBytes bytes (0 = no limit). A chunk
+// is closed before its successor is created, so every chunk but the newest
+// is complete and can be loaded while the scan goes on.
+type rotatingSink struct {
+    path, format      string
+    meta              []string
+    info              ScanInfo
+    key               []byte
+    maxRows, maxBytes int64
+    sink              Sink
+    chunk             int
+    rows              int64
+}
+
+// AddRotating writes the output to numbered chunks of path instead of a
+// single file; chunks left by an earlier run are removed. Call before Run.
+func (c *CSVWriter) AddRotating(path string, maxRows, maxBytes int64) error {
+    for _, old := range RotatedChunks(path) {
+        if err := os.Remove(old); err != nil {
+            return err
+        }
+    }
+    s := &rotatingSink{path: path, format: c.fmt, meta: c.meta, info: c.info, key: c.key, maxRows: maxRows, maxBytes: maxBytes}
+    if err := s.next(); err != nil {
+        return err
+    }
+    c.AddSink(path, s)
+    return nil
+}
+
+// next opens the following chunk.
+func (s *rotatingSink) next() error {
+    sink, err := openSink(RotatedPath(s.path, s.chunk+1), s.format, s.meta, s.info, false, s.key)
+    if err != nil {
+        return err
+    }
+    s.sink, s.rows = sink, 0
+    s.chunk++
+    return nil
+}
+
+// full reports whether the current chunk reached a limit.
+func (s *rotatingSink) full() bool {
+    if s.maxRows > 0 && s.rows >= s.maxRows {
+        return true
+    }
+    if s.maxBytes > 0 {
+        st, err := os.Stat(RotatedPath(s.path, s.chunk))
+        return err == nil && st.Size() >= s.maxBytes
+    }
+    return false
+}
+
+// Write rotates before rather than after a row, so a failure to close or
+// open a chunk leaves the row unwritten for the retry.
+func (s *rotatingSink) Write(r scanner.Result) error {
+    if s.sink != nil && s.rows > 0 && s.full() {
+        err := s.sink.Close()
+        s.sink = nil
+        if err != nil {
+            return err
+        }
+    }
+    if s.sink == nil {
+        if err := s.next(); err != nil {
+            return err
+        }
+    }
+    if err := s.sink.Write(r); err != nil {
+        return err
+    }
+    s.rows++
+    return nil
+}
+
+func (s *rotatingSink) Close() error {
+    if s.sink == nil {
+        return nil
+    }
+    return s.sink.Close()
+}
@@ -0,0 +1,143 @@
+// File: internal/writer/sink.go
+package writer
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "time"
+
+    "goscant/internal/scanner"
+)
+
+// Sink is one destination for result rows.
+type Sink interface {
+    Write(r scanner.Result) error
+    Close() error
+}
+
+const (
+    sinkBacklog       = 10000           // rows held per quarantined sink
+    sinkRetryInterval = 5 * time.Second // between attempts to revive a sink
+)
+
+// csvSink writes rows to one CSV file.
+type csvSink struct {
+    f    io.WriteCloser
+    w    *csv.Writer
+    meta []string
+}
+
+func openCSVSink(path string, meta []string, appendTo bool, key []byte) (*csvSink, error) {
+    st, statErr := os.Stat(path)
+    f, err := openFile(path, appendTo, key)
+    if err != nil { return nil, err }
+    w := csv.NewWriter(f)
+    if appendTo && statErr == nil && st.Size() > 0 {
+        return &csvSink{f: f, w: w, meta: meta}, nil // header already written
+    }
+    w.Write(append([]string{"timestamp", "dst_ip", "addr_family", "dst_port", "proto", "status", "latency_ms", "attempts", "confidence", "engine"}, meta...))
+    w.Flush()
+    if err := w.Error(); err != nil {
+        f.Close()
+        return nil, err
+    }
+    return &csvSink{f: f, w: w, meta: meta}, nil
+}
+
+func (s *csvSink) Write(r scanner.Result) error {
+    ts := r.Time
+    if ts.IsZero() {
+        ts = time.Now()
+    }
+    row := []string{ts.Format(time.RFC3339), r.IP, r.Family(), strconv.Itoa(r.Port), r.Proto, r.Status.String(), strconv.FormatInt(r.LatencyMS, 10), strconv.Itoa(r.Attempts), r.Confidence.String(), r.Engine}
+    for _, k := range s.meta {
+        row = append(row, column(r, k))
+    }
+    s.w.Write(row)
+    s.w.Flush()
+    return s.w.Error()
+}
+
+func (s *csvSink) Close() error {
+    s.w.Flush()
+    if err := s.w.Error(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
+
+// sinkState tracks a sink's health. A sink whose write fails is
+// quarantined: its rows queue in a bounded backlog that is replayed once a
+// retry succeeds, and rows beyond the bound are counted as dropped.
+type sinkState struct {
+    name     string
+    sink     Sink
+    err      error // last failure, nil while healthy
+    backlog  []scanner.Result
+    dropped  int64
+    lastTry  time.Time
+    failed   bool  // was quarantined at some point
+    closeErr error // from Close, which may flush buffered rows or a trailer
+}
+
+func (s *sinkState) write(r scanner.Result) {
+    if s.err != nil {
+        s.retry(false)
+    }
+    if s.err == nil {
+        if s.err = s.sink.Write(r); s.err == nil {
+            return
+        }
+        s.failed, s.lastTry = true, time.Now()
+    }
+    s.hold(r)
+}
+
+func (s *sinkState) hold(r scanner.Result) {
+    if len(s.backlog) >= sinkBacklog {
+        s.dropped++
+        return
+    }
+    s.backlog = append(s.backlog, r)
+}
+
+// retry replays the backlog, at most once per sinkRetryInterval unless
+// force is set. The sink leaves quarantine when the backlog drains.
+func (s *sinkState) retry(force bool) {
+    if !force && time.Since(s.lastTry) < sinkRetryInterval {
+        return
+    }
+    s.lastTry = time.Now()
+    for len(s.backlog) > 0 {
+        if s.err = s.sink.Write(s.backlog[0]); s.err != nil {
+            return
+        }
+        s.backlog = s.backlog[1:]
+    }
+    s.err = nil
+}
+
+// summary describes the sink's fate, or "" if it never failed.
+func (s *sinkState) summary() string {
+    if s.closeErr != nil {
+        line := fmt.Sprintf("sink %s: closing failed (%v) - buffered rows or the file's ending may be missing, output is incomplete", s.name, s.closeErr)
+        if len(s.backlog) > 0 || s.dropped > 0 {
+            line += fmt.Sprintf("; %d rows not written, %d dropped", len(s.backlog), s.dropped)
+        }
+        return line
+    }
+    if !s.failed {
+        return ""
+    }
+    if s.err == nil && s.dropped == 0 {
+        return fmt.Sprintf("sink %s: recovered after failures, no rows lost", s.name)
+    }
+    if s.err == nil {
+        return fmt.Sprintf("sink %s: recovered, but %d rows were dropped while quarantined", s.name, s.dropped)
+    }
+    return fmt.Sprintf("sink %s: still failing (%v); %d rows not written, %d dropped - output is incomplete", s.name, s.err, len(s.backlog), s.dropped)
+}
@@ -0,0 +1,220 @@
+// File: internal/writer/sqlite.go
+package writer
+
+import (
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+    "time"
+
+    "goscant/internal/scanner"
+
+    _ "modernc.org/sqlite" // pure Go driver: no cgo in the scanner build
+)
+
+// sqliteBatch is how many rows share a transaction; one per row would
+// cap multi-million-row scans at the disk's fsync rate.
+const sqliteBatch = 1000
+
+// SQLiteSchema creates the tables of the sqlite output format: one scans
+// row per run and its results, indexed for the usual ad-hoc queries.
+const SQLiteSchema = `
+CREATE TABLE IF NOT EXISTS scans (
+    id        INTEGER PRIMARY KEY,
+    started   TEXT NOT NULL,
+    finished  TEXT,
+    command   TEXT NOT NULL,
+    scan_type TEXT NOT NULL,
+    targets   INTEGER NOT NULL,
+    results   INTEGER
+);
+CREATE TABLE IF NOT EXISTS results (
+    scan_id     INTEGER NOT NULL REFERENCES scans(id),
+    timestamp   TEXT NOT NULL,
+    dst_ip      TEXT NOT NULL,
+    addr_family TEXT NOT NULL,
+    dst_port    INTEGER NOT NULL,
+    proto       TEXT NOT NULL,
+    status      TEXT NOT NULL,
+    latency_ms  INTEGER NOT NULL,
+    attempts    INTEGER NOT NULL,
+    confidence  TEXT,
+    engine      TEXT,
+    banner      TEXT,
+    service     TEXT,
+    meta        TEXT
+);
+CREATE INDEX IF NOT EXISTS results_ip ON results(dst_ip);
+CREATE INDEX IF NOT EXISTS results_port ON results(dst_port);
+CREATE INDEX IF NOT EXISTS results_status ON results(status);
+`
+
+const sqliteInsert = `INSERT INTO results (scan_id, timestamp, dst_ip, addr_family, dst_port, proto, status,
+    latency_ms, attempts, confidence, engine, banner, service, meta) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// sqliteSink stores results in a SQLite database. Rows are committed in
+// batches of sqliteBatch, so a crash loses at most the open batch. The
+// batch is kept until it commits: a failed transaction is rolled back and
+// the batch replayed into the next one.
+type sqliteSink struct {
+    db     *sql.DB
+    tx     *sql.Tx // nil between a commit, or a failure, and the next row
+    insert *sql.Stmt
+    meta   []string
+    scanID int64
+    batch  []scanner.Result // rows of the open transaction
+    n      int              // rows committed
+}
+
+func openSQLiteSink(path string, meta []string, info ScanInfo, appendTo bool, key []byte) (*sqliteSink, error) {
+    if key != nil {
+        return nil, errors.New("sqlite output cannot be encrypted; use csv, jsonl or json with --encrypt")
+    }
+    if !appendTo {
+        if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+            return nil, err
+        }
+    }
+    db, err := sql.Open("sqlite", path)
+    if err != nil { return nil, err }
+    db.SetMaxOpenConns(1)
+    s := &sqliteSink{db: db, meta: meta}
+    if err := s.init(info, appendTo); err != nil {
+        db.Close()
+        return nil, err
+    }
+    return s, nil
+}
+
+// init creates the schema and the scans row, or picks up the latest one
+// when a partition file is reopened during the same run.
+func (s *sqliteSink) init(info ScanInfo, appendTo bool) error {
+    if _, err := s.db.Exec(SQLiteSchema); err != nil {
+        return err
+    }
+    if appendTo {
+        var id sql.NullInt64
+        if err := s.db.QueryRow(`SELECT max(id) FROM scans`).Scan(&id); err != nil {
+            return err
+        }
+        if id.Valid {
+            s.scanID = id.Int64
+            if err := s.db.QueryRow(`SELECT coalesce(results, 0) FROM scans WHERE id = ?`, id.Int64).Scan(&s.n); err != nil {
+                return err
+            }
+            return s.begin()
+        }
+    }
+    res, err := s.db.Exec(`INSERT INTO scans (started, command, scan_type, targets) VALUES (?, ?, ?, ?)`,
+        info.Started.UTC().Format(time.RFC3339Nano), strings.Join(info.Command, " "), info.ScanType, info.Targets)
+    if err != nil {
+        return err
+    }
+    if s.scanID, err = res.LastInsertId(); err != nil {
+        return err
+    }
+    return s.begin()
+}
+
+// begin opens a transaction and replays the batch a failed one held.
+func (s *sqliteSink) begin() error {
+    tx, err := s.db.Begin()
+    if err != nil {
+        return err
+    }
+    insert, err := tx.Prepare(sqliteInsert)
+    if err != nil {
+        tx.Rollback()
+        return err
+    }
+    s.tx, s.insert = tx, insert
+    for _, r := range s.batch {
+        if err := s.exec(r); err != nil {
+            s.abort()
+            return err
+        }
+    }
+    return nil
+}
+
+// abort rolls the open transaction back, keeping its batch for the next.
+func (s *sqliteSink) abort() {
+    s.insert.Close()
+    s.tx.Rollback()
+    s.tx, s.insert = nil, nil
+}
+
+// commit ends the open batch, recording the running count on the scan.
+func (s *sqliteSink) commit() error {
+    if _, err := s.tx.Exec(`UPDATE scans SET results = ? WHERE id = ?`, s.n+len(s.batch), s.scanID); err != nil {
+        s.abort()
+        return err
+    }
+    s.insert.Close()
+    if err := s.tx.Commit(); err != nil {
+        // database/sql is done with the transaction, but SQLite keeps one
+        // whose COMMIT failed (e.g. busy) open on the connection
+        s.db.Exec(`ROLLBACK`)
+        s.tx, s.insert = nil, nil
+        return err
+    }
+    s.n += len(s.batch)
+    s.tx, s.insert, s.batch = nil, nil, nil
+    return nil
+}
+
+func (s *sqliteSink) exec(r scanner.Result) error {
+    row := jsonRow(r, s.meta)
+    var meta []byte
+    if len(row.Meta) > 0 {
+        meta, _ = json.Marshal(row.Meta)
+    }
+    _, err := s.insert.Exec(s.scanID, row.Timestamp.UTC().Format(time.RFC3339Nano), row.DstIP, row.AddrFamily, row.DstPort, row.Proto,
+        row.Status, row.LatencyMS, row.Attempts, row.Confidence, row.Engine, row.Banner, row.Service, string(meta))
+    return err
+}
+
+// Write batches r. On an error r is left out of the batch for the
+// caller's backlog to replay; rows batched earlier stay until they commit.
+func (s *sqliteSink) Write(r scanner.Result) error {
+    if s.tx == nil {
+        if err := s.begin(); err != nil {
+            return err
+        }
+    }
+    if err := s.exec(r); err != nil {
+        s.abort()
+        return err
+    }
+    if s.batch = append(s.batch, r); len(s.batch) < sqliteBatch {
+        return nil
+    }
+    if err := s.commit(); err != nil {
+        s.batch = s.batch[:len(s.batch)-1]
+        return err
+    }
+    return nil
+}
+
+// Close commits the last batch and stamps the scan as finished.
+func (s *sqliteSink) Close() error {
+    var err error
+    if s.tx == nil && len(s.batch) > 0 {
+        err = s.begin()
+    }
+    if err == nil && s.tx != nil {
+        err = s.commit()
+    }
+    if err != nil {
+        s.db.Close()
+        return fmt.Errorf("%d rows not committed: %w", len(s.batch), err)
+    }
+    if _, err := s.db.Exec(`UPDATE scans SET finished = ? WHERE id = ?`, time.Now().UTC().Format(time.RFC3339Nano), s.scanID); err != nil {
+        s.db.Close()
+        return err
+    }
+    return s.db.Close()
+}
@@ -0,0 +1,76 @@
+// File: internal/writer/stats.go
+package writer
+
+import (
+    "math/rand"
+    "sort"
+
+    "goscant/internal/scanner"
+)
+
+// statsSample bounds the latencies kept for percentiles; beyond it a
+// uniform sample stands in for the whole scan.
+const statsSample = 10000
+
+// RunStats summarizes every result the writer received, before filters.
+// Latency percentiles cover answered probes (OPEN and CLOSED): a FILTERED
+// latency is just the timeout.
+type RunStats struct {
+    Results    int64            `json:"results"`
+    Probes     int64            `json:"probes"`
+    ByStatus   map[string]int64 `json:"by_status"`
+    LatencyP50 int64            `json:"latency_p50_ms"`
+    LatencyP95 int64            `json:"latency_p95_ms"`
+    LatencyP99 int64            `json:"latency_p99_ms"`
+}
+
+// runStats accumulates RunStats on the writer goroutine.
+type runStats struct {
+    results, probes int64
+    byStatus        map[scanner.Status]int64
+    answered        int64
+    latencies       []int64 // reservoir sample of answered latencies
+}
+
+func (s *runStats) add(r scanner.Result) {
+    if s.byStatus == nil {
+        s.byStatus = map[scanner.Status]int64{}
+    }
+    s.results++
+    s.probes += int64(max(r.Attempts, 1))
+    s.byStatus[r.Status]++
+    if r.Status != scanner.Open && r.Status != scanner.Closed {
+        return
+    }
+    s.answered++
+    if len(s.latencies) < statsSample {
+        s.latencies = append(s.latencies, r.LatencyMS)
+    } else if i := rand.Int63n(s.answered); i < statsSample {
+        s.latencies[i] = r.LatencyMS
+    }
+}
+
+// Stats returns the statistics of the results received so far. Call after
+// Close for the whole run.
+func (c *CSVWriter) Stats() RunStats {
+    s := &c.stats
+    out := RunStats{Results: s.results, Probes: s.probes, ByStatus: map[string]int64{}}
+    for st, n := range s.byStatus {
+        out.ByStatus[st.String()] = n
+    }
+    lat := append([]int64(nil), s.latencies...)
+    sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+    out.LatencyP50 = percentile(lat, 50)
+    out.LatencyP95 = percentile(lat, 95)
+    out.LatencyP99 = percentile(lat, 99)
+    return out
+}
+
+// percentile returns the p-th percentile of sorted by nearest rank.
+func percentile(sorted []int64, p int) int64 {
+    if len(sorted) == 0 {
+        return 0
+    }
+    i := (len(sorted)*p + 99) / 100
+    return sorted[max(i, 1)-1]
+}